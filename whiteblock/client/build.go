@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// Config is a build of a testnet, as given to the build rpc call
+type Config struct {
+	Servers      []int                  `json:"servers"`
+	Blockchain   string                 `json:"blockchain"`
+	Nodes        int                    `json:"nodes"`
+	Images       []string               `json:"images"`
+	Resources    []Resources            `json:"resources"`
+	Params       map[string]interface{} `json:"params"`
+	Environments []map[string]string    `json:"environments"`
+	Files        []map[string]string    `json:"files"`
+	Logs         []map[string]string    `json:"logs"`
+	Extras       map[string]interface{} `json:"extras"`
+	Meta         map[string]interface{} `json:"__meta"`
+	// Labels are the labels of each node, such as the name of the group it is in
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Resources are the resources of a node, or of every node when only one is given
+type Resources struct {
+	Cpus      string   `json:"cpus"`
+	Memory    string   `json:"memory"`
+	Ports     []string `json:"ports"`
+	Volumes   []string `json:"volumes"`
+	BoundCPUs []int    `json:"boundCPUs,omitonempty"`
+}
+
+// Status is the progress of a build, as given by build_status
+type Status struct {
+	Error    map[string]interface{} `json:"error"`
+	Progress float64                `json:"progress"`
+	Stage    string                 `json:"stage"`
+	Frozen   bool                   `json:"frozen"`
+}
+
+// StageName gets the name of the stage the build is in, as it is shown
+func (status Status) StageName() string {
+	switch {
+	case status.Frozen:
+		return "Frozen"
+	case status.Progress == 0.0:
+		return "Sending build context to Whiteblock"
+	case status.Progress == 100.0:
+		return "Build"
+	}
+	return status.Stage
+}
+
+// Done checks if the build has finished
+func (status Status) Done() bool {
+	return status.Progress == 100.0
+}
+
+// Err gets the error the build failed with, if it has failed
+func (status Status) Err() error {
+	if status.Error == nil {
+		return nil
+	}
+	return fmt.Errorf("%v", status.Error["what"])
+}
+
+// Build starts a new build, returning the id of the testnet being built
+func (c *Client) Build(ctx context.Context, bconf Config) (string, error) {
+	var testnetID string
+	err := c.Call(ctx, "build", bconf, &testnetID)
+	return testnetID, err
+}
+
+// AddNodes adds the nodes described by bconf to an existing testnet
+func (c *Client) AddNodes(ctx context.Context, testnetID string, bconf Config) error {
+	return c.Call(ctx, "add_nodes", []interface{}{testnetID, bconf}, nil)
+}
+
+// BuildStatus gets the progress of a build
+func (c *Client) BuildStatus(ctx context.Context, buildID string) (Status, error) {
+	var status Status
+	err := c.Call(ctx, "build_status", []string{buildID}, &status)
+	return status, err
+}
+
+// StopBuild stops a build which is in progress
+func (c *Client) StopBuild(ctx context.Context, buildID string) error {
+	return c.Call(ctx, "stop_build", []string{buildID}, nil)
+}
+
+// FreezeBuild pauses a build which is in progress
+func (c *Client) FreezeBuild(ctx context.Context, buildID string) error {
+	return c.Call(ctx, "freeze_build", []string{buildID}, nil)
+}
+
+// UnfreezeBuild resumes a paused build
+func (c *Client) UnfreezeBuild(ctx context.Context, buildID string) error {
+	return c.Call(ctx, "unfreeze_build", []string{buildID}, nil)
+}
+
+// GetBuild gets the build which created the given testnet
+func (c *Client) GetBuild(ctx context.Context, testnetID string) (Config, error) {
+	var out Config
+	err := c.Call(ctx, "get_build", []string{testnetID}, &out)
+	return out, err
+}
+
+// LastBuild is the reply of get_last_build
+type LastBuild struct {
+	Config
+	ID string `json:"id"`
+}
+
+// GetLastBuild gets the most recent build on the server
+func (c *Client) GetLastBuild(ctx context.Context) (LastBuild, error) {
+	var out LastBuild
+	err := c.Call(ctx, "get_last_build", []interface{}{}, &out)
+	return out, err
+}
+
+// DeleteTestnet tears down a testnet
+func (c *Client) DeleteTestnet(ctx context.Context, testnetID string) error {
+	return c.Call(ctx, "delete_testnet", []interface{}{testnetID}, nil)
+}
+
+// GetParams gets the name and type pairs of the params a blockchain takes
+func (c *Client) GetParams(ctx context.Context, blockchain string) ([][]string, error) {
+	var out [][]string
+	err := c.Call(ctx, "get_params", []string{blockchain}, &out)
+	return out, err
+}
+
+// GetDefaults gets the default params of a blockchain
+func (c *Client) GetDefaults(ctx context.Context, blockchain string) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	err := c.Call(ctx, "get_defaults", []string{blockchain}, &out)
+	return out, err
+}
+
+// GetResources gets the config files of a blockchain, or a single one when file is given
+func (c *Client) GetResources(ctx context.Context, blockchain string, file string) (interface{}, error) {
+	params := []string{blockchain}
+	if len(file) > 0 {
+		params = append(params, file)
+	}
+	return c.callRaw(ctx, "get_resources", params)
+}
+
+// GetSupportedBlockchains gets the blockchains the server is able to build
+func (c *Client) GetSupportedBlockchains(ctx context.Context) ([]string, error) {
+	var out []string
+	err := c.Call(ctx, "get_supported_blockchains", []string{}, &out)
+	return out, err
+}
+
+// SetExtra stores a value in the extras of the current testnet
+func (c *Client) SetExtra(ctx context.Context, key string, val interface{}) error {
+	return c.Call(ctx, "set_extra", []interface{}{key, val}, nil)
+}
+
+// AddCommands registers the given rpc commands with the server
+func (c *Client) AddCommands(ctx context.Context, commands interface{}) error {
+	return c.Call(ctx, "add_commands", commands, nil)
+}
+
+// RunTests runs the given tests on the server
+func (c *Client) RunTests(ctx context.Context, tests interface{}) (interface{}, error) {
+	return c.callRaw(ctx, "run_tests", tests)
+}
+
+// Lock acquires the lock on the server
+func (c *Client) Lock(ctx context.Context) (interface{}, error) {
+	return c.callRaw(ctx, "lock", []interface{}{})
+}
+
+// Unlock forces the release of the lock on the server
+func (c *Client) Unlock(ctx context.Context) (interface{}, error) {
+	return c.callRaw(ctx, "unlock", []interface{}{})
+}
+
+// GetServers gets the servers available to build on
+func (c *Client) GetServers(ctx context.Context) (interface{}, error) {
+	return c.callRaw(ctx, "get_servers", []string{})
+}
+
+// GetBiomeID gets the id of the biome the server belongs to
+func (c *Client) GetBiomeID(ctx context.Context) (interface{}, error) {
+	return c.callRaw(ctx, "get_biome_id", []string{})
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+)
+
+// StatsRequest selects the range of blocks to get the stats of. Either the times or the
+// blocks are given, and a negative StartBlock selects that many of the most recent blocks.
+type StatsRequest struct {
+	StartTime  int64 `json:"startTime"`
+	EndTime    int64 `json:"endTime"`
+	StartBlock int64 `json:"startBlock"`
+	EndBlock   int64 `json:"endBlock"`
+}
+
+// TPS is the request to start a stream of transactions
+type TPS struct {
+	TPS         int    `json:"tps"`
+	Value       string `json:"value"`
+	TxSize      int    `json:"txSize"`
+	Mode        string `json:"mode,omitempty"`
+	Destination string `json:"destination,omitempty"`
+}
+
+// Stats gets the statistics of the blockchain over the given range
+func (c *Client) Stats(ctx context.Context, req StatsRequest) (interface{}, error) {
+	return c.callRaw(ctx, "stats", req)
+}
+
+// AllStats gets the statistics of the blockchain over all of the blocks
+func (c *Client) AllStats(ctx context.Context) (interface{}, error) {
+	return c.callRaw(ctx, "all_stats", []string{})
+}
+
+// GetBlockNumber gets the block height of the given node
+func (c *Client) GetBlockNumber(ctx context.Context, node int) (int64, error) {
+	var out float64
+	err := c.Call(ctx, "get_block_number", []interface{}{node}, &out)
+	return int64(out), err
+}
+
+// GetBlock gets a block by its number
+func (c *Client) GetBlock(ctx context.Context, number string) (interface{}, error) {
+	return c.callRaw(ctx, "get_block", []string{number})
+}
+
+// GetBlockByHash gets a block by its hash
+func (c *Client) GetBlockByHash(ctx context.Context, hash string) (interface{}, error) {
+	return c.callRaw(ctx, "get_block_by_hash", []string{hash})
+}
+
+// GetTransaction gets a transaction by its hash
+func (c *Client) GetTransaction(ctx context.Context, hash string) (interface{}, error) {
+	return c.callRaw(ctx, "get_transaction", []string{hash})
+}
+
+// GetTransactionReceipt gets the receipt of a transaction
+func (c *Client) GetTransactionReceipt(ctx context.Context, hash string) (interface{}, error) {
+	return c.callRaw(ctx, "get_transaction_receipt", []string{hash})
+}
+
+// AccountsStatus gets the accounts along with their balances
+func (c *Client) AccountsStatus(ctx context.Context) (interface{}, error) {
+	return c.callRaw(ctx, "accounts_status", []string{})
+}
+
+// SendTransaction sends a single transaction
+func (c *Client) SendTransaction(ctx context.Context, from string, to string, gas string, gasPrice string,
+	value string) (interface{}, error) {
+	return c.callRaw(ctx, "send_transaction", []interface{}{from, to, gas, gasPrice, value})
+}
+
+// SendTo sends a single transaction from a random account
+func (c *Client) SendTo(ctx context.Context, to string, value string, data string) (interface{}, error) {
+	return c.callRaw(ctx, "send_to", []interface{}{to, value, data})
+}
+
+// RunConstantTPS starts a stream of transactions, which runs until Kill is called
+func (c *Client) RunConstantTPS(ctx context.Context, tps TPS) (interface{}, error) {
+	return c.callRaw(ctx, "run_constant_tps", []interface{}{tps})
+}
+
+// RunBurstTx sends a burst of transactions
+func (c *Client) RunBurstTx(ctx context.Context, params []string) (interface{}, error) {
+	return c.callRaw(ctx, "run_burst_tx", params)
+}
+
+// StartMining starts the miners of the given nodes, or of all of them if none are given
+func (c *Client) StartMining(ctx context.Context, nodes ...string) (interface{}, error) {
+	return c.callRaw(ctx, "start_mining", nodes)
+}
+
+// StopMining stops the miners of the given nodes, or of all of them if none are given
+func (c *Client) StopMining(ctx context.Context, nodes ...string) (interface{}, error) {
+	return c.callRaw(ctx, "stop_mining", nodes)
+}
+
+// EthGetHashRate gets the hash rate of an ethereum network
+func (c *Client) EthGetHashRate(ctx context.Context) (interface{}, error) {
+	return c.callRaw(ctx, "eth::get_hash_rate", []string{})
+}
+
+// EthGetTransactionReceipt gets the receipt of an ethereum transaction
+func (c *Client) EthGetTransactionReceipt(ctx context.Context, hash string) (interface{}, error) {
+	return c.callRaw(ctx, "eth::get_transaction_receipt", []string{hash})
+}
+
+// EthGetRecentSentTx gets the most recent transactions sent on an ethereum network
+func (c *Client) EthGetRecentSentTx(ctx context.Context, num int) (interface{}, error) {
+	return c.callRaw(ctx, "eth::get_recent_sent_tx", []interface{}{num})
+}
@@ -0,0 +1,86 @@
+// Package client is a typed client for the json rpc api of the whiteblock genesis server,
+// so that testnets can be driven from go without going through the command line.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/whiteblock/cli/whiteblock/rpc"
+	"net/http"
+	"time"
+)
+
+// Transport makes a single json rpc call, decoding the result into out
+type Transport interface {
+	Call(ctx context.Context, method string, params interface{}, out interface{}) error
+}
+
+// HTTPTransport is the Transport which sends the calls to a server over http
+type HTTPTransport struct {
+	// URL is the url of the rpc endpoint, such as https://127.0.0.1:5001/rpc
+	URL string
+	// Client is the http client to use, http.DefaultClient is used if it is nil
+	Client *http.Client
+	// Header is sent along with every call, such as the Authorization header
+	Header http.Header
+	// Attempts is the number of times a call is tried before giving up. Only failures in
+	// reaching the server are retried, and never for calls which are unsafe to repeat.
+	Attempts int
+	// Timeout is the deadline of each attempt, no deadline is set if it is 0
+	Timeout time.Duration
+	// Interruptible cancels the calls on SIGINT or SIGTERM, for interactive programs
+	Interruptible bool
+}
+
+// Call makes a json rpc call over http
+func (t HTTPTransport) Call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	httpClient := t.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if t.Interruptible {
+		var cancel context.CancelFunc
+		ctx, cancel = rpc.InterruptContext(ctx)
+		defer cancel()
+	}
+	return rpc.Retry(ctx, method, t.Attempts, t.Timeout, func(ctx context.Context) error {
+		return rpc.Call(ctx, httpClient, t.URL, t.Header, method, params, out)
+	})
+}
+
+// Client makes calls to the genesis api. The errors returned are the typed errors
+// from the rpc package, such as rpc.RPCError or rpc.TransportError.
+type Client struct {
+	transport Transport
+}
+
+// New creates a Client which makes the calls with the given transport
+func New(transport Transport) *Client {
+	return &Client{transport: transport}
+}
+
+// NewHTTP creates a Client for the server at the given rpc url, authenticating
+// with the given jwt if it is not empty
+func NewHTTP(url string, jwt string) *Client {
+	header := http.Header{}
+	if len(jwt) > 0 {
+		header.Set("Authorization", "Bearer "+jwt)
+	}
+	return New(HTTPTransport{URL: url, Header: header})
+}
+
+// Call makes an arbitrary json rpc call, for the calls which do not have their own method
+func (c *Client) Call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	if out == nil {
+		var discard json.RawMessage
+		out = &discard
+	}
+	return c.transport.Call(ctx, method, params, out)
+}
+
+// callRaw makes a call whose result has no fixed structure
+func (c *Client) callRaw(ctx context.Context, method string, params interface{}) (interface{}, error) {
+	var out interface{}
+	err := c.Call(ctx, method, params, &out)
+	return out, err
+}
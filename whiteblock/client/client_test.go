@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// fakeTransport records the calls made and replies with the given result
+type fakeTransport struct {
+	method string
+	params interface{}
+	result string
+}
+
+func (ft *fakeTransport) Call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	ft.method = method
+	ft.params = params
+	return json.Unmarshal([]byte(ft.result), out)
+}
+
+func TestClientCalls(t *testing.T) {
+	var tests = []struct {
+		call           func(c *Client) (interface{}, error)
+		result         string
+		expectedMethod string
+		expectedParams interface{}
+		expected       interface{}
+	}{
+		{
+			call: func(c *Client) (interface{}, error) {
+				return c.Build(context.Background(), Config{Blockchain: "geth", Nodes: 2})
+			},
+			result:         `"testnet1"`,
+			expectedMethod: "build",
+			expectedParams: Config{Blockchain: "geth", Nodes: 2},
+			expected:       "testnet1",
+		},
+		{
+			call: func(c *Client) (interface{}, error) {
+				return c.Nodes(context.Background(), "testnet1")
+			},
+			result:         `[{"id":"a","absNum":0,"ip":"10.0.0.2"}]`,
+			expectedMethod: "nodes",
+			expectedParams: []string{"testnet1"},
+			expected:       []Node{{ID: "a", AbsoluteNum: 0, IP: "10.0.0.2"}},
+		},
+		{
+			call: func(c *Client) (interface{}, error) {
+				return c.BuildStatus(context.Background(), "testnet1")
+			},
+			result:         `{"progress":50,"stage":"Provisioning"}`,
+			expectedMethod: "build_status",
+			expectedParams: []string{"testnet1"},
+			expected:       Status{Progress: 50, Stage: "Provisioning"},
+		},
+		{
+			call: func(c *Client) (interface{}, error) {
+				return c.GetBlockNumber(context.Background(), 3)
+			},
+			result:         `42`,
+			expectedMethod: "get_block_number",
+			expectedParams: []interface{}{3},
+			expected:       int64(42),
+		},
+		{
+			call: func(c *Client) (interface{}, error) {
+				return nil, c.MakeOutage(context.Background(), "testnet1", 0, 1)
+			},
+			result:         `"success"`,
+			expectedMethod: "make_outage",
+			expectedParams: []interface{}{"testnet1", 0, 1},
+		},
+		{
+			call: func(c *Client) (interface{}, error) {
+				return c.NetemGetLinks(context.Background(), "testnet1")
+			},
+			result:         `[{"node":0,"loss":1},{"node":0,"dst":[1,2],"delay":120000}]`,
+			expectedMethod: "netem_get",
+			expectedParams: []interface{}{"testnet1"},
+			expected:       []Link{{From: 0, To: 1, Delay: 120000}, {From: 0, To: 2, Delay: 120000}},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			ft := &fakeTransport{result: tt.result}
+			out, err := tt.call(New(ft))
+			if err != nil {
+				t.Error("error making the call", err)
+			}
+			if ft.method != tt.expectedMethod {
+				t.Errorf("expected the method %s, got %s", tt.expectedMethod, ft.method)
+			}
+			if !reflect.DeepEqual(ft.params, tt.expectedParams) {
+				t.Errorf("params do not match the expected params: %#v", ft.params)
+			}
+			if tt.expected != nil && !reflect.DeepEqual(out, tt.expected) {
+				t.Errorf("return value does not match expected value: %#v", out)
+			}
+		})
+	}
+}
+
+func TestLinkNetems(t *testing.T) {
+	var tests = []struct {
+		links    []Link
+		expected []Netem
+	}{
+		{links: []Link{}, expected: []Netem{}},
+		{
+			links: []Link{{From: 0, To: 1, Delay: 5000}, {From: 1, To: 0, Delay: 5000}, {From: 0, To: 2, Delay: 5000},
+				{From: 0, To: 3, Delay: 5000, Loss: 1}},
+			expected: []Netem{{Node: 0, Dst: []int{1, 2}, Delay: 5000}, {Node: 1, Dst: []int{0}, Delay: 5000},
+				{Node: 0, Dst: []int{3}, Delay: 5000, Loss: 1}},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			out := LinkNetems(tt.links)
+			if !reflect.DeepEqual(out, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, out)
+			}
+			links := LinksOfNetems(out)
+			if len(links) != len(tt.links) {
+				t.Errorf("expected the links back, got %#v", links)
+			}
+		})
+	}
+}
+
+// failingTransport records the calls made, giving the netems for netem_get and failing the
+// netem calls with the given numbers, counting from 1
+type failingTransport struct {
+	netems  string
+	fail    map[int]bool
+	netem   int
+	calls   []string
+	applied []Netem
+}
+
+func (ft *failingTransport) Call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	ft.calls = append(ft.calls, method)
+	switch method {
+	case "netem_get":
+		return json.Unmarshal([]byte(ft.netems), out)
+	case "netem_delete":
+		ft.applied = nil
+	case "netem":
+		ft.netem++
+		if ft.fail[ft.netem] {
+			return fmt.Errorf("node unreachable")
+		}
+		ft.applied = append(ft.applied, params.([]interface{})[1].(Netem))
+	}
+	return nil
+}
+
+func TestNetemLinksFailure(t *testing.T) {
+	previous := []Netem{{Node: 0, Loss: 1}, {Node: 1, Dst: []int{0}, Delay: 5000}}
+	links := []Link{{From: 0, To: 1, Delay: 100}, {From: 2, To: 1, Delay: 100}}
+	var tests = []struct {
+		fail     map[int]bool
+		expected []Netem
+		calls    int
+		err      string
+	}{
+		{
+			expected: []Netem{{Node: 0, Loss: 1}, {Node: 0, Dst: []int{1}, Delay: 100}, {Node: 2, Dst: []int{1}, Delay: 100}},
+			calls:    5,
+		},
+		{
+			fail:     map[int]bool{2: true},
+			expected: previous,
+			calls:    7,
+			err:      "unable to apply the network conditions, the previous ones were restored: node unreachable",
+		},
+		{
+			fail:     map[int]bool{3: true, 5: true},
+			expected: []Netem{{Node: 0, Loss: 1}},
+			calls:    8,
+			err: "the network conditions were only partly applied, and the previous ones could not be " +
+				"restored (node unreachable): node unreachable",
+		},
+	}
+
+	data, _ := json.Marshal(previous)
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			ft := &failingTransport{netems: string(data), fail: tt.fail}
+			err := New(ft).NetemLinks(context.Background(), "testnet1", links)
+			if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+				t.Errorf("expected the error %q, got %v", tt.err, err)
+			}
+			if !reflect.DeepEqual(ft.applied, tt.expected) {
+				t.Errorf("expected the netems %#v, got %#v", tt.expected, ft.applied)
+			}
+			if len(ft.calls) != tt.calls {
+				t.Errorf("expected %d calls, got %v", tt.calls, ft.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// Netem is the network conditions applied to a node
+type Netem struct {
+	Node int `json:"node"`
+	// Dst are the nodes the conditions apply to the traffic sent to, or all of the traffic
+	// of the node if it is empty
+	Dst   []int `json:"dst,omitempty"`
+	Limit int   `json:"limit,omitempty"`
+	// Loss is the percentage of packets to drop
+	Loss float64 `json:"loss,omitempty"`
+	// Delay is the latency to add, in microseconds
+	Delay int `json:"delay,omitempty"`
+	// Rate is the bandwidth limit, such as 100mbps
+	Rate string `json:"rate,omitempty"`
+	// Jitter is the variation of the delay, in microseconds
+	Jitter int `json:"jitter,omitempty"`
+	// Correlation is the percentage by which each delay depends on the one before it
+	Correlation float64 `json:"correlation,omitempty"`
+	// Distribution is the distribution of the delay, one of normal, pareto or paretonormal
+	Distribution string `json:"distribution,omitempty"`
+	// Duplicate is the percentage of packets to send twice
+	Duplicate float64 `json:"duplicate,omitempty"`
+	// Corrupt is the percentage of packets to flip a bit of
+	Corrupt float64 `json:"corrupt,omitempty"`
+	// Reorder is the percentage of packets to send straight away, ahead of the delayed ones
+	Reorder float64 `json:"reorder,omitempty"`
+}
+
+// Netem applies network conditions to a single node
+func (c *Client) Netem(ctx context.Context, testnetID string, conditions Netem) error {
+	return c.Call(ctx, "netem", []interface{}{testnetID, conditions}, nil)
+}
+
+// NetemAll applies the same network conditions to every node, the Node field is ignored
+func (c *Client) NetemAll(ctx context.Context, testnetID string, conditions Netem) error {
+	return c.Call(ctx, "netem_all", []interface{}{testnetID, conditions}, nil)
+}
+
+// NetemDelete removes the network conditions from every node
+func (c *Client) NetemDelete(ctx context.Context, testnetID string) error {
+	return c.Call(ctx, "netem_delete", []interface{}{testnetID}, nil)
+}
+
+// NetemGet gets the network conditions of the nodes
+func (c *Client) NetemGet(ctx context.Context, testnetID string) (interface{}, error) {
+	return c.callRaw(ctx, "netem_get", []interface{}{testnetID})
+}
+
+// Link is the network conditions applied to the traffic sent from one node to another
+type Link struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Limit int `json:"limit,omitempty"`
+	// Loss is the percentage of packets to drop
+	Loss float64 `json:"loss,omitempty"`
+	// Delay is the latency to add, in microseconds
+	Delay int `json:"delay,omitempty"`
+	// Rate is the bandwidth limit, such as 100mbps
+	Rate string `json:"rate,omitempty"`
+	// Jitter is the variation of the delay, in microseconds
+	Jitter int `json:"jitter,omitempty"`
+	// Correlation is the percentage by which each delay depends on the one before it
+	Correlation float64 `json:"correlation,omitempty"`
+	// Distribution is the distribution of the delay, one of normal, pareto or paretonormal
+	Distribution string `json:"distribution,omitempty"`
+	// Duplicate is the percentage of packets to send twice
+	Duplicate float64 `json:"duplicate,omitempty"`
+	// Corrupt is the percentage of packets to flip a bit of
+	Corrupt float64 `json:"corrupt,omitempty"`
+	// Reorder is the percentage of packets to send straight away, ahead of the delayed ones
+	Reorder float64 `json:"reorder,omitempty"`
+}
+
+// netem gives the conditions of the link as those of its sending node for its receiving node
+func (l Link) netem() Netem {
+	return Netem{
+		Node:         l.From,
+		Dst:          []int{l.To},
+		Limit:        l.Limit,
+		Loss:         l.Loss,
+		Delay:        l.Delay,
+		Rate:         l.Rate,
+		Jitter:       l.Jitter,
+		Correlation:  l.Correlation,
+		Distribution: l.Distribution,
+		Duplicate:    l.Duplicate,
+		Corrupt:      l.Corrupt,
+		Reorder:      l.Reorder,
+	}
+}
+
+// link gives the conditions of the node for the traffic sent to the node to
+func (n Netem) link(to int) Link {
+	return Link{
+		From:         n.Node,
+		To:           to,
+		Limit:        n.Limit,
+		Loss:         n.Loss,
+		Delay:        n.Delay,
+		Rate:         n.Rate,
+		Jitter:       n.Jitter,
+		Correlation:  n.Correlation,
+		Distribution: n.Distribution,
+		Duplicate:    n.Duplicate,
+		Corrupt:      n.Corrupt,
+		Reorder:      n.Reorder,
+	}
+}
+
+// LinkNetems gives the netem calls which apply the conditions of the links, one for each
+// sending node and conditions, filtered to the receiving nodes with those conditions
+func LinkNetems(links []Link) []Netem {
+	out := []Netem{}
+	index := map[Link]int{}
+	for _, link := range links {
+		key := link
+		key.To = 0
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(out)
+			out = append(out, link.netem())
+			continue
+		}
+		out[i].Dst = append(out[i].Dst, link.To)
+	}
+	return out
+}
+
+// LinksOfNetems gives the links of the network conditions which are filtered to receiving nodes,
+// the conditions which apply to all of the traffic of a node are left out
+func LinksOfNetems(netems []Netem) []Link {
+	out := []Link{}
+	for _, netem := range netems {
+		for _, to := range netem.Dst {
+			out = append(out, netem.link(to))
+		}
+	}
+	return out
+}
+
+// NetemLinks replaces the network conditions of the links between the nodes with the given links.
+// The conditions of each link only apply in one direction. The conditions of the nodes which are
+// not filtered to receiving nodes are kept.
+func (c *Client) NetemLinks(ctx context.Context, testnetID string, links []Link) error {
+	previous, err := c.netems(ctx, testnetID)
+	if err != nil {
+		return err
+	}
+	netems := []Netem{}
+	for _, netem := range previous {
+		if len(netem.Dst) == 0 {
+			netems = append(netems, netem)
+		}
+	}
+	return c.replaceNetems(ctx, testnetID, previous, append(netems, LinkNetems(links)...))
+}
+
+// replaceNetems removes the network conditions of the nodes and applies the given netems instead.
+// If one of them fails, the previous conditions are put back, and the error says whether the
+// conditions were left partly applied.
+func (c *Client) replaceNetems(ctx context.Context, testnetID string, previous []Netem, netems []Netem) error {
+	err := c.NetemDelete(ctx, testnetID)
+	if err != nil {
+		return err
+	}
+	err = c.applyNetems(ctx, testnetID, netems)
+	if err == nil {
+		return nil
+	}
+	restoreErr := c.NetemDelete(ctx, testnetID)
+	if restoreErr == nil {
+		restoreErr = c.applyNetems(ctx, testnetID, previous)
+	}
+	if restoreErr != nil {
+		return fmt.Errorf("the network conditions were only partly applied, and the previous ones "+
+			"could not be restored (%v): %w", restoreErr, err)
+	}
+	return fmt.Errorf("unable to apply the network conditions, the previous ones were restored: %w", err)
+}
+
+// applyNetems applies each of the netems in turn, stopping at the first which fails
+func (c *Client) applyNetems(ctx context.Context, testnetID string, netems []Netem) error {
+	for _, netem := range netems {
+		err := c.Netem(ctx, testnetID, netem)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NetemGetLinks gets the network conditions of the links between the nodes
+func (c *Client) NetemGetLinks(ctx context.Context, testnetID string) ([]Link, error) {
+	netems, err := c.netems(ctx, testnetID)
+	if err != nil {
+		return nil, err
+	}
+	return LinksOfNetems(netems), nil
+}
+
+// netems gets the network conditions of the nodes
+func (c *Client) netems(ctx context.Context, testnetID string) ([]Netem, error) {
+	out := []Netem{}
+	err := c.Call(ctx, "netem_get", []interface{}{testnetID}, &out)
+	return out, err
+}
+
+// MakeOutage cuts the connection between two nodes
+func (c *Client) MakeOutage(ctx context.Context, testnetID string, node1 int, node2 int) error {
+	return c.Call(ctx, "make_outage", []interface{}{testnetID, node1, node2}, nil)
+}
+
+// RemoveOutage restores the connection between two nodes
+func (c *Client) RemoveOutage(ctx context.Context, testnetID string, node1 int, node2 int) error {
+	return c.Call(ctx, "remove_outage", []interface{}{testnetID, node1, node2}, nil)
+}
+
+// RemoveAllOutages restores all of the connections between the nodes
+func (c *Client) RemoveAllOutages(ctx context.Context, testnetID string) error {
+	return c.Call(ctx, "remove_all_outages", []interface{}{testnetID}, nil)
+}
+
+// PartitionOutage cuts the given nodes off from the rest of the network
+func (c *Client) PartitionOutage(ctx context.Context, testnetID string, nodes []int) error {
+	return c.Call(ctx, "partition_outage", []interface{}{testnetID, nodes}, nil)
+}
+
+// GetOutages gets the cut connections, only those of the given node when node is not empty
+func (c *Client) GetOutages(ctx context.Context, testnetID string, node string) (interface{}, error) {
+	params := []interface{}{testnetID}
+	if len(node) > 0 {
+		params = append(params, node)
+	}
+	return c.callRaw(ctx, "get_outages", params)
+}
+
+// GetPartitions gets the groups of nodes which are able to reach each other
+func (c *Client) GetPartitions(ctx context.Context, testnetID string) (interface{}, error) {
+	return c.callRaw(ctx, "get_partitions", []interface{}{testnetID})
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+)
+
+// Node is a node of a testnet, as given by the nodes rpc call
+type Node struct {
+	ID           string            `json:"id"`
+	TestNetID    string            `json:"testnetId"`
+	Server       int               `json:"server"`
+	LocalID      int               `json:"localId"`
+	AbsoluteNum  int               `json:"absNum"`
+	IP           string            `json:"ip"`
+	Label        string            `json:"label"`
+	Image        string            `json:"image"`
+	Protocol     string            `json:"protocol"`
+	PortMappings map[string]string `json:"portMappings,omitonempty"`
+}
+
+// NodeStatus is a node as given by status_nodes
+type NodeStatus struct {
+	Node
+	Up bool `json:"up"`
+}
+
+// LogRequest is the request for the logs of a node
+type LogRequest struct {
+	TestnetID string `json:"testnetId"`
+	Node      int    `json:"node"`
+	// Lines is the number of lines from the end to give, -1 gives all of them
+	Lines int `json:"lines"`
+}
+
+// Nodes gets the nodes of a testnet
+func (c *Client) Nodes(ctx context.Context, testnetID string) ([]Node, error) {
+	var out []Node
+	err := c.Call(ctx, "nodes", []string{testnetID}, &out)
+	return out, err
+}
+
+// StatusNodes gets the nodes of a testnet, including whether they are up
+func (c *Client) StatusNodes(ctx context.Context, testnetID string) ([]NodeStatus, error) {
+	var out []NodeStatus
+	err := c.Call(ctx, "status_nodes", []string{testnetID}, &out)
+	return out, err
+}
+
+// Log gets the logs of a node
+func (c *Client) Log(ctx context.Context, req LogRequest) (string, error) {
+	var out string
+	err := c.Call(ctx, "log", req, &out)
+	return out, err
+}
+
+// SignalNode sends a signal, such as SIGINT, to the main process of a node
+func (c *Client) SignalNode(ctx context.Context, testnetID string, node string, signal string) error {
+	return c.Call(ctx, "signal_node", []interface{}{testnetID, node, signal}, nil)
+}
+
+// KillNode kills the main process of a node
+func (c *Client) KillNode(ctx context.Context, testnetID string, node string) error {
+	return c.Call(ctx, "kill_node", []interface{}{testnetID, node}, nil)
+}
+
+// RestartNode kills a node and then re-runs the command used to start it
+func (c *Client) RestartNode(ctx context.Context, testnetID string, node string) error {
+	return c.Call(ctx, "restart_node", []interface{}{testnetID, node}, nil)
+}
+
+// JsonRpcCall forwards a json rpc call to the client of a node. The args are the node, the
+// method and then the params of the call.
+func (c *Client) JsonRpcCall(ctx context.Context, args []interface{}) (interface{}, error) {
+	return c.callRaw(ctx, "jsonrpc_call", args)
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+)
+
+// LoadSettings controls how an automated load is run
+type LoadSettings struct {
+	// TargetDelay is the time between calls, in microseconds
+	TargetDelay   int  `json:"targetDelay"`
+	SampleSize    int  `json:"sampleSize"`
+	MaxNumErrMsgs uint `json:"maxNumErrMsgs"`
+	RecordErrMsgs bool `json:"recordErrMsgs"`
+}
+
+// Load is an automated load of json rpc calls on a node
+type Load struct {
+	Node       int           `json:"node"`
+	Name       string        `json:"name"`
+	Settings   LoadSettings  `json:"settings"`
+	Call       string        `json:"call"`
+	Arguments  []interface{} `json:"arguments"`
+	ErrorCheck bool          `json:"errorCheck"`
+}
+
+// SetupLoad starts an automated load on a node
+func (c *Client) SetupLoad(ctx context.Context, load Load) (interface{}, error) {
+	return c.callRaw(ctx, "setup_load", []interface{}{load})
+}
+
+// SubRoutines gets the automated loads which are running
+func (c *Client) SubRoutines(ctx context.Context) (interface{}, error) {
+	return c.callRaw(ctx, "state::sub_routines", []string{})
+}
+
+// SubRoutinesStats gets the statistics of the automated loads
+func (c *Client) SubRoutinesStats(ctx context.Context) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	err := c.Call(ctx, "state::sub_routines_stats", []string{}, &out)
+	return out, err
+}
+
+// SubRoutineErrors gets the recent errors of the given automated loads, or of all of them
+// if none are given
+func (c *Client) SubRoutineErrors(ctx context.Context, names ...string) (interface{}, error) {
+	if len(names) == 0 {
+		return c.callRaw(ctx, "state::all_sub_routines_errors", []string{})
+	}
+	return c.callRaw(ctx, "state::sub_routine_errors", names)
+}
+
+// KillSubRoutines stops the given automated loads
+func (c *Client) KillSubRoutines(ctx context.Context, names ...string) (interface{}, error) {
+	return c.callRaw(ctx, "state::kill_sub_routines", names)
+}
+
+// ForceStopSubRoutine stops an automated load without waiting for it
+func (c *Client) ForceStopSubRoutine(ctx context.Context, name string) (interface{}, error) {
+	return c.callRaw(ctx, "state::force_stop_sub_routine", []interface{}{name})
+}
+
+// CleanSubRoutines removes the given stopped automated loads
+func (c *Client) CleanSubRoutines(ctx context.Context, names ...string) (interface{}, error) {
+	return c.callRaw(ctx, "state::clean_sub_routines", names)
+}
+
+// PurgeAllSubRoutines stops and removes all of the automated loads
+func (c *Client) PurgeAllSubRoutines(ctx context.Context) (interface{}, error) {
+	return c.callRaw(ctx, "state::purge_all_sub_routines", []string{})
+}
+
+// IsRunning checks if the server is running something, such as a transaction stream
+func (c *Client) IsRunning(ctx context.Context) (interface{}, error) {
+	return c.callRaw(ctx, "state::is_running", []string{})
+}
+
+// WhatIsRunning gets what the server is running
+func (c *Client) WhatIsRunning(ctx context.Context) (interface{}, error) {
+	return c.callRaw(ctx, "state::what_is_running", []string{})
+}
+
+// Kill stops whatever the server is running
+func (c *Client) Kill(ctx context.Context) (interface{}, error) {
+	return c.callRaw(ctx, "state::kill", []string{})
+}
+
+// StateGet gets a value from the state of the server, such as "accounts"
+func (c *Client) StateGet(ctx context.Context, key string) (interface{}, error) {
+	return c.callRaw(ctx, "state::get", []string{key})
+}
+
+// StateInfo gets the information about the current testnet, such as the private keys
+func (c *Client) StateInfo(ctx context.Context, args ...string) (interface{}, error) {
+	return c.callRaw(ctx, "state::info", args)
+}
+
+// GetRecentTx gets the most recently sent transactions
+func (c *Client) GetRecentTx(ctx context.Context, num int) (interface{}, error) {
+	return c.callRaw(ctx, "state::get_recent_tx", []interface{}{num})
+}
@@ -32,31 +32,34 @@ var autoCmd = &cobra.Command{
 	wb auto -i 100000 0 eth_getBalance  +account latest
 `,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 2, -1)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 2, -1)
+		if err != nil {
+			return err
+		}
 		node, err := strconv.Atoi(args[0])
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		interval, err := cmd.Flags().GetInt("interval")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		sampleSize, err := cmd.Flags().GetInt("sample-size")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		errorChecking, err := cmd.Flags().GetBool("full-error-checking")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		maxNumErrMsgs, err := cmd.Flags().GetUint("max-num-err-msgs")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		recordErrMsgs, err := cmd.Flags().GetBool("disable-error-recording")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 
 		params := []interface{}{}
@@ -71,7 +74,7 @@ var autoCmd = &cobra.Command{
 			}
 		}
 
-		util.JsonRpcCallAndPrint("setup_load", []interface{}{map[string]interface{}{
+		return util.JsonRpcCallAndPrint("setup_load", []interface{}{map[string]interface{}{
 			"node": node,
 			"name": fmt.Sprintf("node%d:%s", node, args[1]),
 			"settings": map[string]interface{}{
@@ -94,17 +97,19 @@ var autoKillCmd = &cobra.Command{
 	Long: `
 Kill an auto routine.
 `,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		forced, err := cmd.Flags().GetBool("force")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		if forced {
-			util.CheckArguments(cmd, args, 1, 1)
-			util.JsonRpcCallAndPrint("state::force_stop_sub_routine", []interface{}{args[0]})
-		} else {
-			util.JsonRpcCallAndPrint("state::kill_sub_routines", args)
+			err := util.CheckArguments(args, 1, 1)
+			if err != nil {
+				return err
+			}
+			return util.JsonRpcCallAndPrint("state::force_stop_sub_routine", []interface{}{args[0]})
 		}
+		return util.JsonRpcCallAndPrint("state::kill_sub_routines", args)
 	},
 }
 
@@ -114,8 +119,8 @@ var autoCleanCmd = &cobra.Command{
 	Long: `
 clean a stoped auto routine
 `,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.JsonRpcCallAndPrint("state::clean_sub_routines", args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return util.JsonRpcCallAndPrint("state::clean_sub_routines", args)
 	},
 }
 
@@ -126,8 +131,8 @@ var autoPurgeCmd = &cobra.Command{
 Gracefully stops and removes all of the currently running auto routines.
 Most users do not need to call this as it happens automatically on the next build.
 `,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.JsonRpcCallAndPrint("state::purge_all_sub_routines", args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return util.JsonRpcCallAndPrint("state::purge_all_sub_routines", args)
 	},
 }
 
@@ -136,23 +141,24 @@ var getAutoCmd = &cobra.Command{
 	Aliases: []string{"routines"},
 	Short:   "Check auto QPS",
 	Long:    "Get the QPS of the currently running automated queries",
-	Run: func(cmd *cobra.Command, args []string) {
-		util.JsonRpcCallAndPrint("state::sub_routines", []string{})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return util.JsonRpcCallAndPrint("state::sub_routines", []string{}, routineColumns...)
 	},
 }
 
+// routineColumns are the fields of each automated load shown by --output table, after its name
+var routineColumns = []string{"node", "call", "successes", "errors", "successRate"}
+
 var getAutoErrorsCmd = &cobra.Command{
 	Use:     "errors",
 	Aliases: []string{"error"},
 	Short:   "Check auto errors",
 	Long:    "Get the most recent error messages",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
-			util.JsonRpcCallAndPrint("state::all_sub_routines_errors", []string{})
-			return
+			return util.JsonRpcCallAndPrint("state::all_sub_routines_errors", []string{})
 		}
-		util.JsonRpcCallAndPrint("state::sub_routine_errors", args)
-
+		return util.JsonRpcCallAndPrint("state::sub_routine_errors", args)
 	},
 }
 
@@ -249,27 +255,27 @@ var getAutoDetailedCmd = &cobra.Command{
 	Aliases: []string{"details", "detailed"},
 	Short:   "Check the progress of auto queries in detail",
 	Long:    "Check the progress of auto queries in detail",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		graphIt, err := cmd.Flags().GetBool("graph")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		if !graphIt {
-			util.JsonRpcCallAndPrint("state::sub_routines_stats", []string{})
-			return
+			return util.JsonRpcCallAndPrint("state::sub_routines_stats", []string{})
 		}
 		if err = ui.Init(); err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		defer ui.Close()
 
+		failed := make(chan error, 1)
 		go func() {
 			for {
 
 				plots, err := createAutoGraph()
 				if err != nil {
-					ui.Close()
-					util.PrintErrorFatal(err)
+					failed <- err
+					return
 				}
 				ui.Render(plots...)
 				time.Sleep(time.Second)
@@ -279,13 +285,16 @@ var getAutoDetailedCmd = &cobra.Command{
 
 		uiEvents := ui.PollEvents()
 		for {
-			e := <-uiEvents
-			switch e.ID {
-			case "q", "<C-c>":
-				return
+			select {
+			case err := <-failed:
+				return err
+			case e := <-uiEvents:
+				switch e.ID {
+				case "q", "<C-c>":
+					return nil
+				}
 			}
 		}
-
 	},
 }
 
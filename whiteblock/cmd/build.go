@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -11,76 +13,234 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
-func buildAttach(buildID string) {
-	buildListener(buildID)
-	err := util.Set("previous_build_id", buildID)
-	util.Delete("in_progress_build_id")
+var (
+	// buildTimeout is how long to follow a build for, given by --timeout
+	buildTimeout time.Duration
+	// buildHookFlags are the hooks given by --hook, which run along with those of the hooks file
+	buildHookFlags []string
+	buildNoHooks   bool
+)
+
+// loadBuildHooks gets the hooks of the hooks file and those given by --hook, or none with --no-hooks
+func loadBuildHooks() ([]build.Hook, error) {
+	if buildNoHooks {
+		return []build.Hook{}, nil
+	}
+	hooks, err := build.LoadHooks(build.HooksPath())
+	if err != nil {
+		return nil, err
+	}
+	for _, flag := range buildHookFlags {
+		hook, err := build.ParseHookFlag(flag)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
 
+func buildAttach(buildID string) error {
+	timeout := buildTimeout
+	if timeout == 0 {
+		timeout = util.GetConfig().BuildTimeout
+	}
+	hooks, err := loadBuildHooks()
+	if err != nil {
+		return err
+	}
+	err = buildListener(buildID, timeout, build.NewHooks(buildID, hooks))
+	var timeoutErr util.TimeoutError
+	var interruptErr util.InterruptError
+	if err != nil && !errors.As(err, &timeoutErr) && !errors.As(err, &interruptErr) {
+		outcomeErr := build.SetBuildOutcome(buildID, build.OutcomeFailed, err)
+		if outcomeErr != nil {
+			log.WithFields(log.Fields{"error": outcomeErr}).Warn("unable to record the outcome of the build")
+		}
+	}
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
+	err = build.SetBuildOutcome(buildID, build.OutcomeCompleted, nil)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Warn("unable to record the outcome of the build")
+	}
+	err = util.Set("previous_build_id", buildID)
+	util.Delete("in_progress_build_id")
+	return err
 }
 
-func buildStart(buildConfig interface{}, isAppend bool) {
-	var buildReply interface{}
-	var err error
+// registerTestnet adds the started build to the testnet registry, or adds the
+// appended nodes to it
+func registerTestnet(testnet util.Testnet, buildConfig build.Config, isAppend bool) error {
 	if isAppend {
-		buildReply = build.GetPreviousBuildID()
-		_, err = util.JsonRpcCall("add_nodes", []interface{}{buildReply, buildConfig})
+		existing, err := util.FindTestnet(testnet.ID)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return nil //not built from here, there is nothing to update
 		}
-	} else {
-		buildReply, err = util.JsonRpcCall("build", buildConfig)
-		if err != nil {
-			util.PrintErrorFatal(err)
+		existing.Nodes += buildConfig.Nodes
+		return util.SaveTestnet(existing)
+	}
+	testnet.Blockchain = buildConfig.Blockchain
+	testnet.Nodes = buildConfig.Nodes
+	return util.SaveTestnet(testnet)
+}
+
+// inProgressBuildID gets the id of the build which is in progress
+func inProgressBuildID() (string, error) {
+	var buildID string
+	err := util.GetP("in_progress_build_id", &buildID)
+	if err != nil || len(buildID) == 0 {
+		return "", fmt.Errorf("No in-progress build found. Use build command to deploy a blockchain.")
+	}
+	return buildID, nil
+}
+
+func buildStart(buildConfig build.Config, isAppend bool, testnet util.Testnet) error {
+	hooks, err := loadBuildHooks()
+	if err != nil {
+		return err
+	}
+	var buildReply string
+	if isAppend {
+		buildReply, err = build.GetPreviousBuildID()
+		if err == nil {
+			err = rpcClient().AddNodes(context.Background(), buildReply, buildConfig)
 		}
+	} else {
+		buildReply, err = rpcClient().Build(context.Background(), buildConfig)
+	}
+	if err != nil {
+		return err
 	}
 
 	util.Print("Build Started Successfully.")
 	util.Printf("Testnet ID : %v\n", buildReply)
 
+	testnet.ID = buildReply
+	err = registerTestnet(testnet, buildConfig, isAppend)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Warn("unable to add the testnet to the registry")
+	}
+	_, err = build.RecordBuild(buildConfig, buildReply, isAppend)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Warn("unable to add the build to the history")
+	}
+
 	//Store the in progress builds temporary id until the build finishes
-	err = util.Set("in_progress_build_id", buildReply.(string))
+	err = util.Set("in_progress_build_id", buildReply)
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 
-	buildAttach(buildReply.(string))
+	build.NewHooks(buildReply, hooks).Fire("start", build.Status{})
+	return buildAttach(buildReply)
 }
 
-func Build(cmd *cobra.Command, args []string, isAppend bool) {
-	var err error
-	util.CheckArguments(cmd, args, 0, 0)
+func Build(cmd *cobra.Command, args []string, isAppend bool) error {
+	return buildFrom(cmd, args, isAppend, nil)
+}
+
+// buildFrom builds from the flags, as Build does. If base is given, such as a build from the
+// history, it takes the place of a spec, with the flags overriding its values.
+func buildFrom(cmd *cobra.Command, args []string, isAppend bool, base *build.Config) error {
+	err := util.CheckArguments(args, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	blockchainFlag, err := util.GetStringFlagValue(cmd, "blockchain")
+	if err != nil {
+		return err
+	}
+	nodesFlag, err := util.GetIntFlagValue(cmd, "nodes")
+	if err != nil {
+		return err
+	}
+	paramsFile, err := util.GetStringFlagValue(cmd, "file")
+	if err != nil {
+		return err
+	}
+	validators, err := util.GetIntFlagValue(cmd, "validators")
+	if err != nil {
+		return err
+	}
+	specFile, err := util.GetStringFlagValue(cmd, "spec")
+	if err != nil {
+		return err
+	}
 
-	blockchainFlag := util.GetStringFlagValue(cmd, "blockchain")
-	nodesFlag := util.GetIntFlagValue(cmd, "nodes")
-	paramsFile := util.GetStringFlagValue(cmd, "file")
-	validators := util.GetIntFlagValue(cmd, "validators")
+	var testnet util.Testnet
+	if !isAppend {
+		testnet.Name, err = util.GetStringFlagValue(cmd, "name")
+		if err != nil {
+			return err
+		}
+		testnet.Labels, err = cmd.Flags().GetStringToString("label")
+		if err != nil {
+			return err
+		}
+		err = util.CheckTestnetName(testnet.Name, "")
+		if err != nil {
+			return err
+		}
+	}
 
-	buildConf, _ := build.GetPreviousBuild() //Errors are ok with this.
+	var spec *build.Spec
+	var buildConf build.Config
+	if base != nil {
+		buildConf = *base
+		if len(blockchainFlag) == 0 {
+			blockchainFlag = buildConf.Blockchain
+		}
+		if nodesFlag == 0 {
+			nodesFlag = buildConf.Nodes
+		}
+	} else if len(specFile) > 0 {
+		spec, err = build.LoadSpec(specFile)
+		if err != nil {
+			return err
+		}
+		buildConf = spec.Config
+		//The spec takes the place of the flags, unless they are explicitly given
+		if len(blockchainFlag) == 0 {
+			blockchainFlag = buildConf.Blockchain
+		}
+		if nodesFlag == 0 {
+			nodesFlag = buildConf.Nodes
+		}
+	} else {
+		buildConf, _ = build.GetPreviousBuild() //Errors are ok with this.
+		buildConf.Images = nil
+		buildConf.Files = nil
+		buildConf.Environments = nil
+		buildConf.Labels = nil
+		buildConf.Servers = nil
+		buildConf.Resources = []build.Resources{build.Resources{Cpus: "", Memory: ""}}
+		buildConf.Params = map[string]interface{}{}
+		buildConf.Extras = map[string]interface{}{}
+		buildConf.Meta = map[string]interface{}{}
+	}
 
 	previousNumberNodes := 0
 	if isAppend {
-		nodes := GetNodes()
-		log.WithFields(log.Fields{"nodes": buildConf.Nodes,
-			"err": err}).Debug("getting node number from previous build")
+		nodes, err := GetNodes()
+		if err != nil {
+			return err
+		}
+		log.WithFields(log.Fields{"nodes": len(nodes)}).Debug("getting node number from previous build")
 		previousNumberNodes = len(nodes)
 	}
 
 	blockchainEnabled := len(blockchainFlag) > 0
 	nodesEnabled := nodesFlag > 0
 
-	buildConf.Resources = []build.Resources{build.Resources{Cpus: "", Memory: ""}}
-	buildConf.Params = map[string]interface{}{}
-	buildConf.Extras = map[string]interface{}{}
-	buildConf.Meta = map[string]interface{}{}
-
 	previousYesAll, err := cmd.Flags().GetBool("yes")
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 
 	buildOpt := []string{}
@@ -103,11 +263,11 @@ func Build(cmd *cobra.Command, args []string, isAppend bool) {
 
 	for i := 0; i < len(buildOpt); i++ {
 		if !util.IsTTY() {
-			util.PrintErrorFatal("missing build parameters and couldn't prompt")
+			return util.NewValidationError("missing build parameters and couldn't prompt")
 		}
 		fmt.Print(buildOpt[i] + ": ")
 		if !scanner.Scan() {
-			util.PrintErrorFatal(scanner.Err())
+			return fmt.Errorf("unable to read the build parameters: %v", scanner.Err())
 		}
 
 		text := scanner.Text()
@@ -129,17 +289,18 @@ func Build(cmd *cobra.Command, args []string, isAppend bool) {
 		buildConf.Blockchain = strings.ToLower(buildArr[offset])
 		offset++
 	} //Final blockchain definition. Will need to start another round of prompting
-	optionsChannel := make(chan [][]string, 1)
+	type fetchedParams struct {
+		options [][]string
+		err     error
+	}
+	optionsChannel := make(chan fetchedParams, 1)
 	go func() {
 		if buildConf.Blockchain == "generic" {
-			optionsChannel <- [][]string{}
+			optionsChannel <- fetchedParams{options: [][]string{}}
 			return
 		}
 		opt, err := fetchParams(buildConf.Blockchain)
-		if err != nil {
-			util.PrintErrorFatal(err)
-		}
-		optionsChannel <- opt
+		optionsChannel <- fetchedParams{options: opt, err: err}
 	}()
 
 	if nodesEnabled {
@@ -147,44 +308,74 @@ func Build(cmd *cobra.Command, args []string, isAppend bool) {
 	} else {
 		buildConf.Nodes, err = strconv.Atoi(buildArr[offset])
 		if err != nil {
-			util.InvalidInteger("nodes", buildArr[offset], true)
+			return util.InvalidInteger("nodes", buildArr[offset])
 		}
 		offset++
 	}
 
-	options := <-optionsChannel //Currently has a negative impact but will be positive in the future
-	if validators < 0 && hasParam(options, "validators") && !isAppend {
+	if spec != nil {
+		err = spec.Apply(&buildConf)
+		if err != nil {
+			return err
+		}
+	}
+
+	fetched := <-optionsChannel //Currently has a negative impact but will be positive in the future
+	if fetched.err != nil {
+		return fetched.err
+	}
+	options := fetched.options
+	if errs := build.CheckFlags(cmd, buildConf.Nodes, options); len(errs) > 0 {
+		return errs
+	}
+	_, givenValidators := buildConf.Params["validators"]
+	if validators < 0 && hasParam(options, "validators") && !isAppend && !givenValidators {
 		if !util.IsTTY() {
-			util.PrintErrorFatal("missing validators and couldn't prompt")
+			return util.NewValidationError("missing validators and couldn't prompt")
 		}
 		fmt.Print("validators: ")
 		if !scanner.Scan() {
-			util.PrintErrorFatal(scanner.Err())
+			return fmt.Errorf("unable to read the validators: %v", scanner.Err())
 		}
 		text := scanner.Text()
 		validators, err = strconv.Atoi(text)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return util.InvalidInteger("validators", text)
+		}
+	}
+	err = build.HandleImageFlag(cmd, args, &buildConf)
+	if err != nil {
+		return err
+	}
+	givenOpts, err := build.HandleOptions(cmd, args, &buildConf, options)
+	if err != nil {
+		return err
+	}
+
+	useDefaults := givenOpts || spec != nil || base != nil || previousYesAll
+	if len(paramsFile) == 0 && !useDefaults {
+		useDefaults, err = util.YesNoPrompt("Use default parameters?")
+		if err != nil {
+			return err
 		}
 	}
-	build.HandleImageFlag(cmd, args, &buildConf)
-	givenOpts := build.HandleOptions(cmd, args, &buildConf, options)
 
 	if len(paramsFile) != 0 {
 		f, err := os.Open(paramsFile)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
+		defer f.Close()
 
 		decoder := json.NewDecoder(f)
 		decoder.UseNumber()
 		err = decoder.Decode(&buildConf.Params)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
-	} else if !givenOpts && !previousYesAll && !util.YesNoPrompt("Use default parameters?") {
+	} else if !useDefaults {
 		if !util.IsTTY() {
-			util.PrintErrorFatal("not a tty")
+			return util.NewValidationError("not a tty")
 		}
 		//PARAMS
 		for i := 0; i < len(options); i++ {
@@ -207,7 +398,7 @@ func Build(cmd *cobra.Command, args []string, isAppend bool) {
 			case "int":
 				val, err := strconv.ParseInt(text, 0, 64)
 				if err != nil {
-					util.InvalidInteger(key, text, false)
+					util.PrintStringError(util.InvalidInteger(key, text).Error())
 					i--
 					continue
 				}
@@ -215,7 +406,7 @@ func Build(cmd *cobra.Command, args []string, isAppend bool) {
 			case "bool":
 				val, err := util.GetAsBool(text)
 				if err != nil {
-					util.PrintErrorFatal(err)
+					util.PrintStringError(err.Error())
 					i--
 					continue
 				}
@@ -226,28 +417,62 @@ func Build(cmd *cobra.Command, args []string, isAppend bool) {
 	if validators >= 0 {
 		buildConf.Params["validators"] = validators
 	}
-	build.HandleFreezeBeforeGenesis(cmd, args, &buildConf)
-	build.HandleResources(cmd, args, &buildConf)
-	build.HandleFilesFlag(cmd, args, &buildConf)
-	build.HandleEnv(cmd, args, &buildConf)
-	build.HandleServersFlag(cmd, args, &buildConf)
+	err = build.HandleFreezeBeforeGenesis(cmd, args, &buildConf)
+	if err != nil {
+		return err
+	}
+	_, _, err = build.HandleResources(cmd, args, &buildConf)
+	if err != nil {
+		return err
+	}
+	for _, handle := range []func(*cobra.Command, []string, *build.Config) error{
+		build.HandleFilesFlag,
+		build.HandleEnv,
+		build.HandleServersFlag,
+	} {
+		err = handle(cmd, args, &buildConf)
+		if err != nil {
+			return err
+		}
+	}
 	build.HandlePullFlag(cmd, args, &buildConf)
 	build.HandleForceUnlockFlag(cmd, args, &buildConf)
-	build.HandleDockerAuthFlags(cmd, args, &buildConf)
-	build.HandleSSHOptions(cmd, args, &buildConf)
-	build.HandleDockerfile(cmd, args, &buildConf)
-	build.HandleRepoBuild(cmd, args, &buildConf)
-	build.HandleBoundCPUs(cmd, args, &buildConf)
-	build.HandlePortMapping(cmd, args, &buildConf)
-	build.HandleExposeAllBuildFlag(cmd, args, &buildConf, previousNumberNodes)
+	for _, handle := range []func(*cobra.Command, []string, *build.Config) error{
+		build.HandleDockerAuthFlags,
+		build.HandleSSHOptions,
+		build.HandleDockerfile,
+		build.HandleRepoBuild,
+		build.HandleBoundCPUs,
+		build.HandlePortMapping,
+	} {
+		err = handle(cmd, args, &buildConf)
+		if err != nil {
+			return err
+		}
+	}
+	err = build.HandleExposeAllBuildFlag(cmd, args, &buildConf, previousNumberNodes)
+	if err != nil {
+		return err
+	}
 
 	if !isAppend {
-		build.HandleStartLoggingAtBlock(cmd, args, &buildConf)
+		err = build.HandleStartLoggingAtBlock(cmd, args, &buildConf)
+		if err != nil {
+			return err
+		}
 	}
 	log.WithFields(log.Fields{"build": buildConf, "dest": conf.ServerAddr, "api": conf.APIURL}).Trace("sending the build request")
 	build.SanitizeBuild(&buildConf)
-	build.HandleDebugBuild(cmd, args, &buildConf)
-	buildStart(buildConf, isAppend)
+	if spec != nil {
+		if errs := build.Validate(buildConf, options); len(errs) > 0 {
+			return errs
+		}
+	}
+	debug, err := build.HandleDebugBuild(cmd, args, &buildConf)
+	if err != nil || debug {
+		return err
+	}
+	return buildStart(buildConf, isAppend, testnet)
 }
 
 var buildCmd = &cobra.Command{
@@ -258,8 +483,8 @@ var buildCmd = &cobra.Command{
 		" Each node will be instantiated in its own container and will interact" +
 		" individually as a participant of the specified network.\n",
 
-	Run: func(cmd *cobra.Command, args []string) {
-		Build(cmd, args, false)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return Build(cmd, args, false)
 	},
 }
 
@@ -269,13 +494,12 @@ var buildAttachCmd = &cobra.Command{
 	Short:   "Build a blockchain using previous configurations",
 	Long:    "\nAttach to a current in progress build process\n",
 
-	Run: func(cmd *cobra.Command, args []string) {
-		var buildID string
-		err := util.GetP("in_progress_build_id", &buildID)
-		if err != nil || len(buildID) == 0 {
-			util.PrintErrorFatal("No in progress build found. Use build command to deploy a blockchain.")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		buildID, err := inProgressBuildID()
+		if err != nil {
+			return err
 		}
-		buildAttach(buildID)
+		return buildAttach(buildID)
 	},
 }
 
@@ -285,22 +509,28 @@ var previousCmd = &cobra.Command{
 	Short:   "Build a blockchain using previous configurations",
 	Long:    "\nBuild previous will recreate and deploy the previously built blockchain and specified number of nodes.\n",
 
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 
 		prevBuild, err := build.GetPreviousBuild()
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		previousYesAll, err := cmd.Flags().GetBool("yes")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		util.Print(prevBuild)
-		if previousYesAll || util.YesNoPrompt("Build from previous?") {
+		if !previousYesAll {
+			previousYesAll, err = util.YesNoPrompt("Build from previous?")
+			if err != nil {
+				return err
+			}
+		}
+		if previousYesAll {
 			util.Print("building from previous configuration")
-			buildStart(prevBuild, false)
-			return
+			return buildStart(prevBuild, false, util.Testnet{})
 		}
+		return nil
 	},
 }
 
@@ -310,14 +540,127 @@ var buildStopCmd = &cobra.Command{
 	Short:   "Stops the current build",
 	Long:    "\nBuild stops the current building process.\n",
 
-	Run: func(cmd *cobra.Command, args []string) {
-		var buildID string
-		err := util.GetP("in_progress_build_id", &buildID)
-		if err != nil || len(buildID) == 0 {
-			util.PrintErrorFatal("No in-progress build found. Use build command to deploy a blockchain.")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		buildID, err := inProgressBuildID()
+		if err != nil {
+			return err
 		}
 		defer util.Delete("in_progress_build_id")
-		util.JsonRpcCallAndPrint("stop_build", []interface{}{buildID})
+		err = util.JsonRpcCallAndPrint("stop_build", []interface{}{buildID})
+		if err != nil {
+			return err
+		}
+		err = build.SetBuildOutcome(buildID, build.OutcomeStopped, nil)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Warn("unable to record the outcome of the build")
+		}
+		return nil
+	},
+}
+
+var buildHistoryCmd = &cobra.Command{
+	Use:     "history",
+	Aliases: []string{"hist"},
+	Short:   "List the builds started from here",
+	Long: `
+Lists the builds started from this machine, oldest first, with the testnet each built and how it
+ended. The number of a build may be given to build diff and build from. The last 100 builds are kept.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
+		}
+		history, err := build.GetHistory()
+		if err != nil {
+			return err
+		}
+		if util.IsStructuredOutput() && util.OutputFormat() != util.OutputTable {
+			util.Print(history)
+			return nil
+		}
+		out := []map[string]interface{}{}
+		for _, entry := range history {
+			duration := ""
+			if entry.Duration() > 0 {
+				duration = entry.Duration().Round(time.Second).String()
+			}
+			kind := "build"
+			if entry.Append {
+				kind = "append"
+			}
+			out = append(out, map[string]interface{}{
+				"number":     entry.Number,
+				"started":    entry.Started.Format(time.RFC3339),
+				"kind":       kind,
+				"testnet":    entry.TestnetID,
+				"blockchain": entry.Config.Blockchain,
+				"nodes":      entry.Config.Nodes,
+				"outcome":    entry.Outcome,
+				"duration":   duration,
+			})
+		}
+		util.PrintTable(out, "number", "started", "kind", "testnet", "blockchain", "nodes", "outcome", "duration")
+		return nil
+	},
+}
+
+var buildDiffCmd = &cobra.Command{
+	Use:   "diff <build> <build>",
+	Short: "Show how two builds in the history differ",
+	Long: `
+Shows each field of the build config which differs between two builds in the history, given by their
+number from build history.
+
+Example:
+	whiteblock build diff 3 7
+	nodes: 4 -> 6
+	images[4]: (none) -> "gcr.io/whiteblock/geth:master"
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 2, 2)
+		if err != nil {
+			return err
+		}
+		a, err := build.FindHistoryEntry(args[0])
+		if err != nil {
+			return err
+		}
+		b, err := build.FindHistoryEntry(args[1])
+		if err != nil {
+			return err
+		}
+		diffs, err := build.DiffConfigs(a.Config, b.Config)
+		if err != nil {
+			return err
+		}
+		if util.IsStructuredOutput() {
+			util.PrintTable(diffs, "field", "a", "b")
+			return nil
+		}
+		fmt.Println(build.RenderDiff(diffs))
+		return nil
+	},
+}
+
+var buildFromCmd = &cobra.Command{
+	Use:   "from <build>",
+	Short: "Build again from a build in the history",
+	Long: `
+Builds a new testnet from the config of a build in the history, given by its number from build history.
+The build flags override the values of the config, such as --nodes to change the number of nodes.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		entry, err := build.FindHistoryEntry(args[0])
+		if err != nil {
+			return err
+		}
+		util.Printf("building from build %d of %s", entry.Number, entry.Started.Format(time.RFC3339))
+		return buildFrom(cmd, []string{}, false, &entry.Config)
 	},
 }
 
@@ -326,13 +669,54 @@ var buildStatusCmd = &cobra.Command{
 	Aliases: []string{"progress"},
 	Short:   "Get the raw status of a build",
 	Long:    "Get the raw status of a build",
-	Run: func(cmd *cobra.Command, args []string) {
-		var buildID string
-		err := util.GetP("in_progress_build_id", &buildID)
-		if err != nil || len(buildID) == 0 {
-			util.PrintErrorFatal("No in-progress build found. Use build command to deploy a blockchain.")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		buildID, err := inProgressBuildID()
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("build_status", []string{buildID})
+	},
+}
+
+var buildHooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "List the build hooks",
+	Long: `
+Lists the hooks which run on the events of a build: start, stage, freeze, fail and complete. Hooks are
+read from hooks.yaml in the store directory, or the file given by BUILD_HOOKS, along with any given
+by --hook. A command is given the event as json on stdin, and in WB_EVENT, WB_TESTNET_ID, WB_STAGE
+and WB_PROGRESS. A url is posted the same json. A hook which fails does not stop the build.
+
+Example hooks.yaml:
+	- events: [complete]
+	  run: ./deploy-contracts.sh
+	- events: [fail, complete]
+	  url: https://ci.example.com/builds
+	  timeout: 10s
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
 		}
-		util.JsonRpcCallAndPrint("build_status", []string{buildID})
+		hooks, err := loadBuildHooks()
+		if err != nil {
+			return err
+		}
+		if util.IsStructuredOutput() && util.OutputFormat() != util.OutputTable {
+			util.Print(hooks)
+			return nil
+		}
+		out := []map[string]interface{}{}
+		for _, hook := range hooks {
+			events := "all"
+			if len(hook.Events) > 0 {
+				events = strings.Join(hook.Events, ",")
+			}
+			out = append(out, map[string]interface{}{"events": events, "hook": hook.Target()})
+		}
+		util.PrintTable(out, "events", "hook")
+		return nil
 	},
 }
 
@@ -341,13 +725,12 @@ var buildFreezeCmd = &cobra.Command{
 	Aliases: []string{"pause"},
 	Short:   "Pause a build",
 	Long:    "Pause a build",
-	Run: func(cmd *cobra.Command, args []string) {
-		var buildID string
-		err := util.GetP("in_progress_build_id", &buildID)
-		if err != nil || len(buildID) == 0 {
-			util.PrintErrorFatal("No in-progress build found. Use build command to deploy a blockchain.")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		buildID, err := inProgressBuildID()
+		if err != nil {
+			return err
 		}
-		util.JsonRpcCallAndPrint("freeze_build", []string{buildID})
+		return util.JsonRpcCallAndPrint("freeze_build", []string{buildID})
 	},
 }
 
@@ -356,14 +739,16 @@ var buildUnfreezeCmd = &cobra.Command{
 	Aliases: []string{"thaw", "resume"},
 	Short:   "Unpause a build",
 	Long:    "Unpause a build",
-	Run: func(cmd *cobra.Command, args []string) {
-		var buildID string
-		err := util.GetP("in_progress_build_id", &buildID)
-		if err != nil || len(buildID) == 0 {
-			util.PrintErrorFatal("No in-progress build found. Use build command to deploy a blockchain.")
-		}
-		util.JsonRpcCallAndPrint("unfreeze_build", []string{buildID})
-		buildAttach(buildID)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		buildID, err := inProgressBuildID()
+		if err != nil {
+			return err
+		}
+		err = util.JsonRpcCallAndPrint("unfreeze_build", []string{buildID})
+		if err != nil {
+			return err
+		}
+		return buildAttach(buildID)
 	},
 }
 
@@ -374,18 +759,25 @@ var buildAppendCmd = &cobra.Command{
 		" Each node will be instantiated in its own container and will interact" +
 		" individually as a participant of the specified network.\n",
 
-	Run: func(cmd *cobra.Command, args []string) {
-		Build(cmd, args, true)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return Build(cmd, args, true)
 	},
 }
 
 func init() {
 	build.AddBuildFlagsToCommand(buildCmd, false)
 	build.AddBuildFlagsToCommand(buildAppendCmd, true)
+	build.AddBuildFlagsToCommand(buildFromCmd, false)
 
+	buildCmd.PersistentFlags().DurationVar(&buildTimeout, "timeout", 0,
+		"give up following the build after this long, such as 30m, the default is from BUILD_TIMEOUT or no limit")
+	buildCmd.PersistentFlags().StringArrayVar(&buildHookFlags, "hook", nil,
+		"run a command or post to a url on an event of the build, such as --hook complete=./deploy.sh, see build hooks")
+	buildCmd.PersistentFlags().BoolVar(&buildNoHooks, "no-hooks", false, "do not run any build hooks")
 	previousCmd.Flags().BoolP("yes", "y", false, "Yes to all prompts. Evokes default parameters.")
 
 	buildCmd.AddCommand(previousCmd, buildAppendCmd, buildStopCmd, buildAttachCmd,
-		buildFreezeCmd, buildUnfreezeCmd, buildStatusCmd)
+		buildFreezeCmd, buildUnfreezeCmd, buildStatusCmd, buildHooksCmd,
+		buildHistoryCmd, buildDiffCmd, buildFromCmd)
 	RootCmd.AddCommand(buildCmd)
 }
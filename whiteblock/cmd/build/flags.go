@@ -7,7 +7,6 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/whiteblock/cli/whiteblock/util"
 	"io/ioutil"
-	"os"
 	"strings"
 )
 
@@ -20,6 +19,7 @@ func AddBuildFlagsToCommand(cmd *cobra.Command, isAppend bool) {
 	cmd.Flags().StringSliceP("cpus", "c", []string{"0"}, "specify number of cpus")
 	cmd.Flags().StringSliceP("memory", "m", []string{"0"}, "specify memory allocated")
 	cmd.Flags().StringP("file", "f", "", "parameters file")
+	cmd.Flags().String("spec", "", "build from a yaml or json spec file. Flags override the values in the spec")
 	cmd.Flags().IntP("validators", "v", -1, "set the number of validators")
 	cmd.Flags().StringSliceP("image", "i", []string{}, "image tag")
 	cmd.Flags().StringToStringP("option", "o", nil, "blockchain specific options")
@@ -42,57 +42,74 @@ func AddBuildFlagsToCommand(cmd *cobra.Command, isAppend bool) {
 	cmd.Flags().IntSlice("expose-all", []int{}, "expose a port linearly for all nodes")
 	//META FLAGS
 	if !isAppend {
+		cmd.Flags().String("name", "", "a name to refer to the testnet by, with --testnet")
+		cmd.Flags().StringToString("label", nil, "add labels to the testnet, such as --label team=infra")
 		cmd.Flags().Int("start-logging-at-block", 0, "specify a later block number to start at")
 		cmd.Flags().Int("bound-cpus", -1, "specify number of bound cpus")
 	}
 
 }
 
-func HandleFreezeBeforeGenesis(cmd *cobra.Command, args []string, bconf *Config) {
+func HandleFreezeBeforeGenesis(cmd *cobra.Command, args []string, bconf *Config) error {
 	if !cmd.Flags().Changed("freeze-before-genesis") {
-		return
+		return nil
 	}
 	fbg, err := cmd.Flags().GetBool("freeze-before-genesis")
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 	bconf.Extras["freezeAfterInfrastructure"] = fbg
+	return nil
 }
 
-func HandleEnv(cmd *cobra.Command, args []string, bconf *Config) {
+func HandleEnv(cmd *cobra.Command, args []string, bconf *Config) error {
 	if !cmd.Flags().Changed("env") {
-		return
+		return nil
 	}
 	envVars, err := cmd.Flags().GetStringToString("env")
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 
-	bconf.Environments = make([]map[string]string, bconf.Nodes)
+	for len(bconf.Environments) < bconf.Nodes {
+		bconf.Environments = append(bconf.Environments, nil)
+	}
 	for i, _ := range bconf.Environments {
-		bconf.Environments[i] = make(map[string]string)
+		if bconf.Environments[i] == nil {
+			bconf.Environments[i] = make(map[string]string)
+		}
 	}
 	for k, v := range envVars {
-		node, key := processEnvKey(k)
+		node, key, err := parseEnvKey(k)
+		if err != nil {
+			return util.ValidationError{Err: err}
+		}
 		if node == -1 {
 			for i, _ := range bconf.Environments {
 				bconf.Environments[i][key] = v
 			}
 			continue
 		}
+		if node >= bconf.Nodes {
+			return util.NewValidationError("node %d given to --env is out of range, there are %d nodes",
+				node, bconf.Nodes)
+		}
 		bconf.Environments[node][key] = v
 	}
+	return nil
 }
 
-func HandleOptions(cmd *cobra.Command, args []string, bconf *Config, format [][]string) bool {
+func HandleOptions(cmd *cobra.Command, args []string, bconf *Config, format [][]string) (bool, error) {
 	if !cmd.Flags().Changed("option") {
-		return false
+		return false, nil
 	}
 	givenOptions, err := cmd.Flags().GetStringToString("option")
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return false, err
+	}
+	if bconf.Params == nil {
+		bconf.Params = map[string]interface{}{}
 	}
-	bconf.Params = map[string]interface{}{}
 
 	for _, kv := range format {
 		name := kv[0]
@@ -110,7 +127,10 @@ func HandleOptions(cmd *cobra.Command, args []string, bconf *Config, format [][]
 			preprocessed := strings.Replace(val, " ", ",", -1)
 			bconf.Params[name] = strings.Split(preprocessed, ",")
 		case "int":
-			bconf.Params[name] = util.CheckAndConvertInt64(val, name)
+			bconf.Params[name], err = util.CheckAndConvertInt64(val, name)
+			if err != nil {
+				return false, err
+			}
 
 		case "bool":
 			switch val {
@@ -125,7 +145,7 @@ func HandleOptions(cmd *cobra.Command, args []string, bconf *Config, format [][]
 			}
 		}
 	}
-	return true
+	return true, nil
 }
 
 func HandleForceUnlockFlag(cmd *cobra.Command, args []string, bconf *Config) {
@@ -147,15 +167,23 @@ func HandlePullFlag(cmd *cobra.Command, args []string, bconf *Config) {
 	}
 }
 
-func HandleDockerAuthFlags(cmd *cobra.Command, args []string, bconf *Config) {
+func HandleDockerAuthFlags(cmd *cobra.Command, args []string, bconf *Config) error {
 	if cmd.Flags().Changed("docker-password") != cmd.Flags().Changed("docker-username") {
 		if cmd.Flags().Changed("docker-password") {
-			util.PrintErrorFatal("you must also provide --docker-password with --docker-username")
+			return util.NewValidationError("you must also provide --docker-password with --docker-username")
 		}
-		util.PrintErrorFatal("you must also provide --docker-username with --docker-password")
+		return util.NewValidationError("you must also provide --docker-username with --docker-password")
 	}
 	if !cmd.Flags().Changed("docker-password") {
-		return //The auth flags have not been set
+		return nil //The auth flags have not been set
+	}
+	username, err := cmd.Flags().GetString("docker-username")
+	if err != nil {
+		return err
+	}
+	password, err := cmd.Flags().GetString("docker-password")
+	if err != nil {
+		return err
 	}
 
 	_, ok := bconf.Extras["prebuild"]
@@ -164,27 +192,29 @@ func HandleDockerAuthFlags(cmd *cobra.Command, args []string, bconf *Config) {
 	}
 
 	bconf.Extras["prebuild"].(map[string]interface{})["auth"] = map[string]string{
-		"username": util.GetStringFlagValue(cmd, "docker-username"),
-		"password": util.GetStringFlagValue(cmd, "docker-password"),
+		"username": username,
+		"password": password,
 	}
-
+	return nil
 }
 
-func HandleImageFlag(cmd *cobra.Command, args []string, bconf *Config) {
-
+// HandleImageFlag resolves the image of every node from the image flag, keeping
+// any images already given by a spec.
+func HandleImageFlag(cmd *cobra.Command, args []string, bconf *Config) error {
 	imageFlag, err := cmd.Flags().GetStringSlice("image")
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 
+	givenImages := bconf.Images //given by a spec
 	bconf.Images = make([]string, bconf.Nodes)
 	images, potentialImage, err := util.UnrollStringSliceToMapIntString(imageFlag, "=")
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return util.ValidationError{Err: err}
 	}
 
 	if len(potentialImage) > 1 {
-		util.PrintErrorFatal("too many default images")
+		return util.NewValidationError("too many default images")
 	}
 	imgDefault := ""
 	if len(potentialImage) == 1 {
@@ -197,31 +227,47 @@ func HandleImageFlag(cmd *cobra.Command, args []string, bconf *Config) {
 		image, exists := images[i]
 		if exists {
 			log.WithFields(log.Fields{"image": image}).Trace("image exists")
-			bconf.Images[i] = determineImage(bconf.Blockchain, image)
+		} else if len(imgDefault) == 0 && i < len(givenImages) {
+			image = givenImages[i]
 		} else {
-			bconf.Images[i] = determineImage(bconf.Blockchain, imgDefault)
+			image = imgDefault
+		}
+		bconf.Images[i], err = ResolveImage(bconf.Blockchain, image)
+		if err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
-func HandleFilesFlag(cmd *cobra.Command, args []string, bconf *Config) {
+func HandleFilesFlag(cmd *cobra.Command, args []string, bconf *Config) error {
 	filesFlag, err := cmd.Flags().GetStringSlice("template")
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 	if filesFlag == nil {
-		return
+		return nil
+	}
+	if !cmd.Flags().Changed("template") && len(bconf.Files) > 0 { //given by a spec
+		return nil
 	}
 
-	bconf.Files = make([]map[string]string, bconf.Nodes)
+	for len(bconf.Files) < bconf.Nodes {
+		bconf.Files = append(bconf.Files, nil)
+	}
 	defaults := map[string]string{}
+	if extraDefaults, ok := bconf.Extras["defaults"].(map[string]interface{}); ok {
+		if files, ok := extraDefaults["files"].(map[string]string); ok {
+			defaults = files
+		}
+	}
 	for _, tfileIn := range filesFlag {
 		tuple := strings.SplitN(tfileIn, ";", 3) //support both delim in future
 		if len(tuple) < 3 {
 			tmp := strings.Replace(tfileIn, ";", "=", 1)
 			tuple = strings.SplitN(tmp, "=", 2)
 			if len(tuple) != 2 {
-				util.PrintErrorFatal(fmt.Errorf("Invalid argument"))
+				return util.NewValidationError("Invalid argument given to -t: %s", tfileIn)
 			}
 		}
 		for i := range tuple {
@@ -230,20 +276,25 @@ func HandleFilesFlag(cmd *cobra.Command, args []string, bconf *Config) {
 		if len(tuple) == 2 {
 			data, err := ioutil.ReadFile(tuple[1])
 			if err != nil {
-				util.PrintErrorFatal(err)
+				return err
 			}
 			defaults[tuple[0]] = base64.StdEncoding.EncodeToString(data)
 			continue
 		}
 		data, err := ioutil.ReadFile(tuple[2])
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
+		}
+		index, err := util.CheckAndConvertInt(tuple[0], "node number provided to -t")
+		if err != nil {
+			return err
 		}
-		index := util.CheckAndConvertInt(tuple[0], "node number provided to -t")
 		if index < 0 || index >= bconf.Nodes {
-			util.PrintErrorFatal(fmt.Errorf("Index is out of range for -t flag"))
+			return util.NewValidationError("Index is out of range for -t flag")
+		}
+		if bconf.Files[index] == nil {
+			bconf.Files[index] = map[string]string{}
 		}
-		bconf.Files[index] = map[string]string{}
 		bconf.Files[index][tuple[1]] = base64.StdEncoding.EncodeToString(data)
 	}
 
@@ -254,16 +305,17 @@ func HandleFilesFlag(cmd *cobra.Command, args []string, bconf *Config) {
 		bconf.Extras["defaults"] = map[string]interface{}{}
 	}
 	bconf.Extras["defaults"].(map[string]interface{})["files"] = defaults
+	return nil
 }
 
-func HandleSSHOptions(cmd *cobra.Command, args []string, bconf *Config) {
+func HandleSSHOptions(cmd *cobra.Command, args []string, bconf *Config) error {
 	if !cmd.Flags().Changed("user-ssh-key") { //Don't bother if not specified
-		return
+		return nil
 	}
 
 	sshPubKeys, err := cmd.Flags().GetStringSlice("user-ssh-key")
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 
 	if bconf.Extras == nil {
@@ -279,26 +331,27 @@ func HandleSSHOptions(cmd *cobra.Command, args []string, bconf *Config) {
 	for _, pubKeyFile := range sshPubKeys {
 		data, err := ioutil.ReadFile(pubKeyFile)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		pubKeys = append(pubKeys, string(data))
 	}
 
 	bconf.Extras["postbuild"].(map[string]interface{})["ssh"].(map[string]interface{})["pubKeys"] = pubKeys
+	return nil
 }
 
-func HandleDockerfile(cmd *cobra.Command, args []string, bconf *Config) {
+func HandleDockerfile(cmd *cobra.Command, args []string, bconf *Config) error {
 	if !cmd.Flags().Changed("dockerfile") {
-		return
+		return nil
 	}
 
-	filePath := util.GetStringFlagValue(cmd, "dockerfile")
-	if len(filePath) == 0 {
-		return
+	filePath, err := cmd.Flags().GetString("dockerfile")
+	if err != nil || len(filePath) == 0 {
+		return err
 	}
 	data, err := ioutil.ReadFile(filePath)
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 
 	if bconf.Extras == nil {
@@ -310,37 +363,43 @@ func HandleDockerfile(cmd *cobra.Command, args []string, bconf *Config) {
 	}
 	bconf.Extras["prebuild"].(map[string]interface{})["build"] = true
 	bconf.Extras["prebuild"].(map[string]interface{})["dockerfile"] = base64.StdEncoding.EncodeToString(data)
+	return nil
 }
 
-func HandleStartLoggingAtBlock(cmd *cobra.Command, args []string, bconf *Config) {
+func HandleStartLoggingAtBlock(cmd *cobra.Command, args []string, bconf *Config) error {
 	if !cmd.Flags().Changed("start-logging-at-block") { //Don't bother if not specified
-		return
+		return nil
 	}
-	bconf.Meta["startBlock"] = util.GetIntFlagValue(cmd, "start-logging-at-block")
+	startBlock, err := cmd.Flags().GetInt("start-logging-at-block")
+	if err != nil {
+		return err
+	}
+	bconf.Meta["startBlock"] = startBlock
+	return nil
 }
 
-func HandleResources(cmd *cobra.Command, args []string, bconf *Config) (givenCPU bool, givenMem bool) {
+func HandleResources(cmd *cobra.Command, args []string, bconf *Config) (givenCPU bool, givenMem bool, err error) {
 	givenCPU = cmd.Flags().Changed("cpus")
 	givenMem = cmd.Flags().Changed("memory")
 
-	if len(bconf.Resources) < bconf.Nodes {
-		bconf.Resources = make([]Resources, bconf.Nodes)
+	for len(bconf.Resources) < bconf.Nodes {
+		bconf.Resources = append(bconf.Resources, Resources{})
 	}
 
 	if givenCPU {
 		cpus, err := cmd.Flags().GetStringSlice("cpus")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return givenCPU, givenMem, err
 		}
 
 		explicitCpus, defaultCpu, err := util.UnrollStringSliceToMapIntString(cpus, "=")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return givenCPU, givenMem, util.ValidationError{Err: err}
 		}
 		log.Trace(explicitCpus, defaultCpu)
 
 		if len(defaultCpu) > 1 {
-			util.PrintErrorFatal("too many default cpus")
+			return givenCPU, givenMem, util.NewValidationError("too many default cpus")
 		}
 
 		cpuDefault := ""
@@ -353,7 +412,7 @@ func HandleResources(cmd *cobra.Command, args []string, bconf *Config) (givenCPU
 			cpu, exists := explicitCpus[i]
 			if exists {
 				bconf.Resources[i].Cpus = string(cpu)
-			} else {
+			} else if len(defaultCpu) == 1 {
 				bconf.Resources[i].Cpus = string(cpuDefault)
 			}
 		}
@@ -362,17 +421,17 @@ func HandleResources(cmd *cobra.Command, args []string, bconf *Config) (givenCPU
 	if givenMem {
 		memories, err := cmd.Flags().GetStringSlice("memory")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return givenCPU, givenMem, err
 		}
 
 		explicitMems, defaultMem, err := util.UnrollStringSliceToMapIntString(memories, "=")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return givenCPU, givenMem, util.ValidationError{Err: err}
 		}
 		log.Trace(explicitMems, defaultMem)
 
 		if len(defaultMem) > 1 {
-			util.PrintErrorFatal("too many default memory assignemnts")
+			return givenCPU, givenMem, util.NewValidationError("too many default memory assignemnts")
 		}
 
 		memDefault := ""
@@ -385,7 +444,7 @@ func HandleResources(cmd *cobra.Command, args []string, bconf *Config) (givenCPU
 			mem, exists := explicitMems[i]
 			if exists {
 				bconf.Resources[i].Memory = string(mem)
-			} else {
+			} else if len(defaultMem) == 1 {
 				bconf.Resources[i].Memory = string(memDefault)
 			}
 		}
@@ -394,9 +453,9 @@ func HandleResources(cmd *cobra.Command, args []string, bconf *Config) (givenCPU
 	return
 }
 
-func HandleRepoBuild(cmd *cobra.Command, args []string, bconf *Config) {
+func HandleRepoBuild(cmd *cobra.Command, args []string, bconf *Config) error {
 	if !cmd.Flags().Changed("git-repo") {
-		return
+		return nil
 	}
 	if bconf.Extras == nil {
 		bconf.Extras = map[string]interface{}{}
@@ -407,51 +466,65 @@ func HandleRepoBuild(cmd *cobra.Command, args []string, bconf *Config) {
 	}
 	bconf.Extras["prebuild"].(map[string]interface{})["build"] = true
 
-	repo := util.GetStringFlagValue(cmd, "git-repo")
+	repo, err := cmd.Flags().GetString("git-repo")
+	if err != nil {
+		return err
+	}
 
 	bconf.Extras["prebuild"].(map[string]interface{})["repo"] = repo
 	if cmd.Flags().Changed("git-repo-branch") {
-		branch := util.GetStringFlagValue(cmd, "git-repo-branch")
+		branch, err := cmd.Flags().GetString("git-repo-branch")
+		if err != nil {
+			return err
+		}
 		log.Trace("given a git repo branch")
 		bconf.Extras["prebuild"].(map[string]interface{})["branch"] = branch
 	}
+	return nil
 }
 
-func addPortMapping(portMapping map[int][]string, bconf *Config) {
-	firstResources := bconf.Resources[0]
+func addPortMapping(portMapping map[int][]string, bconf *Config) error {
+	firstResources := Resources{}
+	if len(bconf.Resources) > 0 {
+		firstResources = bconf.Resources[0]
+	}
 	for bconf.Nodes > len(bconf.Resources) {
 		bconf.Resources = append(bconf.Resources, firstResources)
 	}
 	for node, mappings := range portMapping {
+		if node < 0 || node >= len(bconf.Resources) {
+			return util.NewValidationError("node %d given a port mapping is out of range, there are %d nodes",
+				node, bconf.Nodes)
+		}
 		bconf.Resources[node].Ports = mappings
 		log.WithFields(log.Fields{"node": node, "ports": mappings}).Trace("adding the port mapping")
 	}
+	return nil
 }
 
-func HandlePortMapping(cmd *cobra.Command, args []string, bconf *Config) {
+func HandlePortMapping(cmd *cobra.Command, args []string, bconf *Config) error {
 	if !cmd.Flags().Changed("expose-port-mapping") {
-		return
+		return nil
 	}
 	portMapping, err := cmd.Flags().GetStringSlice("expose-port-mapping")
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 
 	parsedPortMapping, err := util.ParseIntToStringSlice(portMapping)
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return util.ValidationError{Err: err}
 	}
-	addPortMapping(parsedPortMapping, bconf)
-
+	return addPortMapping(parsedPortMapping, bconf)
 }
 
-func HandleExposeAllBuildFlag(cmd *cobra.Command, args []string, bconf *Config, offset int) {
+func HandleExposeAllBuildFlag(cmd *cobra.Command, args []string, bconf *Config, offset int) error {
 	if !cmd.Flags().Changed("expose-all") {
-		return
+		return nil
 	}
 	portsToExpose, err := cmd.Flags().GetIntSlice("expose-all")
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 
 	portMapping := map[int][]string{}
@@ -462,36 +535,44 @@ func HandleExposeAllBuildFlag(cmd *cobra.Command, args []string, bconf *Config,
 			portToBind := portToExpose + i + offset
 			_, used := usedPort[portToBind]
 			if used {
-				util.PrintErrorFatal(
-					fmt.Sprintf("would duplicate exposed port %d. Too many nodes to run auto expose", portToExpose))
+				return util.NewValidationError(
+					"would duplicate exposed port %d. Too many nodes to run auto expose", portToExpose)
 			}
 			portMapping[i] = append(portMapping[i], fmt.Sprintf("%d:%d", portToBind, portToExpose))
 		}
 	}
-	addPortMapping(portMapping, bconf)
+	return addPortMapping(portMapping, bconf)
 }
 
-func HandleServersFlag(cmd *cobra.Command, args []string, bconf *Config) {
+func HandleServersFlag(cmd *cobra.Command, args []string, bconf *Config) error {
 	if !cmd.Flags().Changed("servers") {
-		bconf.Servers = getServer()
-		return
+		if len(bconf.Servers) > 0 {
+			return nil
+		}
+		servers, err := getServer()
+		bconf.Servers = servers
+		return err
 	}
 	servers, err := cmd.Flags().GetIntSlice("servers")
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 	bconf.Servers = servers
+	return nil
 }
 
-func HandleBoundCPUs(cmd *cobra.Command, args []string, bconf *Config) {
+func HandleBoundCPUs(cmd *cobra.Command, args []string, bconf *Config) error {
 	if !cmd.Flags().Changed("bound-cpus") {
-		return
+		return nil
 	}
 	firstResources := bconf.Resources[0]
 	for bconf.Nodes > len(bconf.Resources) {
 		bconf.Resources = append(bconf.Resources, firstResources)
 	}
-	numCPUs := util.GetIntFlagValue(cmd, "bound-cpus")
+	numCPUs, err := cmd.Flags().GetInt("bound-cpus")
+	if err != nil {
+		return err
+	}
 	cpuNo := 0
 	for i := range bconf.Resources {
 		bconf.Resources[i].BoundCPUs = []int{}
@@ -500,11 +581,15 @@ func HandleBoundCPUs(cmd *cobra.Command, args []string, bconf *Config) {
 			cpuNo++
 		}
 	}
+	return nil
 }
 
-func HandleDebugBuild(cmd *cobra.Command, args []string, bconf *Config) {
-	if util.GetBoolFlagValue(cmd, "debug") {
-		util.Print(*bconf)
-		os.Exit(0)
+// HandleDebugBuild prints the build instead of running it when given --debug, telling whether
+// it did so
+func HandleDebugBuild(cmd *cobra.Command, args []string, bconf *Config) (bool, error) {
+	debug, err := cmd.Flags().GetBool("debug")
+	if err != nil || !debug {
+		return false, err
 	}
+	return true, util.Print(*bconf)
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/whiteblock/cli/whiteblock/util"
 	"io/ioutil"
 	"os/user"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -41,14 +42,15 @@ func getImageTable() (map[string]map[string]map[string]map[string]string, error)
 	return _imageTable, nil
 }
 
-func determineImage(blockchain string, requested string) string {
-
+// ResolveImage resolves the requested image name for blockchain to the full image,
+// using the image table. An empty request resolves to the stable image.
+func ResolveImage(blockchain string, requested string) (string, error) {
 	cont, err := getImageTable()
 	if err != nil {
 		if len(requested) > 0 {
-			return requested
+			return requested, nil
 		}
-		util.PrintErrorFatal(err)
+		return "", fmt.Errorf("unable to determine the image for %s: %v", blockchain, err)
 	}
 
 	defaultImage := "gcr.io/whiteblock/" + blockchain + ":master"
@@ -58,30 +60,30 @@ func determineImage(blockchain string, requested string) string {
 	if _, ok := cont["blockchains"][blockchain]; !ok {
 		log.Debug("chose default image due to missing entry")
 		if len(requested) > 0 {
-			return requested
+			return requested, nil
 		}
-		return defaultImage
+		return defaultImage, nil
 	}
 	if _, ok := cont["blockchains"][blockchain]["images"]; !ok {
 		log.Debug("chose default image due to missing entry")
 		if len(requested) > 0 {
-			return requested
+			return requested, nil
 		}
-		return defaultImage
+		return defaultImage, nil
 	}
 
 	if len(requested) == 0 {
 		if stableImage, ok := cont["blockchains"][blockchain]["images"]["stable"]; ok {
-			return stableImage
+			return stableImage, nil
 		}
 		log.Debugf("missing default stable image for %s", blockchain)
-		return defaultImage
+		return defaultImage, nil
 	}
 
 	if image, ok := cont["blockchains"][blockchain]["images"][requested]; ok {
-		return image
+		return image, nil
 	}
-	return requested
+	return requested, nil
 }
 
 func SanitizeBuild(conf *Config) {
@@ -91,44 +93,58 @@ func SanitizeBuild(conf *Config) {
 	}
 }
 
-func getServer() []int {
-	idList := make([]int, 0)
+func getServer() ([]int, error) {
 	res, err := util.JsonRpcCall("get_servers", []string{})
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return nil, err
+	}
+	return DefaultServers(res)
+}
+
+// DefaultServers picks the servers to build on from the reply of get_servers, for a build
+// which does not give any
+func DefaultServers(res interface{}) ([]int, error) {
+	idList := make([]int, 0)
+	servers, ok := res.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected format for the servers")
 	}
-	servers := res.(map[string]interface{})
-	serverID := 0
 	for _, v := range servers {
-		serverID = int(v.(map[string]interface{})["id"].(float64))
+		server, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected format for the servers")
+		}
+		serverID, ok := server["id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected format for the servers")
+		}
 		//move this and take out break statement if instance has multiple servers
-		idList = append(idList, serverID)
+		idList = append(idList, int(serverID))
 		break
 	}
-	return idList
+	return idList, nil
 }
 
-func GetPreviousBuildIDErr() (string, error) {
+// GetPreviousBuildID gets the id of the testnet to run the commands against, which is the
+// testnet given with --testnet, or otherwise the previous build
+func GetPreviousBuildID() (string, error) {
+	if ref := util.SelectedTestnet(); len(ref) > 0 {
+		testnet, err := util.FindTestnet(ref)
+		if err != nil {
+			return "", err
+		}
+		return testnet.ID, nil
+	}
 	var buildID string
 	err := util.GetP("previous_build_id", &buildID)
 	if err != nil || len(buildID) == 0 {
-		return "", fmt.Errorf("No previous build. Use build command to deploy a blockchain, " +
-			"or run `whiteblock sync` if you already have a blockchain deployed.")
+		return "", util.NoPreviousBuildError{}
 	}
 	return buildID, nil
 }
 
-//"github.com/sirupsen/logrus"
-func GetPreviousBuildID() string {
-	res, err := GetPreviousBuildIDErr()
-	if err != nil {
-		util.PrintErrorFatal(err)
-	}
-	return res
-}
-
 func GetPreviousBuild() (Config, error) {
-	buildId, err := GetPreviousBuildIDErr()
+	buildId, err := GetPreviousBuildID()
 	if err != nil {
 		return Config{}, err
 	}
@@ -149,7 +165,7 @@ func GetPreviousBuild() (Config, error) {
 }
 
 func FetchPreviousBuild() (Config, error) {
-	buildId, err := GetPreviousBuildIDErr()
+	buildId, err := GetPreviousBuildID()
 	if err != nil {
 		return Config{}, err
 	}
@@ -169,9 +185,9 @@ func FetchPreviousBuild() (Config, error) {
 }
 
 //-1 means for all
-func processEnvKey(in string) (int, string) {
+func parseEnvKey(in string) (int, string, error) {
 	node := -1
-	index := 0
+	index := len(in)
 	for i, char := range in {
 		if char < '0' || char > '9' {
 			index = i
@@ -179,11 +195,15 @@ func processEnvKey(in string) (int, string) {
 		}
 	}
 	if index == 0 {
-		return node, in
+		return node, in, nil
 	}
 
 	if index == len(in) {
-		util.PrintErrorFatal("Cannot have a numerical environment variable")
+		return node, "", fmt.Errorf("Cannot have a numerical environment variable")
+	}
+	node, err := strconv.Atoi(in[:index])
+	if err != nil {
+		return node, "", fmt.Errorf("Invalid integer, given \"%s\" for node.", in[:index])
 	}
-	return util.CheckAndConvertInt(in[:index], "node"), in[index:len(in)]
+	return node, in[index:len(in)], nil
 }
@@ -0,0 +1,214 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	historyKey = "build_history"
+	// maxHistory is the number of builds kept in the history, the oldest are dropped past it
+	maxHistory = 100
+)
+
+// The outcomes of a build in the history
+const (
+	OutcomeRunning   = "running"
+	OutcomeCompleted = "completed"
+	OutcomeFailed    = "failed"
+	OutcomeStopped   = "stopped"
+)
+
+// HistoryEntry is a build which was started from here
+type HistoryEntry struct {
+	Number    int       `json:"number"`
+	TestnetID string    `json:"testnetId"`
+	Append    bool      `json:"append,omitempty"`
+	Started   time.Time `json:"started"`
+	// Finished is when the build was seen to finish, it is zero until then
+	Finished time.Time `json:"finished"`
+	Outcome  string    `json:"outcome"`
+	Error    string    `json:"error,omitempty"`
+	Config   Config    `json:"config"`
+}
+
+// Duration gets how long the build took, or zero if it has not been seen to finish
+func (entry HistoryEntry) Duration() time.Duration {
+	if entry.Finished.IsZero() {
+		return 0
+	}
+	return entry.Finished.Sub(entry.Started)
+}
+
+// GetHistory gets the builds in the history, oldest first
+func GetHistory() ([]HistoryEntry, error) {
+	out := []HistoryEntry{}
+	if !util.Exists(historyKey) {
+		return out, nil
+	}
+	return out, util.GetP(historyKey, &out)
+}
+
+// withoutSecrets copies the build config, leaving out the docker credentials so that they are
+// not kept in the history
+func withoutSecrets(conf Config) (Config, error) {
+	data, err := json.Marshal(conf)
+	if err != nil {
+		return Config{}, err
+	}
+	var out Config
+	err = json.Unmarshal(data, &out)
+	if err != nil {
+		return Config{}, err
+	}
+	if prebuild, ok := out.Extras["prebuild"].(map[string]interface{}); ok {
+		delete(prebuild, "auth")
+	}
+	return out, nil
+}
+
+// RecordBuild adds a build which has just been started to the history
+func RecordBuild(conf Config, testnetID string, isAppend bool) (HistoryEntry, error) {
+	history, err := GetHistory()
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	conf, err = withoutSecrets(conf)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	entry := HistoryEntry{Number: 1, TestnetID: testnetID, Append: isAppend, Started: time.Now(),
+		Outcome: OutcomeRunning, Config: conf}
+	if len(history) > 0 {
+		entry.Number = history[len(history)-1].Number + 1
+	}
+	history = append(history, entry)
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	return entry, util.Set(historyKey, history)
+}
+
+// SetBuildOutcome records the outcome of the latest running build of the testnet, if it is in
+// the history
+func SetBuildOutcome(testnetID string, outcome string, buildErr error) error {
+	history, err := GetHistory()
+	if err != nil {
+		return err
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].TestnetID != testnetID || history[i].Outcome != OutcomeRunning {
+			continue
+		}
+		history[i].Outcome = outcome
+		history[i].Finished = time.Now()
+		if buildErr != nil {
+			history[i].Error = buildErr.Error()
+		}
+		return util.Set(historyKey, history)
+	}
+	return nil
+}
+
+// FindHistoryEntry gets the build with the given number from the history
+func FindHistoryEntry(ref string) (HistoryEntry, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(ref, "#"))
+	if err != nil {
+		return HistoryEntry{}, util.NewValidationError(`expected the number of a build, got "%s"`, ref)
+	}
+	history, err := GetHistory()
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	for _, entry := range history {
+		if entry.Number == number {
+			return entry, nil
+		}
+	}
+	return HistoryEntry{}, util.NewValidationError("build %d is not in the history, see build history", number)
+}
+
+// FieldDiff is a field which differs between two build configs, where a missing value is empty
+type FieldDiff struct {
+	Field string `json:"field"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+// flatten gets each value within v by its path, such as resources[0].cpus, as json
+func flatten(prefix string, v interface{}, out map[string]string) {
+	switch val := v.(type) {
+	case nil:
+	case map[string]interface{}:
+		for key, inner := range val {
+			path := key
+			if len(prefix) > 0 {
+				path = prefix + "." + key
+			}
+			flatten(path, inner, out)
+		}
+	case []interface{}:
+		for i, inner := range val {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), inner, out)
+		}
+	default:
+		data, _ := json.Marshal(val)
+		out[prefix] = string(data)
+	}
+}
+
+// DiffConfigs gets the fields which differ between two build configs, in order of the field
+func DiffConfigs(a Config, b Config) ([]FieldDiff, error) {
+	fields := [2]map[string]string{{}, {}}
+	for i, conf := range []Config{a, b} {
+		data, err := json.Marshal(conf)
+		if err != nil {
+			return nil, err
+		}
+		var v interface{}
+		err = json.Unmarshal(data, &v)
+		if err != nil {
+			return nil, err
+		}
+		flatten("", v, fields[i])
+	}
+	out := []FieldDiff{}
+	for field, valA := range fields[0] {
+		if valB := fields[1][field]; valA != valB {
+			out = append(out, FieldDiff{Field: field, A: valA, B: valB})
+		}
+	}
+	for field, valB := range fields[1] {
+		if _, ok := fields[0][field]; !ok {
+			out = append(out, FieldDiff{Field: field, B: valB})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Field < out[j].Field
+	})
+	return out, nil
+}
+
+// RenderDiff describes the differences as text, one field per line
+func RenderDiff(diffs []FieldDiff) string {
+	if len(diffs) == 0 {
+		return "the builds are the same"
+	}
+	out := []string{}
+	for _, diff := range diffs {
+		a, b := diff.A, diff.B
+		if len(a) == 0 {
+			a = "(none)"
+		}
+		if len(b) == 0 {
+			b = "(none)"
+		}
+		out = append(out, fmt.Sprintf("%s: %s -> %s", diff.Field, a, b))
+	}
+	return strings.Join(out, "\n")
+}
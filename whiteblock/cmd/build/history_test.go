@@ -0,0 +1,143 @@
+package build
+
+import (
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// prebuildConfig gets a build config with docker credentials for the prebuild
+func prebuildConfig() Config {
+	return Config{
+		Blockchain: "geth",
+		Nodes:      2,
+		Extras: map[string]interface{}{
+			"prebuild": map[string]interface{}{
+				"auth":  map[string]interface{}{"username": "me", "password": "secret"},
+				"build": true,
+			},
+		},
+	}
+}
+
+func TestWithoutSecrets(t *testing.T) {
+	conf := prebuildConfig()
+	out, err := withoutSecrets(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prebuild := out.Extras["prebuild"].(map[string]interface{})
+	if _, ok := prebuild["auth"]; ok {
+		t.Errorf("expected the docker credentials to be removed, got %v", prebuild)
+	}
+	if prebuild["build"] != true {
+		t.Errorf("expected the rest of the prebuild to be kept, got %v", prebuild)
+	}
+	if _, ok := conf.Extras["prebuild"].(map[string]interface{})["auth"]; !ok {
+		t.Error("expected the given config to be left as it was")
+	}
+
+	out, err = withoutSecrets(Config{Blockchain: "geth", Extras: map[string]interface{}{"prebuild": "yes"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Extras["prebuild"] != "yes" {
+		t.Errorf("unexpected extras %v", out.Extras)
+	}
+}
+
+func TestDiffConfigs(t *testing.T) {
+	var tests = []struct {
+		a        Config
+		b        Config
+		expected []FieldDiff
+	}{
+		{a: Config{Blockchain: "geth", Nodes: 2}, b: Config{Blockchain: "geth", Nodes: 2}, expected: []FieldDiff{}},
+		{
+			a: Config{Blockchain: "geth", Nodes: 2, Images: []string{"geth:1"}},
+			b: Config{Blockchain: "parity", Nodes: 2, Images: []string{"geth:1", "geth:2"}},
+			expected: []FieldDiff{
+				{Field: "blockchain", A: `"geth"`, B: `"parity"`},
+				{Field: "images[1]", B: `"geth:2"`},
+			},
+		},
+		{
+			a: Config{Resources: []Resources{{Cpus: "2", Memory: "4GB"}}, Params: map[string]interface{}{"chainId": 15}},
+			b: Config{Resources: []Resources{{Cpus: "2"}}, Params: map[string]interface{}{"network": "dev"}},
+			expected: []FieldDiff{
+				{Field: "params.chainId", A: "15"},
+				{Field: "params.network", B: `"dev"`},
+				{Field: "resources[0].memory", A: `"4GB"`, B: `""`},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			diffs, err := DiffConfigs(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(diffs, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, diffs)
+			}
+		})
+	}
+}
+
+func TestRenderDiff(t *testing.T) {
+	if RenderDiff([]FieldDiff{}) != "the builds are the same" {
+		t.Errorf("unexpected rendering of no differences: %s", RenderDiff([]FieldDiff{}))
+	}
+	out := RenderDiff([]FieldDiff{{Field: "blockchain", A: `"geth"`, B: `"parity"`}, {Field: "images[1]", B: `"geth:2"`}})
+	expected := "blockchain: \"geth\" -> \"parity\"\nimages[1]: (none) -> \"geth:2\""
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestRecordBuild(t *testing.T) {
+	saved, err := GetHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer util.Set(historyKey, saved)
+	util.Delete(historyKey)
+
+	for i := 1; i <= maxHistory+2; i++ {
+		entry, err := RecordBuild(prebuildConfig(), fmt.Sprintf("testnet%d", i), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Number != i || entry.Outcome != OutcomeRunning {
+			t.Fatalf("unexpected entry %+v", entry)
+		}
+	}
+	history, err := GetHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != maxHistory || history[0].Number != 3 || history[len(history)-1].Number != maxHistory+2 {
+		t.Fatalf("expected only the last %d builds to be kept, got %d from %d", maxHistory, len(history), history[0].Number)
+	}
+	if _, ok := history[0].Config.Extras["prebuild"].(map[string]interface{})["auth"]; ok {
+		t.Error("expected the docker credentials to be left out of the history")
+	}
+
+	err = SetBuildOutcome("testnet5", OutcomeFailed, fmt.Errorf("out of memory"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := FindHistoryEntry("#5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Outcome != OutcomeFailed || entry.Error != "out of memory" || entry.Finished.IsZero() {
+		t.Errorf("expected the build to have failed, got %+v", entry)
+	}
+	if _, err := FindHistoryEntry("2"); err == nil {
+		t.Error("expected an error for a build which was dropped from the history")
+	}
+}
@@ -0,0 +1,266 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// HookEvents are the points in the life of a build at which hooks may run
+var HookEvents = []string{"start", "stage", "freeze", "fail", "complete"}
+
+const defaultHookTimeout = time.Minute
+
+// Hook is a local command to run, or a url to post to, on events of a build. The payload is
+// given as json, on stdin to a command.
+type Hook struct {
+	// Events are the events to run the hook on, or every event if empty
+	Events []string `json:"events,omitempty"`
+	Run    string   `json:"run,omitempty"`
+	URL    string   `json:"url,omitempty"`
+	// Timeout is how long the hook may take, a minute if not given
+	Timeout util.Duration `json:"timeout,omitempty"`
+}
+
+// HookPayload is what a hook is given about the event
+type HookPayload struct {
+	Event     string    `json:"event"`
+	TestnetID string    `json:"testnetId"`
+	Status    Status    `json:"status"`
+	Time      time.Time `json:"time"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Validate checks that the hook has one of a command or url, and only known events
+func (hook Hook) Validate() error {
+	if (len(hook.Run) == 0) == (len(hook.URL) == 0) {
+		return fmt.Errorf("expected one of run or url")
+	}
+	if len(hook.URL) > 0 && !strings.HasPrefix(hook.URL, "http://") && !strings.HasPrefix(hook.URL, "https://") {
+		return fmt.Errorf(`invalid url "%s"`, hook.URL)
+	}
+	for _, event := range hook.Events {
+		known := false
+		for _, hookEvent := range HookEvents {
+			known = known || event == hookEvent
+		}
+		if !known {
+			return fmt.Errorf(`unknown event "%s", expected one of %s`, event, strings.Join(HookEvents, ", "))
+		}
+	}
+	return nil
+}
+
+// Handles checks if the hook runs on the given event
+func (hook Hook) Handles(event string) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, on := range hook.Events {
+		if on == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Target is the command or url of the hook
+func (hook Hook) Target() string {
+	if len(hook.Run) > 0 {
+		return hook.Run
+	}
+	return hook.URL
+}
+
+// ParseHookFlag parses a hook given as event=command, where a command starting with http://
+// or https:// is taken as a url to post to instead
+func ParseHookFlag(flag string) (Hook, error) {
+	parts := strings.SplitN(flag, "=", 2)
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		return Hook{}, util.NewValidationError(`invalid hook "%s", expected event=command, such as complete=./deploy.sh`, flag)
+	}
+	hook := Hook{Events: []string{parts[0]}, Run: parts[1]}
+	if strings.HasPrefix(parts[1], "http://") || strings.HasPrefix(parts[1], "https://") {
+		hook = Hook{Events: []string{parts[0]}, URL: parts[1]}
+	}
+	err := hook.Validate()
+	if err != nil {
+		return Hook{}, util.NewValidationError("hook %s: %v", flag, err)
+	}
+	return hook, nil
+}
+
+// HooksPath gets the path of the hooks file, which is hooks.yaml in the store directory
+// unless BUILD_HOOKS is set
+func HooksPath() string {
+	conf := util.GetConfig()
+	if len(conf.BuildHooks) > 0 {
+		return conf.BuildHooks
+	}
+	return conf.StoreDirectory + "hooks.yaml"
+}
+
+// ParseHooks parses a list of hooks from yaml or json
+func ParseHooks(data []byte) ([]Hook, error) {
+	out := []Hook{}
+	err := util.UnmarshalYAMLStrict(data, &out)
+	if err != nil {
+		return nil, util.ValidationError{Err: err}
+	}
+	for i, hook := range out {
+		err = hook.Validate()
+		if err != nil {
+			return nil, util.NewValidationError("hook %d: %v", i+1, err)
+		}
+	}
+	return out, nil
+}
+
+// LoadHooks reads the hooks from the file at path, giving none if it does not exist
+func LoadHooks(path string) ([]Hook, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []Hook{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out, err := ParseHooks(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
+
+// Hooks runs hooks on the events of a build, as its status changes. A hook which fails is
+// logged without stopping the build.
+type Hooks struct {
+	Hooks     []Hook
+	TestnetID string
+	stage     string
+	frozen    bool
+	ended     bool
+}
+
+// NewHooks creates Hooks for the build of the given testnet
+func NewHooks(testnetID string, hooks []Hook) *Hooks {
+	return &Hooks{Hooks: hooks, TestnetID: testnetID}
+}
+
+// Events gets the events which the status brings about, given the statuses before it
+func (h *Hooks) Events(status Status) []string {
+	if h.ended {
+		return nil
+	}
+	if status.Err() != nil {
+		h.ended = true
+		return []string{"fail"}
+	}
+	out := []string{}
+	if stage := status.StageName(); !status.Done() && !status.Frozen && stage != h.stage {
+		h.stage = stage
+		out = append(out, "stage")
+	}
+	if status.Frozen && !h.frozen {
+		out = append(out, "freeze")
+	}
+	h.frozen = status.Frozen
+	if status.Done() {
+		h.ended = true
+		out = append(out, "complete")
+	}
+	return out
+}
+
+// Update runs the hooks for each event the status brings about
+func (h *Hooks) Update(status Status) {
+	for _, event := range h.Events(status) {
+		h.Fire(event, status)
+	}
+}
+
+// Fail runs the hooks for the fail event with the given error, for a build which ended
+// without a failed status, unless the build has already ended
+func (h *Hooks) Fail(err error) {
+	if h.ended {
+		return
+	}
+	h.ended = true
+	h.Fire("fail", Status{Error: map[string]interface{}{"what": err.Error()}})
+}
+
+// Fire runs each of the hooks which handle the event, one after another
+func (h *Hooks) Fire(event string, status Status) {
+	payload := HookPayload{Event: event, TestnetID: h.TestnetID, Status: status, Time: time.Now()}
+	if err := status.Err(); err != nil {
+		payload.Error = err.Error()
+	}
+	for _, hook := range h.Hooks {
+		if !hook.Handles(event) {
+			continue
+		}
+		err := hook.Fire(payload)
+		if err != nil {
+			log.WithFields(log.Fields{"event": event, "hook": hook.Target(), "error": err}).Warn("build hook failed")
+		}
+	}
+}
+
+// Fire runs the hook with the given payload
+func (hook Hook) Fire(payload HookPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	timeout := time.Duration(hook.Timeout)
+	if timeout == 0 {
+		timeout = defaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	log.WithFields(log.Fields{"event": payload.Event, "hook": hook.Target()}).Debug("running build hook")
+
+	if len(hook.URL) > 0 {
+		req, err := http.NewRequest("POST", hook.URL, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		client, err := util.HTTPClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		if res.StatusCode >= 300 {
+			return fmt.Errorf("%s returned %d", hook.URL, res.StatusCode)
+		}
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook.Run)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stdout
+	if util.IsStructuredOutput() {
+		cmd.Stdout = os.Stderr
+	}
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"WB_EVENT="+payload.Event,
+		"WB_TESTNET_ID="+payload.TestnetID,
+		"WB_STAGE="+payload.Status.StageName(),
+		fmt.Sprintf("WB_PROGRESS=%.1f", payload.Status.Progress))
+	return cmd.Run()
+}
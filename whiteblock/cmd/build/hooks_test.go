@@ -0,0 +1,204 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParseHookFlag(t *testing.T) {
+	var tests = []struct {
+		flag     string
+		expected Hook
+		err      bool
+	}{
+		{flag: "complete=./deploy.sh", expected: Hook{Events: []string{"complete"}, Run: "./deploy.sh"}},
+		{flag: "fail=echo a=b", expected: Hook{Events: []string{"fail"}, Run: "echo a=b"}},
+		{flag: "stage=https://hooks.example.com/build", expected: Hook{Events: []string{"stage"}, URL: "https://hooks.example.com/build"}},
+		{flag: "complete", err: true},
+		{flag: "complete=", err: true},
+		{flag: "done=./deploy.sh", err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			hook, err := ParseHookFlag(tt.flag)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected an error for %s, got %+v", tt.flag, hook)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(hook, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, hook)
+			}
+		})
+	}
+}
+
+func TestParseHooks(t *testing.T) {
+	var tests = []struct {
+		data  string
+		hooks int
+		err   bool
+	}{
+		{data: `[]`, hooks: 0},
+		{data: `[{events: [stage, complete], run: ./notify.sh, timeout: 10s}, {url: "http://127.0.0.1:8080"}]`, hooks: 2},
+		{data: `[{"run": "./notify.sh"}]`, hooks: 1},
+		{data: `[{run: ./notify.sh, url: "http://127.0.0.1:8080"}]`, err: true},
+		{data: `[{events: [done], run: ./notify.sh}]`, err: true},
+		{data: `[{run: ./notify.sh, command: ./other.sh}]`, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			hooks, err := ParseHooks([]byte(tt.data))
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", hooks)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hooks) != tt.hooks {
+				t.Errorf("expected %d hooks, got %+v", tt.hooks, hooks)
+			}
+		})
+	}
+}
+
+func TestHookValidate(t *testing.T) {
+	var tests = []struct {
+		hook Hook
+		err  bool
+	}{
+		{hook: Hook{Run: "./deploy.sh"}},
+		{hook: Hook{URL: "https://hooks.example.com", Events: []string{"start", "fail"}}},
+		{hook: Hook{}, err: true},
+		{hook: Hook{Run: "./deploy.sh", URL: "https://hooks.example.com"}, err: true},
+		{hook: Hook{URL: "hooks.example.com"}, err: true},
+		{hook: Hook{Run: "./deploy.sh", Events: []string{"stage", "finish"}}, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := tt.hook.Validate()
+			if tt.err != (err != nil) {
+				t.Errorf("unexpected result validating %+v: %v", tt.hook, err)
+			}
+		})
+	}
+}
+
+func TestHooksEvents(t *testing.T) {
+	provisioning := Status{Progress: 10, Stage: "Provisioning"}
+	frozen := Status{Progress: 10, Stage: "Provisioning", Frozen: true}
+	failed := Status{Progress: 20, Error: map[string]interface{}{"what": "out of memory"}}
+	var tests = []struct {
+		statuses []Status
+		expected [][]string
+	}{
+		{
+			statuses: []Status{{}, provisioning, provisioning, {Progress: 50, Stage: "Starting"}, {Progress: 100},
+				{Progress: 100}},
+			expected: [][]string{{"stage"}, {"stage"}, {}, {"stage"}, {"complete"}, nil},
+		},
+		{
+			statuses: []Status{provisioning, frozen, frozen, provisioning, frozen},
+			expected: [][]string{{"stage"}, {"freeze"}, {}, {}, {"freeze"}},
+		},
+		{
+			statuses: []Status{provisioning, failed, provisioning, {Progress: 100}},
+			expected: [][]string{{"stage"}, {"fail"}, nil, nil},
+		},
+		{
+			statuses: []Status{frozen, {Progress: 100}},
+			expected: [][]string{{"freeze"}, {"complete"}},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			hooks := NewHooks("testnet1", nil)
+			for j, status := range tt.statuses {
+				events := hooks.Events(status)
+				if fmt.Sprint(events) != fmt.Sprint(tt.expected[j]) {
+					t.Errorf("status %d: expected the events %v, got %v", j, tt.expected[j], events)
+				}
+			}
+		})
+	}
+}
+
+func TestHookFireURL(t *testing.T) {
+	payloads := make(chan HookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var payload HookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		payloads <- payload
+	}))
+	defer server.Close()
+
+	hook := Hook{URL: server.URL}
+	err := hook.Fire(HookPayload{Event: "complete", TestnetID: "testnet1", Status: Status{Progress: 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := <-payloads
+	if payload.Event != "complete" || payload.TestnetID != "testnet1" || !payload.Status.Done() {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	hook = Hook{URL: server.URL + "/missing"}
+	if err := hook.Fire(HookPayload{Event: "fail"}); err == nil {
+		t.Error("expected an error when the url gives 404")
+	}
+}
+
+func TestHooksFail(t *testing.T) {
+	payloads := make(chan HookPayload, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload HookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		payloads <- payload
+	}))
+	defer server.Close()
+
+	hooks := NewHooks("testnet1", []Hook{{URL: server.URL, Events: []string{"fail"}}})
+	hooks.Update(Status{Progress: 10, Stage: "Provisioning"})
+	hooks.Fail(fmt.Errorf("the build was stopped"))
+	hooks.Fail(fmt.Errorf("the build was stopped again"))
+	if events := hooks.Events(Status{Progress: 100}); events != nil {
+		t.Errorf("expected no events after the build failed, got %v", events)
+	}
+	if len(payloads) != 1 {
+		t.Fatalf("expected the fail hook to run once, got %d runs", len(payloads))
+	}
+	payload := <-payloads
+	if payload.Event != "fail" || payload.Error != "the build was stopped" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	hooks = NewHooks("testnet1", []Hook{{URL: server.URL, Events: []string{"fail"}}})
+	hooks.Update(Status{Progress: 100})
+	hooks.Fail(fmt.Errorf("lost the connection"))
+	if len(payloads) != 0 {
+		t.Error("expected no fail hook for a build which completed")
+	}
+}
@@ -0,0 +1,8 @@
+package build
+
+import (
+	"github.com/whiteblock/cli/whiteblock/client"
+)
+
+// Node is a node of a testnet, as given by the nodes rpc call
+type Node = client.Node
@@ -0,0 +1,440 @@
+package build
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const allNodesKey = "all"
+
+// Spec is the declarative form of a build, as given to build --spec. It accepts
+// every field of Config, plus some shorthands which are expanded into the
+// per node arrays of the Config once the number of nodes is known.
+//
+// The node keyed maps take either a node index or "all".
+type Spec struct {
+	Config
+	Image     string                       `json:"image"`
+	Env       map[string]map[string]string `json:"env"`
+	Templates map[string]map[string]string `json:"templates"`
+	Ports     map[string][]string          `json:"ports"`
+	Groups    []Group                      `json:"groups"`
+
+	dir string
+}
+
+// Group is a named group of nodes which share a role, such as validators. The nodes of the
+// groups are numbered in the order the groups are given, and each node is labelled with the
+// name of its group.
+type Group struct {
+	Name   string `json:"name"`
+	Nodes  int    `json:"nodes"`
+	Image  string `json:"image"`
+	Cpus   string `json:"cpus"`
+	Memory string `json:"memory"`
+	// Env are the environment variables of each node of the group
+	Env map[string]string `json:"env"`
+	// Templates are the paths of the templates of each node of the group, by name
+	Templates map[string]string `json:"templates"`
+}
+
+// FieldError describes a problem with a single field of a build
+type FieldError struct {
+	Field string
+	Msg   string
+}
+
+func (fe FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", fe.Field, fe.Msg)
+}
+
+// FieldErrors is the collection of all of the problems found with a build
+type FieldErrors []FieldError
+
+func (fes FieldErrors) Error() string {
+	out := []string{fmt.Sprintf("found %d problem(s) with the build", len(fes))}
+	for _, fe := range fes {
+		out = append(out, "  "+fe.Error())
+	}
+	return strings.Join(out, "\n")
+}
+
+// ExitCode is util.ExitValidation
+func (fes FieldErrors) ExitCode() int {
+	return util.ExitValidation
+}
+
+func (fes *FieldErrors) add(field string, format string, a ...interface{}) {
+	*fes = append(*fes, FieldError{Field: field, Msg: fmt.Sprintf(format, a...)})
+}
+
+// LoadSpec reads a build spec from a yaml or json file
+func LoadSpec(path string) (*Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ParseSpec(data)
+	if _, ok := err.(FieldErrors); ok {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	spec.dir = filepath.Dir(path)
+	return spec, nil
+}
+
+// ParseSpec parses a build spec. Any errors relating to the structure of the
+// document are returned as FieldErrors.
+func ParseSpec(data []byte) (*Spec, error) {
+	spec := new(Spec)
+	err := util.UnmarshalYAMLStrict(data, spec)
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	spec.Blockchain = strings.ToLower(strings.TrimSpace(spec.Blockchain))
+	if spec.Params == nil {
+		spec.Params = map[string]interface{}{}
+	}
+	if spec.Extras == nil {
+		spec.Extras = map[string]interface{}{}
+	}
+	if spec.Meta == nil {
+		spec.Meta = map[string]interface{}{}
+	}
+	if len(spec.Resources) == 0 {
+		spec.Resources = []Resources{Resources{}}
+	}
+	if spec.Nodes == 0 {
+		for _, group := range spec.Groups {
+			spec.Nodes += group.Nodes
+		}
+	}
+	return spec, nil
+}
+
+func decodeError(err error) error {
+	switch e := err.(type) {
+	case *json.UnmarshalTypeError:
+		field := e.Field
+		if len(field) == 0 {
+			field = "spec"
+		}
+		return FieldErrors{FieldError{Field: field, Msg: fmt.Sprintf("expected %v, got %s", e.Type, e.Value)}}
+	case *json.SyntaxError:
+		return err
+	}
+	//encoding/json does not give a type for unknown fields
+	if strings.HasPrefix(err.Error(), "json: unknown field ") {
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return FieldErrors{FieldError{Field: field, Msg: "unknown field"}}
+	}
+	return err
+}
+
+// parseNodeKey converts the key of a node keyed map into a node index, -1 means all nodes
+func parseNodeKey(key string, nodes int) (int, error) {
+	if key == allNodesKey || key == "*" {
+		return -1, nil
+	}
+	node, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf(`expected a node index or "%s"`, allNodesKey)
+	}
+	if node < 0 || node >= nodes {
+		return 0, fmt.Errorf("node %d is out of range, there are %d nodes", node, nodes)
+	}
+	return node, nil
+}
+
+func sortedKeys(m interface{}) []string {
+	out := []string{}
+	switch val := m.(type) {
+	case map[string]map[string]string:
+		for k := range val {
+			out = append(out, k)
+		}
+	case map[string][]string:
+		for k := range val {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Apply expands the shorthands of the spec into bconf, which should be the spec's Config
+// with any command line overrides applied. bconf.Nodes must be final at this point.
+func (spec Spec) Apply(bconf *Config) error {
+	errs := FieldErrors{}
+	if bconf.Nodes <= 0 {
+		errs.add("nodes", "must be greater than 0")
+		return errs
+	}
+	if len(spec.Groups) > 0 {
+		spec.applyGroups(bconf, &errs)
+		if len(errs) > 0 {
+			return errs
+		}
+	}
+	if len(bconf.Images) > bconf.Nodes {
+		errs.add("images", "has %d entries, but there are only %d nodes", len(bconf.Images), bconf.Nodes)
+	}
+	for i := len(bconf.Images); i < bconf.Nodes; i++ {
+		bconf.Images = append(bconf.Images, spec.Image)
+	}
+
+	firstResources := bconf.Resources[0]
+	for bconf.Nodes > len(bconf.Resources) {
+		res := firstResources
+		res.Ports = nil //ports cannot be shared between nodes
+		bconf.Resources = append(bconf.Resources, res)
+	}
+
+	if len(spec.Env) > 0 {
+		for len(bconf.Environments) < bconf.Nodes {
+			bconf.Environments = append(bconf.Environments, map[string]string{})
+		}
+		for _, key := range sortedKeys(spec.Env) {
+			node, err := parseNodeKey(key, bconf.Nodes)
+			if err != nil {
+				errs.add("env."+key, "%v", err)
+				continue
+			}
+			for i := range bconf.Environments {
+				if node != -1 && node != i {
+					continue
+				}
+				if bconf.Environments[i] == nil {
+					bconf.Environments[i] = map[string]string{}
+				}
+				for k, v := range spec.Env[key] {
+					bconf.Environments[i][k] = v
+				}
+			}
+		}
+	}
+
+	if len(spec.Templates) > 0 {
+		for len(bconf.Files) < bconf.Nodes {
+			bconf.Files = append(bconf.Files, nil)
+		}
+		defaults := map[string]string{}
+		for _, key := range sortedKeys(spec.Templates) {
+			node, err := parseNodeKey(key, bconf.Nodes)
+			if err != nil {
+				errs.add("templates."+key, "%v", err)
+				continue
+			}
+			for name, path := range spec.Templates[key] {
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(spec.dir, path)
+				}
+				data, err := ioutil.ReadFile(path)
+				if err != nil {
+					errs.add(fmt.Sprintf("templates.%s.%s", key, name), "%v", err)
+					continue
+				}
+				if node == -1 {
+					defaults[name] = base64.StdEncoding.EncodeToString(data)
+					continue
+				}
+				if bconf.Files[node] == nil {
+					bconf.Files[node] = map[string]string{}
+				}
+				bconf.Files[node][name] = base64.StdEncoding.EncodeToString(data)
+			}
+		}
+		if _, ok := bconf.Extras["defaults"].(map[string]interface{}); !ok {
+			bconf.Extras["defaults"] = map[string]interface{}{}
+		}
+		bconf.Extras["defaults"].(map[string]interface{})["files"] = defaults
+	}
+
+	if len(spec.Ports) > 0 {
+		portMapping := map[int][]string{}
+		for _, key := range sortedKeys(spec.Ports) {
+			node, err := parseNodeKey(key, bconf.Nodes)
+			if err != nil {
+				errs.add("ports."+key, "%v", err)
+				continue
+			}
+			if node == -1 {
+				errs.add("ports."+key, "port mappings must be given per node")
+				continue
+			}
+			portMapping[node] = spec.Ports[key]
+		}
+		addPortMapping(portMapping, bconf)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// applyGroups expands the groups into the images, resources, environments, files and labels
+// of each node, which must not also be given per node
+func (spec Spec) applyGroups(bconf *Config, errs *FieldErrors) {
+	total := 0
+	names := map[string]bool{}
+	for i, group := range spec.Groups {
+		field := fmt.Sprintf("groups[%d]", i)
+		if len(group.Name) == 0 {
+			errs.add(field+".name", "is required")
+		} else if names[group.Name] {
+			errs.add(field+".name", "%s is given to more than one group", group.Name)
+		} else if strings.ContainsAny(group.Name, " \t\n=,") {
+			errs.add(field+".name", "invalid group name %q", group.Name)
+		}
+		names[group.Name] = true
+		if group.Nodes <= 0 {
+			errs.add(field+".nodes", "must be greater than 0")
+		}
+		if len(group.Cpus) > 0 {
+			if _, err := strconv.ParseFloat(group.Cpus, 64); err != nil {
+				errs.add(field+".cpus", "invalid number of cpus %q", group.Cpus)
+			}
+		}
+		total += group.Nodes
+	}
+	if len(*errs) > 0 {
+		return
+	}
+	if total != bconf.Nodes {
+		errs.add("groups", "have %d nodes in total, but there are %d nodes", total, bconf.Nodes)
+		return
+	}
+	for _, given := range []struct {
+		field string
+		len   int
+	}{
+		{field: "images", len: len(bconf.Images)},
+		{field: "environments", len: len(bconf.Environments)},
+		{field: "files", len: len(bconf.Files)},
+		{field: "labels", len: len(bconf.Labels)},
+	} {
+		if given.len > 0 {
+			errs.add(given.field, "cannot be given along with groups")
+		}
+	}
+	if len(bconf.Resources) > 1 {
+		errs.add("resources", "only the default resources may be given along with groups")
+	}
+	if len(*errs) > 0 {
+		return
+	}
+
+	defaults := bconf.Resources[0]
+	defaults.Ports = nil
+	bconf.Resources = bconf.Resources[:0]
+	for i, group := range spec.Groups {
+		res := defaults
+		if len(group.Cpus) > 0 {
+			res.Cpus = group.Cpus
+		}
+		if len(group.Memory) > 0 {
+			res.Memory = group.Memory
+		}
+		image := group.Image
+		if len(image) == 0 {
+			image = spec.Image
+		}
+		files := map[string]string{}
+		for name, path := range group.Templates {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(spec.dir, path)
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				errs.add(fmt.Sprintf("groups[%d].templates.%s", i, name), "%v", err)
+				continue
+			}
+			files[name] = base64.StdEncoding.EncodeToString(data)
+		}
+		for j := 0; j < group.Nodes; j++ {
+			env := map[string]string{}
+			for k, v := range group.Env {
+				env[k] = v
+			}
+			nodeFiles := map[string]string{}
+			for k, v := range files {
+				nodeFiles[k] = v
+			}
+			bconf.Images = append(bconf.Images, image)
+			bconf.Resources = append(bconf.Resources, res)
+			bconf.Environments = append(bconf.Environments, env)
+			bconf.Files = append(bconf.Files, nodeFiles)
+			bconf.Labels = append(bconf.Labels, group.Name)
+		}
+	}
+}
+
+// CheckConfig checks a build config for problems which would cause the build to fail,
+// returning all of the problems found.
+func CheckConfig(bconf Config) FieldErrors {
+	errs := FieldErrors{}
+	if len(bconf.Blockchain) == 0 {
+		errs.add("blockchain", "is required")
+	}
+	if bconf.Nodes <= 0 {
+		errs.add("nodes", "must be greater than 0")
+		return errs
+	}
+	if len(bconf.Images) != bconf.Nodes {
+		errs.add("images", "has %d entries, expected %d", len(bconf.Images), bconf.Nodes)
+	}
+	for i, image := range bconf.Images {
+		if len(image) == 0 {
+			errs.add(fmt.Sprintf("images[%d]", i), "image is empty")
+		}
+	}
+	if len(bconf.Resources) > bconf.Nodes {
+		errs.add("resources", "has %d entries, but there are only %d nodes", len(bconf.Resources), bconf.Nodes)
+	}
+	for i, res := range bconf.Resources {
+		if len(res.Cpus) > 0 {
+			if _, err := strconv.ParseFloat(res.Cpus, 64); err != nil {
+				errs.add(fmt.Sprintf("resources[%d].cpus", i), "invalid number of cpus %q", res.Cpus)
+			}
+		}
+		for j, port := range res.Ports {
+			if !validPortMapping(port) {
+				errs.add(fmt.Sprintf("resources[%d].ports[%d]", i, j),
+					"invalid port mapping %q, expected [host ip:]<host port>:<node port>", port)
+			}
+		}
+	}
+	if len(bconf.Environments) > bconf.Nodes {
+		errs.add("environments", "has %d entries, but there are only %d nodes", len(bconf.Environments), bconf.Nodes)
+	}
+	if len(bconf.Files) > bconf.Nodes {
+		errs.add("files", "has %d entries, but there are only %d nodes", len(bconf.Files), bconf.Nodes)
+	}
+	if len(bconf.Labels) > bconf.Nodes {
+		errs.add("labels", "has %d entries, but there are only %d nodes", len(bconf.Labels), bconf.Nodes)
+	}
+	return errs
+}
+
+func validPortMapping(mapping string) bool {
+	parts := strings.Split(mapping, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return false
+	}
+	for _, part := range parts[len(parts)-2:] { //may also be prefixed with a host ip
+		port, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || port <= 0 || port > 65535 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,348 @@
+package build
+
+import (
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func init() {
+	//keep the tests from fetching the image table
+	_imageTable = map[string]map[string]map[string]map[string]string{}
+}
+
+// writeSpec writes a spec to a temporary directory, with a template next to it
+func writeSpec(t *testing.T, spec string) string {
+	dir, err := ioutil.TempDir("", "spec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "genesis.json"), []byte("{}"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "spec.yaml")
+	err = ioutil.WriteFile(path, []byte(spec), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func fieldNames(errs FieldErrors) []string {
+	out := []string{}
+	for _, fe := range errs {
+		out = append(out, fe.Field)
+	}
+	return out
+}
+
+func TestParseSpecStrict(t *testing.T) {
+	var tests = []struct {
+		spec   string
+		fields []string
+		err    bool
+	}{
+		{spec: "blockchain: geth\nnodes: 3\n"},
+		{spec: "blockchian: geth\nnodes: 3\n", fields: []string{"blockchian"}},
+		{spec: "blockchain: geth\nnodes: three\n", fields: []string{"nodes"}},
+		{spec: "blockchain: geth\ngroups:\n  - name: a\n    nodes: 2\n    color: red\n", fields: []string{"color"}},
+		{spec: "blockchain: [geth\n", err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := ParseSpec([]byte(tt.spec))
+			if len(tt.fields) == 0 && !tt.err {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			errs, ok := err.(FieldErrors)
+			if tt.err {
+				if ok {
+					t.Errorf("expected an error which is not about a field, got %v", err)
+				}
+				return
+			}
+			if !ok || !reflect.DeepEqual(fieldNames(errs), tt.fields) {
+				t.Errorf("expected errors for the fields %v, got %v", tt.fields, err)
+			}
+		})
+	}
+}
+
+func TestSpecFlagsOverride(t *testing.T) {
+	path := writeSpec(t, `
+blockchain: geth
+nodes: 3
+image: geth-spec
+resources:
+  - cpus: "2"
+    memory: 4GB
+env:
+  all:
+    FOO: spec
+  1:
+    BAR: spec
+templates:
+  all:
+    genesis.json: genesis.json
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	var tests = []struct {
+		flags        []string
+		images       []string
+		cpus         []string
+		memory       []string
+		environments []map[string]string
+	}{
+		{
+			images: []string{"geth-spec", "geth-spec", "geth-spec"},
+			cpus:   []string{"2", "2", "2"},
+			memory: []string{"4GB", "4GB", "4GB"},
+			environments: []map[string]string{{"FOO": "spec"}, {"FOO": "spec", "BAR": "spec"},
+				{"FOO": "spec"}},
+		},
+		{
+			flags:  []string{"--image", "1=geth-flag", "--cpus", "4", "--memory", "2=1GB", "--env", "FOO=flag,2BAZ=flag"},
+			images: []string{"geth-spec", "geth-flag", "geth-spec"},
+			cpus:   []string{"4", "4", "4"},
+			memory: []string{"4GB", "4GB", "1GB"},
+			environments: []map[string]string{{"FOO": "flag"}, {"FOO": "flag", "BAR": "spec"},
+				{"FOO": "flag", "BAZ": "flag"}},
+		},
+		{
+			flags:  []string{"--image", "geth-flag"},
+			images: []string{"geth-flag", "geth-flag", "geth-flag"},
+			cpus:   []string{"2", "2", "2"},
+			memory: []string{"4GB", "4GB", "4GB"},
+			environments: []map[string]string{{"FOO": "spec"}, {"FOO": "spec", "BAR": "spec"},
+				{"FOO": "spec"}},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			cmd := &cobra.Command{}
+			AddBuildFlagsToCommand(cmd, false)
+			if err := cmd.ParseFlags(tt.flags); err != nil {
+				t.Fatal(err)
+			}
+			spec, err := LoadSpec(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bconf := spec.Config
+			if err := spec.Apply(&bconf); err != nil {
+				t.Fatal(err)
+			}
+			if err := HandleImageFlag(cmd, nil, &bconf); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := HandleResources(cmd, nil, &bconf); err != nil {
+				t.Fatal(err)
+			}
+			if err := HandleEnv(cmd, nil, &bconf); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(bconf.Images, tt.images) {
+				t.Errorf("unexpected images: %v", bconf.Images)
+			}
+			for j, res := range bconf.Resources {
+				if res.Cpus != tt.cpus[j] || res.Memory != tt.memory[j] {
+					t.Errorf("unexpected resources for node %d: %+v", j, res)
+				}
+			}
+			if !reflect.DeepEqual(bconf.Environments, tt.environments) {
+				t.Errorf("unexpected environments: %v", bconf.Environments)
+			}
+			files := bconf.Extras["defaults"].(map[string]interface{})["files"].(map[string]string)
+			if files["genesis.json"] != "e30=" {
+				t.Errorf("expected the template of the spec to be a default file, got %v", files)
+			}
+			if errs := CheckConfig(bconf); len(errs) > 0 {
+				t.Errorf("unexpected problems with the config: %v", errs)
+			}
+		})
+	}
+}
+
+func TestSpecApplyErrors(t *testing.T) {
+	var tests = []struct {
+		spec   string
+		fields []string
+	}{
+		{spec: "blockchain: geth\nnodes: 2\nenv:\n  5:\n    A: b\n", fields: []string{"env.5"}},
+		{spec: "blockchain: geth\nnodes: 2\nenv:\n  first:\n    A: b\n", fields: []string{"env.first"}},
+		{spec: "blockchain: geth\nnodes: 2\nports:\n  all: [\"8545:8545\"]\n", fields: []string{"ports.all"}},
+		{spec: "blockchain: geth\nnodes: 2\ntemplates:\n  0:\n    a.json: missing.json\n", fields: []string{"templates.0.a.json"}},
+		{spec: "blockchain: geth\nnodes: 1\nimages: [a, b]\n", fields: []string{"images"}},
+		{spec: "blockchain: geth\n", fields: []string{"nodes"}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			path := writeSpec(t, tt.spec)
+			defer os.RemoveAll(filepath.Dir(path))
+			spec, err := LoadSpec(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bconf := spec.Config
+			err = spec.Apply(&bconf)
+			errs, ok := err.(FieldErrors)
+			if !ok || !reflect.DeepEqual(fieldNames(errs), tt.fields) {
+				t.Errorf("expected errors for the fields %v, got %v", tt.fields, err)
+			}
+		})
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	valid := func() Config {
+		return Config{Blockchain: "geth", Nodes: 2, Images: []string{"a", "b"},
+			Resources: []Resources{{Cpus: "1", Ports: []string{"8545:8545"}}}}
+	}
+	var tests = []struct {
+		change func(bconf *Config)
+		fields []string
+	}{
+		{change: func(bconf *Config) {}, fields: []string{}},
+		{change: func(bconf *Config) { bconf.Blockchain = "" }, fields: []string{"blockchain"}},
+		{change: func(bconf *Config) { bconf.Nodes = 0 }, fields: []string{"nodes"}},
+		{change: func(bconf *Config) { bconf.Images = []string{"a"} }, fields: []string{"images"}},
+		{change: func(bconf *Config) { bconf.Images[1] = "" }, fields: []string{"images[1]"}},
+		{change: func(bconf *Config) { bconf.Resources[0].Cpus = "many" }, fields: []string{"resources[0].cpus"}},
+		{change: func(bconf *Config) { bconf.Resources[0].Ports = []string{"8545"} }, fields: []string{"resources[0].ports[0]"}},
+		{change: func(bconf *Config) { bconf.Resources[0].Ports = []string{"127.0.0.1:80:70000"} },
+			fields: []string{"resources[0].ports[0]"}},
+		{change: func(bconf *Config) { bconf.Environments = make([]map[string]string, 3) }, fields: []string{"environments"}},
+		{change: func(bconf *Config) { bconf.Files = make([]map[string]string, 3) }, fields: []string{"files"}},
+		{change: func(bconf *Config) { bconf.Labels = []string{"a", "b", "c"} }, fields: []string{"labels"}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			bconf := valid()
+			tt.change(&bconf)
+			errs := CheckConfig(bconf)
+			if !reflect.DeepEqual(fieldNames(errs), tt.fields) {
+				t.Errorf("expected errors for the fields %v, got %v", tt.fields, errs)
+			}
+		})
+	}
+}
+
+func TestSpecGroups(t *testing.T) {
+	path := writeSpec(t, `
+blockchain: geth
+image: geth-default
+resources:
+  - cpus: "1"
+    memory: 2GB
+groups:
+  - name: validators
+    nodes: 2
+    image: geth-validator
+    cpus: "4"
+    env:
+      ROLE: validator
+    templates:
+      genesis.json: genesis.json
+  - name: observers
+    nodes: 1
+    memory: 8GB
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	spec, err := LoadSpec(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Nodes != 3 {
+		t.Fatalf("expected the nodes of the groups to be added up, got %d", spec.Nodes)
+	}
+	bconf := spec.Config
+	if err := spec.Apply(&bconf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(bconf.Images, []string{"geth-validator", "geth-validator", "geth-default"}) {
+		t.Errorf("unexpected images: %v", bconf.Images)
+	}
+	cpus := []string{"4", "4", "1"}
+	memory := []string{"2GB", "2GB", "8GB"}
+	if len(bconf.Resources) != 3 {
+		t.Fatalf("expected resources for each node, got %v", bconf.Resources)
+	}
+	for i, res := range bconf.Resources {
+		if res.Cpus != cpus[i] || res.Memory != memory[i] {
+			t.Errorf("unexpected resources for node %d: %+v", i, res)
+		}
+	}
+	expectedEnv := []map[string]string{{"ROLE": "validator"}, {"ROLE": "validator"}, {}}
+	if !reflect.DeepEqual(bconf.Environments, expectedEnv) {
+		t.Errorf("unexpected environments: %v", bconf.Environments)
+	}
+	expectedFiles := []map[string]string{{"genesis.json": "e30="}, {"genesis.json": "e30="}, {}}
+	if !reflect.DeepEqual(bconf.Files, expectedFiles) {
+		t.Errorf("unexpected files: %v", bconf.Files)
+	}
+	if !reflect.DeepEqual(bconf.Labels, []string{"validators", "validators", "observers"}) {
+		t.Errorf("unexpected labels: %v", bconf.Labels)
+	}
+
+	bconf.Environments[0]["ROLE"] = "changed"
+	bconf.Files[0]["genesis.json"] = "changed"
+	if bconf.Environments[1]["ROLE"] != "validator" || bconf.Files[1]["genesis.json"] != "e30=" {
+		t.Error("expected each node of a group to have its own environment and files")
+	}
+}
+
+func TestSpecGroupErrors(t *testing.T) {
+	var tests = []struct {
+		spec   string
+		fields []string
+	}{
+		{spec: "blockchain: geth\nnodes: 4\ngroups:\n  - {name: a, nodes: 2}\n  - {name: b, nodes: 1}\n",
+			fields: []string{"groups"}},
+		{spec: "blockchain: geth\nnodes: 2\ngroups:\n  - {name: a, nodes: 3}\n", fields: []string{"groups"}},
+		{spec: "blockchain: geth\nnodes: 1\ngroups:\n  - {nodes: 0}\n", fields: []string{"groups[0].name", "groups[0].nodes"}},
+		{spec: "blockchain: geth\ngroups:\n  - {name: a, nodes: 1}\n  - {name: a, nodes: 1}\n", fields: []string{"groups[1].name"}},
+		{spec: "blockchain: geth\ngroups:\n  - {name: a b, nodes: 1}\n", fields: []string{"groups[0].name"}},
+		{spec: "blockchain: geth\ngroups:\n  - {name: a, nodes: 1, cpus: many}\n", fields: []string{"groups[0].cpus"}},
+		{spec: "blockchain: geth\nimages: [a]\nlabels: [a]\ngroups:\n  - {name: a, nodes: 1}\n",
+			fields: []string{"images", "labels"}},
+		{spec: "blockchain: geth\nresources: [{cpus: \"1\"}, {cpus: \"2\"}]\ngroups:\n  - {name: a, nodes: 2}\n",
+			fields: []string{"resources"}},
+		{spec: "blockchain: geth\ngroups:\n  - {name: a, nodes: 1, templates: {a.json: missing.json}}\n",
+			fields: []string{"groups[0].templates.a.json"}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			path := writeSpec(t, tt.spec)
+			defer os.RemoveAll(filepath.Dir(path))
+			spec, err := LoadSpec(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bconf := spec.Config
+			err = spec.Apply(&bconf)
+			errs, ok := err.(FieldErrors)
+			if !ok || !reflect.DeepEqual(fieldNames(errs), tt.fields) {
+				t.Errorf("expected errors for the fields %v, got %v", tt.fields, err)
+			}
+		})
+	}
+}
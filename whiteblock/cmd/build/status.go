@@ -1,31 +1,181 @@
 package build
 
 import (
+	"encoding/json"
 	"fmt"
+	"github.com/whiteblock/cli/whiteblock/client"
 	"github.com/whiteblock/cli/whiteblock/util"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
 )
 
-type Status struct {
-	Error    map[string]interface{} `json:"error"`
-	Progress float64                `json:"progress"`
-	Stage    string                 `json:"stage"`
-	Frozen   bool                   `json:"frozen"`
-}
-
-func (status Status) Print() bool {
-	if status.Frozen {
-		fmt.Printf("\nBuild is currently frozen. Press Ctrl-\\ to drop into console. Run 'whiteblock build unfreeze' to resume. \r")
-	} else if status.Error != nil {
-		fmt.Println() //move to the next line
-		util.PrintErrorFatal(status.Error["what"])
-	} else if status.Progress == 0.0 {
-		fmt.Printf("Sending build context to Whiteblock\r")
-	} else if status.Progress == 100.0 {
-		fmt.Printf("\033[1m\033[K\033[31m%s\033[0m\t%f%% completed\r", "Build", status.Progress)
-		util.Print("\a")
-		return true
+// Status is the progress of a build, as given by build_status
+type Status = client.Status
+
+// StageTiming is the time a build spent in one of its stages
+type StageTiming struct {
+	Stage    string        `json:"stage"`
+	Started  time.Time     `json:"started"`
+	Duration util.Duration `json:"duration"`
+}
+
+// Timeline keeps the stages of a build and how long each took, as its status changes
+type Timeline struct {
+	Started time.Time `json:"started"`
+	// Finished is when the build finished, it is zero until then
+	Finished time.Time     `json:"finished"`
+	Stages   []StageTiming `json:"stages"`
+}
+
+// Update moves the timeline on to the stage of the status at the given time. It reports
+// whether the stage changed, with the timing of the stage which just finished, if any.
+func (tl *Timeline) Update(status Status, at time.Time) (*StageTiming, bool) {
+	if tl.Started.IsZero() {
+		tl.Started = at
+	}
+	stage := status.StageName()
+	var finished *StageTiming
+	if len(tl.Stages) > 0 {
+		current := &tl.Stages[len(tl.Stages)-1]
+		if current.Stage == stage {
+			return nil, false
+		}
+		current.Duration = util.Duration(at.Sub(current.Started))
+		finished = current
+	}
+	if status.Done() {
+		tl.Finished = at
 	} else {
-		fmt.Printf("\033[1m\033[K\033[31m%s\033[0m\t%f%% completed\r", status.Stage, status.Progress)
+		tl.Stages = append(tl.Stages, StageTiming{Stage: stage, Started: at})
+	}
+	return finished, true
+}
+
+// Elapsed gets the time from the start of the build until at, or until it finished
+func (tl Timeline) Elapsed(at time.Time) time.Duration {
+	if !tl.Finished.IsZero() {
+		return tl.Finished.Sub(tl.Started)
+	}
+	return at.Sub(tl.Started)
+}
+
+// Slowest gets up to n of the stages which took the longest, slowest first
+func (tl Timeline) Slowest(n int) []StageTiming {
+	out := append([]StageTiming{}, tl.Stages...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Duration > out[j].Duration
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// Event is a change in the progress of a build, as printed with --output json
+type Event struct {
+	// Event is one of stage, error or done
+	Event    string        `json:"event"`
+	Stage    string        `json:"stage,omitempty"`
+	Progress float64       `json:"progress"`
+	Time     time.Time     `json:"time"`
+	Elapsed  util.Duration `json:"elapsed"`
+	// Finished is the stage which ended with this event
+	Finished *StageTiming  `json:"finished,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Slowest  []StageTiming `json:"slowest,omitempty"`
+}
+
+// Printer prints the progress of a build. On a terminal, the current stage is redrawn in place
+// with a line left behind for each finished stage. Otherwise a line is printed per stage, or
+// a json event per stage with --output json.
+type Printer struct {
+	Timeline Timeline
+	out      io.Writer
+	redraw   bool
+	events   bool
+	now      func() time.Time
+}
+
+// NewPrinter creates a Printer for the current output format
+func NewPrinter() *Printer {
+	return &Printer{
+		out:    os.Stdout,
+		redraw: util.IsTTY() && util.OutputFormat() == util.OutputDefault,
+		events: util.OutputFormat() == util.OutputJSON,
+		now:    time.Now,
+	}
+}
+
+func (p *Printer) emit(event Event) {
+	data, err := json.Marshal(event)
+	if err == nil {
+		fmt.Fprintln(p.out, string(data))
+	}
+}
+
+// Update prints the status if its stage has changed, reporting whether the build has finished.
+// The error of a failed build is given back for the caller to report.
+func (p *Printer) Update(status Status) (bool, error) {
+	at := p.now()
+	if err := status.Err(); err != nil {
+		if p.events {
+			p.emit(Event{Event: "error", Stage: status.StageName(), Progress: status.Progress, Time: at,
+				Elapsed: util.Duration(p.Timeline.Elapsed(at)), Error: err.Error()})
+		} else if p.redraw {
+			fmt.Fprintln(p.out)
+		}
+		return false, err
+	}
+	finished, changed := p.Timeline.Update(status, at)
+	elapsed := p.Timeline.Elapsed(at).Round(time.Second)
+	switch {
+	case p.events:
+		if !changed {
+			break
+		}
+		event := Event{Event: "stage", Stage: status.StageName(), Progress: status.Progress, Time: at,
+			Elapsed: util.Duration(elapsed), Finished: finished}
+		if status.Done() {
+			event.Event = "done"
+			event.Stage = ""
+			event.Slowest = p.Timeline.Slowest(3)
+		}
+		p.emit(event)
+	case util.IsStructuredOutput():
+	case p.redraw:
+		if finished != nil {
+			fmt.Fprintf(p.out, "\r\033[K%s\t%v\n", finished.Stage, time.Duration(finished.Duration).Round(time.Second))
+		}
+		if status.Frozen {
+			fmt.Fprintf(p.out, "Build is currently frozen. Press Ctrl-\\ to drop into console. Run 'whiteblock build unfreeze' to resume. \r")
+		} else if !status.Done() {
+			fmt.Fprintf(p.out, "\033[1m\033[K\033[31m%s\033[0m\t%.1f%% completed\r", status.StageName(), status.Progress)
+		}
+	default:
+		if changed && !status.Done() {
+			fmt.Fprintf(p.out, "[%v] %s\t%.1f%% completed\n", elapsed, status.StageName(), status.Progress)
+		}
+	}
+	if changed && status.Done() && p.redraw {
+		fmt.Fprint(p.out, "\a")
+	}
+	return status.Done(), nil
+}
+
+// PrintSummary prints the total time taken by the build and its slowest stages
+func (p *Printer) PrintSummary() {
+	if p.events || util.IsStructuredOutput() {
+		return
+	}
+	slowest := []string{}
+	for _, stage := range p.Timeline.Slowest(3) {
+		slowest = append(slowest, fmt.Sprintf("%s (%v)", stage.Stage, time.Duration(stage.Duration).Round(time.Second)))
+	}
+	fmt.Fprintf(p.out, "Build completed in %v\n", p.Timeline.Elapsed(p.now()).Round(time.Second))
+	if len(slowest) > 0 {
+		fmt.Fprintf(p.out, "Slowest stages: %s\n", strings.Join(slowest, ", "))
 	}
-	return false
 }
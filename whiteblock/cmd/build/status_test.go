@@ -0,0 +1,130 @@
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// statusAt is a status of a build, given the number of seconds after the build started
+type statusAt struct {
+	status  Status
+	seconds int
+}
+
+var testStatuses = []statusAt{
+	{status: Status{}, seconds: 0},
+	{status: Status{Progress: 20, Stage: "Provisioning"}, seconds: 10},
+	{status: Status{Progress: 30, Stage: "Provisioning"}, seconds: 20},
+	{status: Status{Progress: 100}, seconds: 70},
+}
+
+// runPrinter feeds the statuses to a printer with a fake clock, giving what it printed
+func runPrinter(t *testing.T, p *Printer, statuses []statusAt) string {
+	buf := new(bytes.Buffer)
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	var at time.Time
+	p.out = buf
+	p.now = func() time.Time { return at }
+	for _, s := range statuses {
+		at = start.Add(time.Duration(s.seconds) * time.Second)
+		done, err := p.Update(s.status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done != s.status.Done() {
+			t.Errorf("expected done to be %v at %ds", s.status.Done(), s.seconds)
+		}
+	}
+	p.PrintSummary()
+	return buf.String()
+}
+
+func TestTimelineUpdate(t *testing.T) {
+	var tl Timeline
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, s := range testStatuses {
+		finished, changed := tl.Update(s.status, start.Add(time.Duration(s.seconds)*time.Second))
+		if changed != (i != 2) {
+			t.Errorf("status %d: expected changed to be %v", i, i != 2)
+		}
+		if (finished != nil) != (i == 1 || i == 3) {
+			t.Errorf("status %d: unexpected finished stage %+v", i, finished)
+		}
+	}
+	if len(tl.Stages) != 2 || tl.Stages[1].Stage != "Provisioning" {
+		t.Fatalf("unexpected stages %+v", tl.Stages)
+	}
+	if time.Duration(tl.Stages[1].Duration) != time.Minute {
+		t.Errorf("expected Provisioning to take a minute, got %v", tl.Stages[1].Duration)
+	}
+	if tl.Elapsed(start.Add(time.Hour)) != 70*time.Second {
+		t.Errorf("expected the elapsed time to stop when the build finished, got %v", tl.Elapsed(start.Add(time.Hour)))
+	}
+	slowest := tl.Slowest(1)
+	if len(slowest) != 1 || slowest[0].Stage != "Provisioning" {
+		t.Errorf("unexpected slowest stages %+v", slowest)
+	}
+}
+
+func TestPrinterLines(t *testing.T) {
+	out := runPrinter(t, &Printer{}, testStatuses)
+	expected := "[0s] Sending build context to Whiteblock\t0.0% completed\n" +
+		"[10s] Provisioning\t20.0% completed\n" +
+		"Build completed in 1m10s\n" +
+		"Slowest stages: Provisioning (1m0s), Sending build context to Whiteblock (10s)\n"
+	if out != expected {
+		t.Errorf("expected\n%q\ngot\n%q", expected, out)
+	}
+}
+
+func TestPrinterRedraw(t *testing.T) {
+	statuses := append([]statusAt{{status: Status{Progress: 20, Stage: "Provisioning", Frozen: true}, seconds: 5}},
+		testStatuses[1:]...)
+	out := runPrinter(t, &Printer{redraw: true}, statuses)
+	for _, part := range []string{
+		"Build is currently frozen.",
+		"\r\033[KFrozen\t5s\n",
+		"\033[31mProvisioning\033[0m\t20.0% completed\r",
+		"\033[31mProvisioning\033[0m\t30.0% completed\r",
+		"\r\033[KProvisioning\t1m0s\n\a",
+		"Build completed in 1m5s\n",
+	} {
+		if !strings.Contains(out, part) {
+			t.Errorf("expected the output to contain %q, got %q", part, out)
+		}
+	}
+}
+
+func TestPrinterEvents(t *testing.T) {
+	out := runPrinter(t, &Printer{events: true}, testStatuses)
+	events := []Event{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("expected a json event per line, got %q", line)
+		}
+		events = append(events, event)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected an event for each change of stage, got %q", out)
+	}
+	if events[1].Event != "stage" || events[1].Stage != "Provisioning" || events[1].Finished == nil ||
+		events[1].Finished.Stage != "Sending build context to Whiteblock" {
+		t.Errorf("unexpected stage event %+v", events[1])
+	}
+	if events[2].Event != "done" || time.Duration(events[2].Elapsed) != 70*time.Second || len(events[2].Slowest) != 2 {
+		t.Errorf("unexpected done event %+v", events[2])
+	}
+
+	p := &Printer{events: true, out: new(bytes.Buffer), now: time.Now}
+	_, err := p.Update(Status{Progress: 40, Error: map[string]interface{}{"what": "out of memory"}})
+	if err == nil || err.Error() != "out of memory" {
+		t.Errorf("expected the error of the build, got %v", err)
+	}
+	if !strings.Contains(p.out.(*bytes.Buffer).String(), `"event":"error"`) {
+		t.Errorf("expected an error event, got %q", p.out.(*bytes.Buffer).String())
+	}
+}
@@ -0,0 +1,287 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Validate checks a fully assembled build against the parameter schema of its blockchain,
+// as given by get_params. An empty schema skips the parameter checks.
+func Validate(bconf Config, schema [][]string) FieldErrors {
+	errs := CheckConfig(bconf)
+	if len(schema) > 0 {
+		errs = append(errs, CheckParams(bconf.Params, schema)...)
+	}
+	errs = append(errs, CheckPorts(bconf)...)
+	return errs
+}
+
+func paramsCacheKey(blockchain string) string {
+	return "params_schema_" + blockchain
+}
+
+// CacheParams stores the params schema of the blockchain, to check builds against when the
+// server cannot be reached
+func CacheParams(blockchain string, schema [][]string) error {
+	return util.Set(paramsCacheKey(blockchain), schema)
+}
+
+// CachedParams gets the params schema stored by the last call to CacheParams
+func CachedParams(blockchain string) ([][]string, error) {
+	var out [][]string
+	err := util.GetP(paramsCacheKey(blockchain), &out)
+	if err != nil {
+		return nil, fmt.Errorf("no cached params schema for %s", blockchain)
+	}
+	return out, nil
+}
+
+// CheckParams type checks the given params against the name/type pairs from get_params
+func CheckParams(params map[string]interface{}, schema [][]string) FieldErrors {
+	errs := FieldErrors{}
+	types := map[string]string{}
+	for _, kv := range schema {
+		if len(kv) < 2 {
+			continue
+		}
+		types[kv[0]] = kv[1]
+	}
+	names := []string{}
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := "params." + name
+		keyType, ok := types[name]
+		if !ok {
+			errs.add(field, "unknown parameter")
+			continue
+		}
+		if !paramHasType(params[name], keyType) {
+			errs.add(field, "expected %s, got %v", keyType, params[name])
+		}
+	}
+	return errs
+}
+
+func paramHasType(val interface{}, keyType string) bool {
+	switch keyType {
+	case "string":
+		_, ok := val.(string)
+		return ok
+	case "[]string":
+		switch arr := val.(type) {
+		case []string:
+			return true
+		case []interface{}:
+			for _, elem := range arr {
+				if _, ok := elem.(string); !ok {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+	case "int":
+		switch num := val.(type) {
+		case int, int64:
+			return true
+		case float64:
+			return num == float64(int64(num))
+		case json.Number:
+			_, err := num.Int64()
+			return err == nil
+		}
+		return false
+	case "bool":
+		_, ok := val.(bool)
+		return ok
+	}
+	return true //unknown types are left to the server
+}
+
+func hostPort(mapping string) (int, bool) {
+	parts := strings.Split(mapping, ":")
+	if len(parts) < 2 {
+		return 0, false
+	}
+	port, err := strconv.Atoi(strings.TrimSpace(parts[len(parts)-2]))
+	return port, err == nil
+}
+
+// CheckPorts finds host ports which have been mapped more than once
+func CheckPorts(bconf Config) FieldErrors {
+	errs := FieldErrors{}
+	used := map[int]int{}
+	for i, res := range bconf.Resources {
+		for j, mapping := range res.Ports {
+			port, ok := hostPort(mapping)
+			if !ok {
+				continue
+			}
+			if node, dup := used[port]; dup {
+				errs.add(fmt.Sprintf("resources[%d].ports[%d]", i, j),
+					"host port %d is already mapped to node %d", port, node)
+				continue
+			}
+			used[port] = i
+		}
+	}
+	return errs
+}
+
+func checkNodeIndex(errs *FieldErrors, field string, index int, nodes int) {
+	if index < 0 || index >= nodes {
+		errs.add(field, "node %d is out of range, there are %d nodes", index, nodes)
+	}
+}
+
+func checkFileFlag(errs *FieldErrors, flag string, paths ...string) {
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			errs.add("--"+flag, "%v", err)
+		}
+	}
+}
+
+// CheckFlags checks the node indexes, file paths and values given to the build flags of cmd,
+// so that the problems can be reported at once instead of the build exiting part way through.
+// An empty schema skips the checks on --option.
+func CheckFlags(cmd *cobra.Command, nodes int, schema [][]string) FieldErrors {
+	errs := FieldErrors{}
+	flags := cmd.Flags()
+
+	if flags.Changed("env") {
+		envVars, err := flags.GetStringToString("env")
+		if err != nil {
+			errs.add("--env", "%v", err)
+		}
+		for k := range envVars {
+			node, _, err := parseEnvKey(k)
+			if err != nil {
+				errs.add("--env "+k, "%v", err)
+				continue
+			}
+			if node != -1 {
+				checkNodeIndex(&errs, "--env "+k, node, nodes)
+			}
+		}
+	}
+
+	if flags.Changed("template") {
+		templates, _ := flags.GetStringSlice("template")
+		for _, tfileIn := range templates {
+			tuple := strings.SplitN(tfileIn, ";", 3)
+			if len(tuple) < 3 {
+				tuple = strings.SplitN(strings.Replace(tfileIn, ";", "=", 1), "=", 2)
+				if len(tuple) != 2 {
+					errs.add("--template "+tfileIn, "expected [node;]<name>;<file>")
+					continue
+				}
+			}
+			for i := range tuple {
+				tuple[i] = strings.Trim(tuple[i], " \n\r\t")
+			}
+			if len(tuple) == 3 {
+				index, err := strconv.Atoi(tuple[0])
+				if err != nil {
+					errs.add("--template "+tfileIn, "invalid node number %q", tuple[0])
+				} else {
+					checkNodeIndex(&errs, "--template "+tfileIn, index, nodes)
+				}
+			}
+			checkFileFlag(&errs, "template", tuple[len(tuple)-1])
+		}
+	}
+
+	if flags.Changed("expose-port-mapping") {
+		mappings, _ := flags.GetStringSlice("expose-port-mapping")
+		for _, mapping := range mappings {
+			pair := strings.SplitN(mapping, "=", 2)
+			if len(pair) != 2 {
+				errs.add("--expose-port-mapping "+mapping, "expected <node>=<host port>:<node port>")
+				continue
+			}
+			index, err := strconv.Atoi(pair[0])
+			if err != nil {
+				errs.add("--expose-port-mapping "+mapping, "invalid node number %q", pair[0])
+				continue
+			}
+			checkNodeIndex(&errs, "--expose-port-mapping "+mapping, index, nodes)
+		}
+	}
+
+	for _, flag := range []string{"cpus", "memory", "image"} {
+		if !flags.Changed(flag) {
+			continue
+		}
+		vals, _ := flags.GetStringSlice(flag)
+		explicit, defaults, err := util.UnrollStringSliceToMapIntString(vals, "=")
+		if err != nil {
+			errs.add("--"+flag, "%v", err)
+			continue
+		}
+		if len(defaults) > 1 {
+			errs.add("--"+flag, "only one default may be given, got %d", len(defaults))
+		}
+		indexes := []int{}
+		for index := range explicit {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		for _, index := range indexes {
+			checkNodeIndex(&errs, fmt.Sprintf("--%s %d=%s", flag, index, explicit[index]), index, nodes)
+		}
+	}
+
+	if flags.Changed("docker-password") != flags.Changed("docker-username") {
+		errs.add("--docker-username", "--docker-username and --docker-password must be given together")
+	}
+	if flags.Changed("user-ssh-key") {
+		keys, _ := flags.GetStringSlice("user-ssh-key")
+		checkFileFlag(&errs, "user-ssh-key", keys...)
+	}
+	if flags.Changed("dockerfile") {
+		dockerfile, _ := flags.GetString("dockerfile")
+		checkFileFlag(&errs, "dockerfile", dockerfile)
+	}
+
+	if flags.Changed("option") && len(schema) > 0 {
+		options, _ := flags.GetStringToString("option")
+		types := map[string]string{}
+		for _, kv := range schema {
+			if len(kv) >= 2 {
+				types[kv[0]] = kv[1]
+			}
+		}
+		for name, val := range options {
+			keyType, ok := types[name]
+			if !ok {
+				errs.add("--option "+name, "unknown parameter")
+				continue
+			}
+			switch keyType {
+			case "int":
+				if _, err := strconv.ParseInt(val, 0, 64); err != nil {
+					errs.add("--option "+name, "expected int, got %q", val)
+				}
+			case "bool":
+				switch val {
+				case "true", "yes", "false", "no":
+				default:
+					errs.add("--option "+name, "expected bool, got %q", val)
+				}
+			}
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
@@ -0,0 +1,118 @@
+package build
+
+import (
+	"encoding/json"
+	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestCheckParams(t *testing.T) {
+	schema := [][]string{{"chainId", "int"}, {"network", "string"}, {"peers", "[]string"},
+		{"mine", "bool"}, {"broken"}}
+	var tests = []struct {
+		params map[string]interface{}
+		fields []string
+	}{
+		{
+			params: map[string]interface{}{"chainId": json.Number("15"), "network": "dev",
+				"peers": []interface{}{"a", "b"}, "mine": true},
+			fields: []string{},
+		},
+		{params: map[string]interface{}{"chainId": 1.5}, fields: []string{"params.chainId"}},
+		{params: map[string]interface{}{"chainId": float64(15), "mine": "yes"}, fields: []string{"params.mine"}},
+		{params: map[string]interface{}{"peers": []interface{}{"a", 2}}, fields: []string{"params.peers"}},
+		{params: map[string]interface{}{"network": 3, "gasLimit": 1}, fields: []string{"params.gasLimit", "params.network"}},
+		{params: map[string]interface{}{"broken": 1}, fields: []string{"params.broken"}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			errs := CheckParams(tt.params, schema)
+			if !reflect.DeepEqual(fieldNames(errs), tt.fields) {
+				t.Errorf("expected errors for the fields %v, got %v", tt.fields, errs)
+			}
+		})
+	}
+}
+
+func TestCheckPorts(t *testing.T) {
+	var tests = []struct {
+		resources []Resources
+		fields    []string
+	}{
+		{resources: []Resources{{Ports: []string{"8545:8545"}}, {Ports: []string{"8546:8545"}}}, fields: []string{}},
+		{resources: []Resources{{Ports: []string{"8545:8545"}}, {Ports: []string{"8545:8546"}}},
+			fields: []string{"resources[1].ports[0]"}},
+		{resources: []Resources{{Ports: []string{"127.0.0.1:80:80", "0.0.0.0:80:81"}}},
+			fields: []string{"resources[0].ports[1]"}},
+		{resources: []Resources{{Ports: []string{"8545", "8545"}}}, fields: []string{}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			errs := CheckPorts(Config{Resources: tt.resources})
+			if !reflect.DeepEqual(fieldNames(errs), tt.fields) {
+				t.Errorf("expected errors for the fields %v, got %v", tt.fields, errs)
+			}
+		})
+	}
+}
+
+func TestCheckFlags(t *testing.T) {
+	schema := [][]string{{"chainId", "int"}, {"mine", "bool"}}
+	var tests = []struct {
+		flags  []string
+		schema [][]string
+		fields []string
+	}{
+		{flags: []string{"--env", "FOO=a,1BAR=b", "--cpus", "2,1=4", "-o", "chainId=5,mine=yes"}, schema: schema,
+			fields: []string{}},
+		{flags: []string{"--env", "3FOO=a"}, fields: []string{"--env 3FOO"}},
+		{flags: []string{"--cpus", "1,2", "--image", "5=geth"}, fields: []string{"--cpus", "--image 5=geth"}},
+		{flags: []string{"-p", "2=8545:8545", "-p", "x=1:1", "-p", "8545"},
+			fields: []string{"--expose-port-mapping 2=8545:8545", "--expose-port-mapping 8545",
+				"--expose-port-mapping x=1:1"}},
+		{flags: []string{"--docker-username", "me"}, fields: []string{"--docker-username"}},
+		{flags: []string{"-t", "0;genesis.json;/missing/genesis.json"}, fields: []string{"--template"}},
+		{flags: []string{"-o", "chainId=five,mine=maybe,extra=1"}, schema: schema,
+			fields: []string{"--option chainId", "--option extra", "--option mine"}},
+		{flags: []string{"-o", "extra=1"}, fields: []string{}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			cmd := &cobra.Command{}
+			AddBuildFlagsToCommand(cmd, false)
+			if err := cmd.ParseFlags(tt.flags); err != nil {
+				t.Fatal(err)
+			}
+			errs := CheckFlags(cmd, 2, tt.schema)
+			if !reflect.DeepEqual(fieldNames(errs), tt.fields) {
+				t.Errorf("expected errors for the fields %v, got %v", tt.fields, errs)
+			}
+		})
+	}
+}
+
+func TestCachedParams(t *testing.T) {
+	blockchain := "test-cached-params"
+	defer util.Delete(paramsCacheKey(blockchain))
+
+	if _, err := CachedParams(blockchain); err == nil {
+		t.Fatal("expected an error when nothing is cached")
+	}
+	schema := [][]string{{"chainId", "int"}, {"network", "string"}}
+	if err := CacheParams(blockchain, schema); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := CachedParams(blockchain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cached, schema) {
+		t.Errorf("expected the cached schema to be %v, got %v", schema, cached)
+	}
+}
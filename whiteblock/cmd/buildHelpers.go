@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/whiteblock/cli/whiteblock/cmd/build"
 	"github.com/whiteblock/cli/whiteblock/util"
 )
 
@@ -39,6 +41,10 @@ func fetchParams(blockchain string) ([][]string, error) {
 			}
 		}
 	}
+	err = build.CacheParams(blockchain, out)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Warn("failed to cache the params schema")
+	}
 	return out, nil
 }
 
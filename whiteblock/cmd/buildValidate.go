@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"os"
+	"strings"
+)
+
+// getParamsSchema gets the params schema from the server, falling back on the cache
+func getParamsSchema(blockchain string, offline bool) ([][]string, error) {
+	if blockchain == "generic" {
+		return [][]string{}, nil
+	}
+	if !offline {
+		schema, err := fetchParams(blockchain)
+		if err == nil {
+			return schema, nil
+		}
+		log.WithFields(log.Fields{"error": err}).Debug("unable to fetch the params, using the cache")
+	}
+	return build.CachedParams(blockchain)
+}
+
+// flagErrors gives the error of the flag as the problems of a build
+func flagErrors(flag string, err error) build.FieldErrors {
+	return build.FieldErrors{build.FieldError{Field: "--" + flag, Msg: err.Error()}}
+}
+
+// assembleBuild puts together the build the same way Build does, but without prompting,
+// stopping at the first stage which has problems. The problems of the last stage are all
+// collected, along with those found by validating the build.
+func assembleBuild(cmd *cobra.Command, offline bool) (build.Config, build.FieldErrors) {
+	errs := build.FieldErrors{}
+	var buildConf build.Config
+	var spec *build.Spec
+
+	specFile, err := util.GetStringFlagValue(cmd, "spec")
+	if err != nil {
+		return buildConf, flagErrors("spec", err)
+	}
+	if len(specFile) > 0 {
+		spec, err = build.LoadSpec(specFile)
+		if fieldErrs, ok := err.(build.FieldErrors); ok {
+			return buildConf, fieldErrs
+		} else if err != nil {
+			return buildConf, build.FieldErrors{build.FieldError{Field: "--spec", Msg: err.Error()}}
+		}
+		buildConf = spec.Config
+	} else {
+		buildConf.Resources = []build.Resources{build.Resources{}}
+		buildConf.Params = map[string]interface{}{}
+		buildConf.Extras = map[string]interface{}{}
+		buildConf.Meta = map[string]interface{}{}
+	}
+
+	blockchain, err := util.GetStringFlagValue(cmd, "blockchain")
+	if err != nil {
+		return buildConf, flagErrors("blockchain", err)
+	}
+	if len(blockchain) > 0 {
+		buildConf.Blockchain = strings.ToLower(blockchain)
+	}
+	nodes, err := util.GetIntFlagValue(cmd, "nodes")
+	if err != nil {
+		return buildConf, flagErrors("nodes", err)
+	}
+	if nodes > 0 {
+		buildConf.Nodes = nodes
+	}
+	if len(buildConf.Blockchain) == 0 {
+		errs = append(errs, build.FieldError{Field: "blockchain", Msg: "is required, give it with --blockchain or in the spec"})
+	}
+	if buildConf.Nodes <= 0 {
+		errs = append(errs, build.FieldError{Field: "nodes", Msg: "is required, give it with --nodes or in the spec"})
+	}
+	if len(errs) > 0 {
+		return buildConf, errs
+	}
+
+	schema, err := getParamsSchema(buildConf.Blockchain, offline)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to check the params: %v\n", err)
+	}
+
+	errs = build.CheckFlags(cmd, buildConf.Nodes, schema)
+	if spec != nil {
+		if err := spec.Apply(&buildConf); err != nil {
+			errs = append(errs, err.(build.FieldErrors)...)
+		}
+	}
+	if err := build.HandleImageFlag(cmd, nil, &buildConf); err != nil {
+		errs = append(errs, build.FieldError{Field: "images", Msg: err.Error()})
+	}
+	if len(errs) > 0 {
+		return buildConf, errs
+	}
+
+	_, err = build.HandleOptions(cmd, nil, &buildConf, schema)
+	if err != nil {
+		return buildConf, flagErrors("option", err)
+	}
+	paramsFile, err := util.GetStringFlagValue(cmd, "file")
+	if err != nil {
+		return buildConf, flagErrors("file", err)
+	}
+	if len(paramsFile) > 0 {
+		f, err := os.Open(paramsFile)
+		if err != nil {
+			return buildConf, build.FieldErrors{build.FieldError{Field: "--file", Msg: err.Error()}}
+		}
+		defer f.Close()
+		decoder := json.NewDecoder(f)
+		decoder.UseNumber()
+		err = decoder.Decode(&buildConf.Params)
+		if err != nil {
+			return buildConf, build.FieldErrors{build.FieldError{Field: "--file", Msg: err.Error()}}
+		}
+	}
+	validators, err := util.GetIntFlagValue(cmd, "validators")
+	if err != nil {
+		return buildConf, flagErrors("validators", err)
+	}
+	if validators >= 0 {
+		buildConf.Params["validators"] = validators
+	}
+	if _, _, err := build.HandleResources(cmd, nil, &buildConf); err != nil {
+		errs = append(errs, build.FieldError{Field: "resources", Msg: err.Error()})
+	}
+	for _, handler := range []struct {
+		flag   string
+		handle func(*cobra.Command, []string, *build.Config) error
+	}{
+		{flag: "template", handle: build.HandleFilesFlag},
+		{flag: "env", handle: build.HandleEnv},
+		{flag: "expose-port-mapping", handle: build.HandlePortMapping},
+	} {
+		if err := handler.handle(cmd, nil, &buildConf); err != nil {
+			errs = append(errs, flagErrors(handler.flag, err)...)
+		}
+	}
+	if err := build.HandleExposeAllBuildFlag(cmd, nil, &buildConf, 0); err != nil {
+		errs = append(errs, flagErrors("expose-all", err)...)
+	}
+	build.SanitizeBuild(&buildConf)
+
+	return buildConf, append(errs, build.Validate(buildConf, schema)...)
+}
+
+var buildValidateCmd = &cobra.Command{
+	Use:     "validate",
+	Aliases: []string{"check", "lint"},
+	Short:   "Check a build for problems without building it",
+	Long: "\nValidate puts together the build from the given flags and spec file, without prompting, " +
+		"and reports every problem found with it. The blockchain params are checked against the schema " +
+		"from the server, or the last fetched schema when the server cannot be reached.\n",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
+		}
+		offline, err := util.GetBoolFlagValue(cmd, "offline")
+		if err != nil {
+			return err
+		}
+		buildConf, errs := assembleBuild(cmd, offline)
+		if len(errs) > 0 {
+			return errs
+		}
+		log.WithFields(log.Fields{"build": buildConf}).Debug("the build is valid")
+		util.Print(fmt.Sprintf("The build of %d %s nodes is valid", buildConf.Nodes, buildConf.Blockchain))
+		return nil
+	},
+}
+
+func init() {
+	build.AddBuildFlagsToCommand(buildValidateCmd, false)
+	buildValidateCmd.Flags().Bool("offline", false, "only use the cached params schema, do not contact the server")
+	buildCmd.AddCommand(buildValidateCmd)
+}
@@ -19,8 +19,8 @@ var showConfCmd = &cobra.Command{
 	Long: `
 	Show the default values set by the configuration file.
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.Print(*conf)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return util.Print(*conf)
 	},
 }
 
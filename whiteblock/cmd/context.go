@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"io/ioutil"
+	"strings"
+)
+
+var contextCmd = &cobra.Command{
+	Use:     "context <command>",
+	Aliases: []string{"contexts", "ctx"},
+	Short:   "Manage the servers the cli can be pointed at",
+	Long: `
+Context manages named servers, each with their own address, credentials and builds, so that
+switching between servers does not require logging in again. The context to use for a single
+command can be given with --context.
+`,
+	Run: util.PartialCommand,
+}
+
+var contextListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the contexts",
+	Long:    "\nList the contexts, along with the server and the previous build of each.\n",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
+		}
+		names, err := util.ContextNames()
+		if err != nil {
+			return err
+		}
+		out := []map[string]interface{}{}
+		for _, name := range names {
+			sctx, err := util.GetContext(name)
+			if err != nil {
+				return err
+			}
+			var biome, previousBuild, jwt string
+			util.GetContextValue(name, "biome", &biome)
+			util.GetContextValue(name, "previous_build_id", &previousBuild)
+			util.GetContextValue(name, "jwt", &jwt)
+			current := ""
+			if name == util.ActiveContext() {
+				current = "*"
+			}
+			out = append(out, map[string]interface{}{
+				"current":       current,
+				"name":          name,
+				"serverAddr":    sctx.ServerAddr,
+				"scheme":        sctx.Scheme,
+				"apiURL":        sctx.APIURL,
+				"biome":         biome,
+				"previousBuild": previousBuild,
+				"loggedIn":      len(jwt) > 0,
+			})
+		}
+		util.PrintTable(out, "current", "name", "serverAddr", "scheme", "apiURL", "biome", "previousBuild", "loggedIn")
+		return nil
+	},
+}
+
+var contextUseCmd = &cobra.Command{
+	Use:     "use <name>",
+	Aliases: []string{"switch", "select"},
+	Short:   "Switch to a context",
+	Long:    "\nSwitch to a context, the commands run afterwards will use its server. Use \"default\" to go back to the configured server.\n",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		err = util.SetCurrentContext(args[0])
+		if err != nil {
+			return err
+		}
+		util.Printf("Switched to context \"%s\"", args[0])
+		return nil
+	},
+}
+
+var contextAddCmd = &cobra.Command{
+	Use:     "add <name>",
+	Aliases: []string{"set", "create"},
+	Short:   "Add or update a context",
+	Long: `
+Add a context, or update the given settings of an existing one.
+
+If --server-addr is not given, the server is taken from the biome of the user logged in with the context.
+The jwt may be given as a file or as the token itself, like with login.
+
+Example:
+	whiteblock context add lab --server-addr 10.0.0.5:8080 --scheme http --use
+	whiteblock context add hosted --jwt ~/hosted.jwt --biome 3
+	whiteblock context add secure --server-addr genesis.lab:443 --scheme https --ca-cert lab-ca.pem \
+		--client-cert me.pem --client-key me-key.pem
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		flags := map[string]string{}
+		for _, flag := range []string{"server-addr", "scheme", "api-url", "jwt", "biome"} {
+			flags[flag], err = util.GetStringFlagValue(cmd, flag)
+			if err != nil {
+				return err
+			}
+		}
+		name := args[0]
+		sctx, err := util.GetContext(name)
+		if err != nil {
+			sctx = util.ServerContext{Name: name}
+		}
+		if cmd.Flags().Changed("server-addr") {
+			sctx.ServerAddr = flags["server-addr"]
+		}
+		if cmd.Flags().Changed("scheme") {
+			sctx.Scheme = strings.ToLower(flags["scheme"])
+			if sctx.Scheme != "http" && sctx.Scheme != "https" {
+				return util.NewValidationError(`invalid scheme "%s", expected http or https`, sctx.Scheme)
+			}
+		}
+		if cmd.Flags().Changed("api-url") {
+			sctx.APIURL = flags["api-url"]
+		}
+		err = util.SaveContext(sctx)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("jwt") {
+			jwt, err := ioutil.ReadFile(flags["jwt"])
+			if err != nil {
+				jwt = []byte(flags["jwt"])
+			}
+			err = util.SetContextValue(name, "jwt", strings.TrimSpace(string(jwt)))
+			if err != nil {
+				return err
+			}
+		}
+		if cmd.Flags().Changed("biome") {
+			err = util.SetContextValue(name, "biome", flags["biome"])
+			if err != nil {
+				return err
+			}
+		}
+		use, err := util.GetBoolFlagValue(cmd, "use")
+		if err != nil {
+			return err
+		}
+		if use {
+			err = util.SetCurrentContext(name)
+			if err != nil {
+				return err
+			}
+		}
+		return util.Print(fmt.Sprintf("Saved context \"%s\"", name))
+	},
+}
+
+var contextDeleteCmd = &cobra.Command{
+	Use:     "delete <name>",
+	Aliases: []string{"rm", "remove"},
+	Short:   "Delete a context",
+	Long:    "\nDelete a context, along with the credentials and builds stored for it.\n",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		err = util.DeleteContext(args[0])
+		if err != nil {
+			return err
+		}
+		util.Printf("Deleted context \"%s\"", args[0])
+		return nil
+	},
+}
+
+func init() {
+	contextAddCmd.Flags().String("server-addr", "", "the address of the server, with the port")
+	contextAddCmd.Flags().String("scheme", "", "the scheme of the server, http or https")
+	contextAddCmd.Flags().String("api-url", "", "the url of the whiteblock api")
+	contextAddCmd.Flags().String("ca-cert", "", "a pem bundle of the certificate authorities to trust for the server")
+	contextAddCmd.Flags().String("client-cert", "", "the pem client certificate to present to the server")
+	contextAddCmd.Flags().String("client-key", "", "the pem private key of the client certificate")
+	contextAddCmd.Flags().Bool("insecure-skip-verify", false, "do not verify the certificate of the server")
+	contextAddCmd.Flags().String("jwt", "", "the jwt to authenticate with, or a file containing it")
+	contextAddCmd.Flags().String("biome", "", "the biome to use")
+	contextAddCmd.Flags().Bool("use", false, "switch to the context once it is saved")
+
+	contextCmd.AddCommand(contextListCmd, contextUseCmd, contextAddCmd, contextDeleteCmd)
+	RootCmd.AddCommand(contextCmd)
+}
@@ -14,8 +14,16 @@ var doneCmd = &cobra.Command{
 	Tears down the nodes, and frees up any resources which they are using.
 `,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.JsonRpcCallAndPrint("delete_testnet", []interface{}{build.GetPreviousBuildID()})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		err = util.JsonRpcCallAndPrint("delete_testnet", []interface{}{testnetID})
+		if err != nil {
+			return err
+		}
+		return util.RemoveTestnet(testnetID)
 	},
 }
 
@@ -10,8 +10,12 @@ var execCmd = &cobra.Command{
 	Use:    "exec",
 	Short:  "Execute a function call",
 	Long:   "\nMainly for internal and debug purposes.\n",
-	Run: func(cmd *cobra.Command, args []string) {
-		util.JsonRpcCallAndPrint(args[0], util.ArgsToJSON(args[1:]))
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, util.NoMaxArgs)
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint(args[0], util.ArgsToJSON(args[1:]))
 	},
 }
 
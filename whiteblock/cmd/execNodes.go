@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
+	"github.com/whiteblock/cli/whiteblock/rpc"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// execResult is the outcome of running a command on a node
+type execResult struct {
+	Node     int           `json:"node"`
+	IP       string        `json:"ip"`
+	ExitCode int           `json:"exitCode"`
+	Stdout   string        `json:"stdout"`
+	Stderr   string        `json:"stderr"`
+	Duration util.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+func (res execResult) failed() bool {
+	return res.ExitCode != 0 || len(res.Error) > 0
+}
+
+// execOnNode runs a command on the node at ip over ssh
+func execOnNode(ctx context.Context, node int, ip string, command string) execResult {
+	start := time.Now()
+	out := execResult{Node: node, IP: ip, ExitCode: -1}
+	client, err := util.NewSshClient(ip)
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	defer client.Close()
+	out.Stdout, out.Stderr, out.ExitCode, err = client.RunContext(ctx, command)
+	out.Duration = util.Duration(time.Since(start))
+	if err == context.DeadlineExceeded {
+		out.Error = "timed out"
+	} else if err != nil {
+		out.Error = err.Error()
+	}
+	return out
+}
+
+// printPrefixed prints each line of text with the node it came from
+func printPrefixed(w io.Writer, node int, text string) {
+	text = strings.TrimRight(text, "\n")
+	if len(text) == 0 {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(w, "[node %d] %s\n", node, line)
+	}
+}
+
+var execNodesCmd = &cobra.Command{
+	Use:   "exec-nodes <nodes> -- <command>...",
+	Short: "Run a command on many nodes at once",
+	Long: `
+Runs a command on each of the nodes over ssh, in parallel, with at most --parallel running at once.
+Each line of output is prefixed with the node it came from as each node finishes, or with --group
+the output of each node is given together once all have finished. The command fails if it fails
+on any of the nodes.
+
+A single argument after -- is given to the shell as it is, so that it may be a pipeline.
+
+Example:
+	whiteblock exec-nodes all -- df -h /
+	whiteblock exec-nodes label=validator --timeout 10s -- 'grep -c peer /output.log'
+
+` + selector.Usage,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 2, util.NoMaxArgs)
+		if err != nil {
+			return err
+		}
+		if dash := cmd.ArgsLenAtDash(); dash != -1 && dash != 1 {
+			return util.NewValidationError("expected the nodes and then -- before the command")
+		}
+		parallel, err := cmd.Flags().GetInt("parallel")
+		if err != nil {
+			return err
+		}
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+		group, err := cmd.Flags().GetBool("group")
+		if err != nil {
+			return err
+		}
+		nodes, err := selectNodes(args[0])
+		if err != nil {
+			return err
+		}
+		command := util.ShellJoin(args[1:])
+
+		ctx, cancel := rpc.InterruptContext(context.Background())
+		defer cancel()
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		stream := !group && !util.IsStructuredOutput()
+		mux := sync.Mutex{}
+		results := selector.FanOut(ctx, nodes.Selected, parallel, func(ctx context.Context, node int) (interface{}, error) {
+			res := execOnNode(ctx, node, nodes.Nodes[node].IP, command)
+			if stream {
+				mux.Lock()
+				printPrefixed(os.Stdout, node, res.Stdout)
+				printPrefixed(os.Stderr, node, res.Stderr)
+				if len(res.Error) > 0 {
+					printPrefixed(os.Stderr, node, "Error: "+res.Error)
+				} else if res.ExitCode != 0 {
+					printPrefixed(os.Stderr, node, fmt.Sprintf("exited %d", res.ExitCode))
+				}
+				mux.Unlock()
+			}
+			return res, nil
+		})
+
+		out := []execResult{}
+		failed := []string{}
+		for _, result := range results {
+			res := result.Result.(execResult)
+			out = append(out, res)
+			if !res.failed() {
+				continue
+			}
+			if len(res.Error) > 0 {
+				failed = append(failed, fmt.Sprintf("node %d: %s", res.Node, res.Error))
+			} else {
+				failed = append(failed, fmt.Sprintf("node %d exited %d", res.Node, res.ExitCode))
+			}
+		}
+
+		switch {
+		case util.IsStructuredOutput():
+			err = util.PrintTable(out, "node", "ip", "exitCode", "duration", "error", "stdout", "stderr")
+			if err != nil {
+				return err
+			}
+		case group:
+			for _, res := range out {
+				status := fmt.Sprintf("exited %d", res.ExitCode)
+				if len(res.Error) > 0 {
+					status = res.Error
+				}
+				fmt.Printf("==> node %d (%s) %s in %v <==\n", res.Node, res.IP, status,
+					time.Duration(res.Duration).Round(time.Millisecond))
+				fmt.Print(res.Stdout)
+				fmt.Fprint(os.Stderr, res.Stderr)
+			}
+		}
+		if !util.IsStructuredOutput() {
+			fmt.Fprintf(os.Stderr, "ran on %d nodes, %d succeeded and %d failed\n", len(out), len(out)-len(failed), len(failed))
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("the command failed on %d of %d nodes: %s", len(failed), len(out), strings.Join(failed, ", "))
+		}
+		return nil
+	},
+}
+
+func init() {
+	execNodesCmd.Flags().IntP("parallel", "p", 10, "the number of nodes to run the command on at once, 0 for all of them")
+	execNodesCmd.Flags().Duration("timeout", 0, "stop the command on any node where it runs for longer than this, such as 30s")
+	execNodesCmd.Flags().Bool("group", false, "give the output of each node together once all have finished, instead of prefixing each line")
+	RootCmd.AddCommand(execNodesCmd)
+}
@@ -24,11 +24,34 @@ func handleFetchChunk(testnetID string, node Node, logName string, chunk string)
 	return util.JwtHTTPRequest("GET", ep, "")
 }
 
-func handleChunks(testnetID string, node Node, logName string, rawChunks string, sem *semaphore.Weighted) []string {
+// exportErrors keeps the first error of the goroutines fetching the export
+type exportErrors struct {
+	mux sync.Mutex
+	err error
+}
+
+func (ee *exportErrors) set(err error) {
+	if err == nil {
+		return
+	}
+	ee.mux.Lock()
+	defer ee.mux.Unlock()
+	if ee.err == nil {
+		ee.err = err
+	}
+}
+
+func (ee *exportErrors) get() error {
+	ee.mux.Lock()
+	defer ee.mux.Unlock()
+	return ee.err
+}
+
+func handleChunks(testnetID string, node Node, logName string, rawChunks string, sem *semaphore.Weighted) ([]string, error) {
 	var res map[string]interface{}
 	err := json.Unmarshal([]byte(rawChunks), &res)
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return nil, err
 	}
 	chunks := res["items"].([]interface{})
 	wg := sync.WaitGroup{}
@@ -37,6 +60,7 @@ func handleChunks(testnetID string, node Node, logName string, rawChunks string,
 	ctx := context.TODO()
 
 	outChunks := make([]string, len(chunks))
+	errs := exportErrors{}
 
 	for i, chunk := range chunks {
 		sem.Acquire(ctx, 1)
@@ -52,27 +76,25 @@ func handleChunks(testnetID string, node Node, logName string, rawChunks string,
 				}
 			}
 			if err != nil {
-				util.PrintErrorFatal(err)
+				errs.set(err)
+				return
 			}
 			log.WithFields(log.Fields{"chunk": chunk, "num": i}).Debug("fetched a chunk")
-			err = ioutil.WriteFile(fmt.Sprintf("./%s/%s/%s", node.ID, logName, chunk), []byte(res), 0664)
-			if err != nil {
-				util.PrintErrorFatal(err)
-			}
+			errs.set(ioutil.WriteFile(fmt.Sprintf("./%s/%s/%s", node.ID, logName, chunk), []byte(res), 0664))
 
 		}(chunk.(string), i)
 		outChunks[i] = chunk.(string)
 	}
 	wg.Wait()
 
-	return outChunks
+	return outChunks, errs.get()
 }
 
-func handleExportLogs(testnetID string, node Node, rawRes string, sem *semaphore.Weighted) (interface{}, map[string][]string) {
+func handleExportLogs(testnetID string, node Node, rawRes string, sem *semaphore.Weighted) (interface{}, map[string][]string, error) {
 	var res map[string]interface{}
 	err := json.Unmarshal([]byte(rawRes), &res)
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return nil, nil, err
 	}
 	logs := res["items"].([]interface{})
 	out := map[string][]string{}
@@ -85,6 +107,7 @@ func handleExportLogs(testnetID string, node Node, rawRes string, sem *semaphore
 	wg := sync.WaitGroup{}
 	wg.Add(len(logs))
 	mux := sync.Mutex{}
+	errs := exportErrors{}
 
 	for _, logName := range logs {
 		go func(logName string) {
@@ -93,40 +116,38 @@ func handleExportLogs(testnetID string, node Node, rawRes string, sem *semaphore
 			log.WithFields(log.Fields{"ep": ep}).Debug("fetching the log chunks")
 			res, err := util.JwtHTTPRequest("GET", ep, "")
 			if err != nil {
-				util.PrintErrorFatal(err)
+				errs.set(err)
+				return
 			}
+			chunks, err := handleChunks(testnetID, node, logName, res, sem)
+			errs.set(err)
 			mux.Lock()
-			out[logName] = handleChunks(testnetID, node, logName, res, sem)
+			out[logName] = chunks
 			mux.Unlock()
 		}(logName.(string))
 
 	}
 	wg.Wait()
-	return res["nextPageToken"], out
+	return res["nextPageToken"], out, errs.get()
 }
 
-func convertBlockNumber(blockNumber interface{}) int64 {
+func convertBlockNumber(blockNumber interface{}) (int64, error) {
 	switch num := blockNumber.(type) {
 	case float64:
-		return int64(num)
+		return int64(num), nil
 	case string:
 		num = strings.TrimLeft(num, "0")
-		res, err := strconv.ParseInt(num, 0, 64)
-		if err != nil {
-			util.PrintErrorFatal(err)
-		}
-		return res
+		return strconv.ParseInt(num, 0, 64)
 	default:
-		util.PrintErrorFatal(fmt.Errorf("blocknumber is of unknown type"))
+		return 0, fmt.Errorf("blocknumber is of unknown type")
 	}
-	panic("shouldn't reach")
 }
 
-func handleExportBlocks(testnetID string, node string, rawRes string, coveredBlockNumbers *map[int64]struct{}, sem *semaphore.Weighted) (interface{}, []string) {
+func handleExportBlocks(testnetID string, node string, rawRes string, coveredBlockNumbers *map[int64]struct{}, sem *semaphore.Weighted) (interface{}, []string, error) {
 	var res map[string]interface{}
 	err := json.Unmarshal([]byte(rawRes), &res)
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return nil, nil, err
 	}
 	blockNumbers := res["items"].([]interface{})
 
@@ -135,11 +156,17 @@ func handleExportBlocks(testnetID string, node string, rawRes string, coveredBlo
 	wg := sync.WaitGroup{}
 	wg.Add(len(blockNumbers))
 	mux := sync.Mutex{}
+	errs := exportErrors{}
 
 	ctx := context.TODO()
 	for i, blockNumber := range blockNumbers {
 
-		num := convertBlockNumber(blockNumber)
+		num, err := convertBlockNumber(blockNumber)
+		if err != nil {
+			wg.Done()
+			errs.set(err)
+			continue
+		}
 		if _, ok := (*coveredBlockNumbers)[num]; ok {
 			wg.Done()
 			continue
@@ -160,7 +187,8 @@ func handleExportBlocks(testnetID string, node string, rawRes string, coveredBlo
 				}
 			}
 			if err != nil {
-				util.PrintErrorFatal(err)
+				errs.set(err)
+				return
 			}
 			mux.Lock()
 			out[i] = res
@@ -170,55 +198,57 @@ func handleExportBlocks(testnetID string, node string, rawRes string, coveredBlo
 
 	}
 	wg.Wait()
-	return res["nextPageToken"], out
+	return res["nextPageToken"], out, errs.get()
 }
 
-func mergeDown(node Node, files map[string][]string) {
+func mergeDown(node Node, files map[string][]string) error {
 	for file, chunks := range files {
 		tmpFileName := fmt.Sprintf("./%s/%s.tmp", node.ID, file)
 		fd, err := os.Create(tmpFileName)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
-		defer func() {
-			_, err = fd.Write([]byte("]"))
-			if err != nil {
-				util.PrintErrorFatal(err)
-			}
-			_ = fd.Close()
-		}()
 		for _, chunk := range chunks {
 			data, err := ioutil.ReadFile(fmt.Sprintf("./%s/%s/%s", node.ID, file, chunk))
-			if err != nil {
-				util.PrintErrorFatal(err)
+			if err == nil {
+				_, err = fd.Write(data)
 			}
-			_, err = fd.Write(data)
 			if err != nil {
-				util.PrintErrorFatal(err)
+				fd.Close()
+				return err
 			}
 		}
+		_, err = fd.Write([]byte("]"))
+		if err != nil {
+			fd.Close()
+			return err
+		}
+		err = fd.Close()
+		if err != nil {
+			return err
+		}
 		err = os.RemoveAll(fmt.Sprintf("./%s/%s", node.ID, file))
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		err = syscall.Rename(fmt.Sprintf("./%s/%s.tmp", node.ID, file), fmt.Sprintf("./%s/%s", node.ID, file))
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 	}
-
+	return nil
 }
 
-func appendBlocks(items []string, firstCall bool, f *os.File) {
+func appendBlocks(items []string, firstCall bool, f *os.File) error {
 
 	fInfo, err := f.Stat()
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 	if fInfo.Size() == 0 {
 		_, err = f.Write([]byte("["))
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 	}
 	first := true
@@ -231,15 +261,16 @@ func appendBlocks(items []string, firstCall bool, f *os.File) {
 		} else {
 			_, err := f.Write([]byte(","))
 			if err != nil {
-				util.PrintErrorFatal(err)
+				return err
 			}
 		}
 
 		_, err := f.Write([]byte(item))
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 	}
+	return nil
 }
 
 var exportCmd = &cobra.Command{
@@ -247,39 +278,38 @@ var exportCmd = &cobra.Command{
 	Use:    "export [testnet id]",
 	Short:  "Export stuff",
 	Long:   "Export stuff",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 
 		spinner := Spinner{txt: "fetching the block and log data"}
 		spinner.Run(100)
 		defer spinner.Kill()
 		local, err := cmd.Flags().GetBool("local")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		outputDir, err := cmd.Flags().GetString("dir")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		startBlock, err := cmd.Flags().GetInt("start-block")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		singleNodeMode, err := cmd.Flags().GetBool("single-node-mode")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 
 		os.MkdirAll(outputDir, 0755)
 		if local {
-			fetchDataLocally(outputDir, startBlock, singleNodeMode)
-			return
+			return fetchDataLocally(outputDir, startBlock, singleNodeMode)
 		}
 
 		var testnetID string
 		if len(args) == 0 {
-			testnetID = build.GetPreviousBuildID()
+			testnetID, err = build.GetPreviousBuildID()
 			if err != nil {
-				util.PrintErrorFatal(err)
+				return err
 			}
 		} else {
 			testnetID = args[0]
@@ -289,11 +319,11 @@ var exportCmd = &cobra.Command{
 		ep := fmt.Sprintf("%s/testnets/%s/nodes", conf.APIURL, testnetID)
 		res, err := util.JwtHTTPRequest("GET", ep, "")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		err = json.Unmarshal([]byte(res), &nodes)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		for _, node := range nodes {
 			os.RemoveAll(fmt.Sprintf("%s/%s", outputDir, node.ID))
@@ -302,6 +332,7 @@ var exportCmd = &cobra.Command{
 		log.Trace("removed the files")
 		wg := sync.WaitGroup{}
 		wg.Add(len(nodes))
+		errs := exportErrors{}
 
 		for _, node := range nodes {
 			go func(node Node) {
@@ -318,11 +349,18 @@ var exportCmd = &cobra.Command{
 					}
 					res, err := util.JwtHTTPRequest("GET", ep, "")
 					if err != nil {
-						util.PrintErrorFatal(err)
+						errs.set(err)
+						return
 					}
 					log.WithFields(log.Fields{"ep": ep, "res": res}).Debug("fetching the logs")
-					nextToken, files = handleExportLogs(testnetID, node, res, sem)
-					mergeDown(node, files)
+					nextToken, files, err = handleExportLogs(testnetID, node, res, sem)
+					if err == nil {
+						err = mergeDown(node, files)
+					}
+					if err != nil {
+						errs.set(err)
+						return
+					}
 					if nextToken == nil {
 						break
 					}
@@ -341,7 +379,11 @@ var exportCmd = &cobra.Command{
 
 				f, err := os.Create(fmt.Sprintf("%s/%s/blocks.json", outputDir, node.ID))
 				if err != nil {
-					util.PrintErrorFatal(err)
+					for _, f := range files {
+						f.Close()
+					}
+					errs.set(err)
+					return
 				}
 				files = append(files, f)
 			}
@@ -350,6 +392,7 @@ var exportCmd = &cobra.Command{
 				wg.Add(1)
 				go func(node Node, i int) {
 					defer wg.Done()
+					defer files[i].Close()
 					coveredBlockNumbers := map[int64]struct{}{}
 					first := true
 					for {
@@ -363,26 +406,27 @@ var exportCmd = &cobra.Command{
 						log.WithFields(log.Fields{"ep": ep}).Debug("fetching the log chunks")
 						res, err := util.JwtHTTPRequest("GET", ep, "")
 						if err != nil {
-							util.PrintErrorFatal(err)
+							errs.set(err)
+							return
 						}
 						log.WithFields(log.Fields{"ep": ep, "res": res}).Debug("fetched the blocks")
 
-						nextToken, blocks = handleExportBlocks(testnetID, node.ID, res, &coveredBlockNumbers, sem)
-						appendBlocks(blocks, first, files[i])
+						nextToken, blocks, err = handleExportBlocks(testnetID, node.ID, res, &coveredBlockNumbers, sem)
+						if err == nil {
+							err = appendBlocks(blocks, first, files[i])
+						}
+						if err != nil {
+							errs.set(err)
+							return
+						}
 						first = false
 
 						if nextToken == nil {
 							break
 						}
 					}
-					_, err = files[i].Write([]byte("]"))
-					if err != nil {
-						util.PrintErrorFatal(err)
-					}
-					err = files[i].Close()
-					if err != nil {
-						util.PrintErrorFatal(err)
-					}
+					_, err := files[i].Write([]byte("]"))
+					errs.set(err)
 				}(node, i)
 			}
 		}()
@@ -395,12 +439,13 @@ var exportCmd = &cobra.Command{
 		}*/
 		//fmt.Printf("https://api.whiteblock.io/testnets/%s/nodes/%s/blocks\n",testnetID,node.ID)
 		//	fmt.Printf("https://api.whiteblock.io/testnets/%s/nodes/%s/logs\n",testnetID,node.ID)
+		return errs.get()
 	},
 }
 
 func GrabManyBlocks(sem *semaphore.Weighted, start int, end int) ([]string, error) {
 	out := make([]string, end-start)
-	var outErr error
+	errs := exportErrors{}
 	wg := sync.WaitGroup{}
 	ctx := context.TODO()
 
@@ -412,29 +457,31 @@ func GrabManyBlocks(sem *semaphore.Weighted, start int, end int) ([]string, erro
 			defer wg.Done()
 			data, err := util.JsonRpcCall("get_block", []interface{}{i})
 			if err != nil {
-				util.PrintErrorFatal(err)
+				errs.set(err)
+				return
 			}
 			block, err := json.Marshal(data)
 			if err != nil {
-				util.PrintErrorFatal(err)
+				errs.set(err)
+				return
 			}
 			*blck = string(block)
 		}(&out[i-start], i)
 	}
 	wg.Wait()
 	log.WithFields(log.Fields{"start": start, "end": end}).Trace("fetched some blocks")
-	return out, outErr
+	return out, errs.get()
 }
 
-func fetchBlockDataLocally(sem *semaphore.Weighted, node Node, blockHeight int, startBlock int, dir string) {
+func fetchBlockDataLocally(sem *semaphore.Weighted, node Node, blockHeight int, startBlock int, dir string) error {
 	os.RemoveAll(fmt.Sprintf("%s/%s", dir, node.ID))
 	err := os.MkdirAll(fmt.Sprintf("%s/%s", dir, node.ID), 0755)
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 	fd, err := os.Create(fmt.Sprintf("%s/%s/blocks.json", dir, node.ID))
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 	defer fd.Close()
 
@@ -450,28 +497,31 @@ func fetchBlockDataLocally(sem *semaphore.Weighted, node Node, blockHeight int,
 			i -= diff
 			continue
 		}
-		appendBlocks(blocks, i == startBlock, fd)
+		err = appendBlocks(blocks, i == startBlock, fd)
+		if err != nil {
+			return err
+		}
 		err = fd.Sync()
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 	}
 	_, err = fd.Write([]byte("]"))
 	if err != nil {
-		util.PrintErrorFatal(err)
-	}
-	err = fd.Sync()
-	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
+	return fd.Sync()
 }
 
 //only supports the main log and the block data
-func fetchDataLocally(dir string, startBlock int, singleNodeMode bool) {
+func fetchDataLocally(dir string, startBlock int, singleNodeMode bool) error {
 	sem := semaphore.NewWeighted(conf.MaxConns)
-	nodes := GetNodes()
+	nodes, err := GetNodes()
+	if err != nil {
+		return err
+	}
 	if len(nodes) < 1 {
-		return
+		return nil
 	}
 	if singleNodeMode {
 		nodes = nodes[:1]
@@ -480,18 +530,22 @@ func fetchDataLocally(dir string, startBlock int, singleNodeMode bool) {
 	for i := range nodes {
 		err := util.JsonRpcCallP("get_block_number", []interface{}{i}, &blockHeights[i])
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		log.WithFields(log.Fields{"node": i, "block number": blockHeights[i]}).Trace("got the block height for the node")
 	}
+	testnetID, err := build.GetPreviousBuildID()
+	if err != nil {
+		return err
+	}
 	wg := sync.WaitGroup{}
-	testnetID := build.GetPreviousBuildID()
+	errs := exportErrors{}
 
 	for i, blockHeight := range blockHeights {
 		wg.Add(1)
 		go func(blockHeight int, i int) {
 			defer wg.Done()
-			fetchBlockDataLocally(sem, nodes[i], blockHeight, startBlock, dir)
+			errs.set(fetchBlockDataLocally(sem, nodes[i], blockHeight, startBlock, dir))
 		}(blockHeight, i)
 		wg.Add(1)
 
@@ -503,19 +557,19 @@ func fetchDataLocally(dir string, startBlock int, singleNodeMode bool) {
 				"lines":     -1,
 			})
 			if err != nil {
-				util.PrintErrorFatal(err)
+				errs.set(err)
+				return
 			}
 			toWrite, err := json.Marshal(res)
 			if err != nil {
-				util.PrintErrorFatal(err)
-			}
-			err = ioutil.WriteFile(fmt.Sprintf("%s/%s/output.log", dir, nodes[i].ID), toWrite, 0664)
-			if err != nil {
-				util.PrintErrorFatal(err)
+				errs.set(err)
+				return
 			}
+			errs.set(ioutil.WriteFile(fmt.Sprintf("%s/%s/output.log", dir, nodes[i].ID), toWrite, 0664))
 		}(i)
 	}
 	wg.Wait()
+	return errs.get()
 }
 
 func init() {
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sort"
@@ -13,14 +14,18 @@ import (
 	"github.com/whiteblock/cli/whiteblock/util"
 )
 
-func GetNodes() []Node {
-	var out []Node
-	err := util.JsonRpcCallP("nodes", []string{build.GetPreviousBuildID()}, &out)
+// GetNodes gets the nodes of the previous build
+func GetNodes() ([]Node, error) {
+	testnetID, err := build.GetPreviousBuildID()
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return nil, err
+	}
+	out, err := rpcClient().Nodes(context.Background(), testnetID)
+	if err != nil {
+		return nil, err
 	}
 	log.WithFields(log.Fields{"nodes": out}).Trace("raw nodes")
-	return out
+	return out, nil
 }
 
 var getCmd = &cobra.Command{
@@ -35,18 +40,25 @@ var getServerCmd = &cobra.Command{
 	Aliases: []string{"servers"},
 	Short:   "Get server information.",
 	Long:    "\nServer will output server information.\n",
-	Run: func(cmd *cobra.Command, args []string) {
-		util.JsonRpcCallAndPrint("get_servers", []string{})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return util.JsonRpcCallAndPrint("get_servers", []string{}, serverColumns...)
 	},
 }
 
+// serverColumns are the fields of each server shown by --output table, after its name
+var serverColumns = []string{"id", "addr", "nodes", "max"}
+
 var getTestnetIDCmd = &cobra.Command{
 	Use:     "testnetid",
 	Aliases: []string{"id"},
 	Short:   "Get the last stored testnet id",
 	Long:    "\nGet the last stored testnet id.\n",
-	Run: func(cmd *cobra.Command, args []string) {
-		util.Print(build.GetPreviousBuildID())
+	RunE: func(cmd *cobra.Command, args []string) error {
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		return util.Print(testnetID)
 	},
 }
 
@@ -55,12 +67,12 @@ var getBuildCmd = &cobra.Command{
 	Aliases: []string{"built"},
 	Short:   "Get the last applied build",
 	Long:    "\nGet the last applied build.\n",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		prevBuild, err := build.GetPreviousBuild()
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
-		util.Print(prevBuild)
+		return util.Print(prevBuild)
 	},
 }
 
@@ -69,41 +81,54 @@ var getSupportedCmd = &cobra.Command{
 	Aliases: []string{"blockchains"},
 	Short:   "Get the currently supported blockchains",
 	Long:    "Fetches the blockchains which whiteblock is currently able build by default",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 
 		var blockchains []string
-		util.JsonRpcCallP("get_supported_blockchains", []string{}, &blockchains)
+		err := util.JsonRpcCallP("get_supported_blockchains", []string{}, &blockchains)
+		if err != nil {
+			return err
+		}
 		sortedBlockchains := sort.StringSlice(blockchains)
 		sortedBlockchains.Sort()
-		util.Print([]string(sortedBlockchains))
+		return util.Print([]string(sortedBlockchains))
 	},
 }
 
+// the columns of get nodes, when given --output table
+var nodeColumns = []string{"absNum", "id", "ip", "server", "image", "label", "up"}
+
 var getNodesCmd = &cobra.Command{
 	Use:     "nodes",
 	Aliases: []string{"node"},
 	Short:   "Nodes will show all nodes in the network.",
 	Long:    "\nNodes will output all of the nodes in the current network.\n",
 
-	Run: func(cmd *cobra.Command, args []string) {
-		testnetID := build.GetPreviousBuildID()
-		if util.GetBoolFlagValue(cmd, "all") {
-			util.JsonRpcCallAndPrint("status_nodes", []string{testnetID})
-			return
+	RunE: func(cmd *cobra.Command, args []string) error {
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		all, err := util.GetBoolFlagValue(cmd, "all")
+		if err != nil {
+			return err
+		}
+		if all {
+			return util.JsonRpcCallAndPrint("status_nodes", []string{testnetID}, nodeColumns...)
 		}
 		res, err := util.JsonRpcCall("status_nodes", []string{testnetID})
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 
-		rawNodes := res.([]interface{})
+		rawNodes, _ := res.([]interface{})
 		out := []interface{}{}
 		for _, rawNode := range rawNodes {
-			if rawNode.(map[string]interface{})["up"].(bool) {
+			node, _ := rawNode.(map[string]interface{})
+			if up, _ := node["up"].(bool); up {
 				out = append(out, rawNode)
 			}
 		}
-		util.Print(out)
+		return util.PrintTable(out, nodeColumns...)
 	},
 }
 
@@ -113,8 +138,11 @@ var getNodesExternalCmd = &cobra.Command{
 	Short:   "Get the port mappings of the nodes",
 	Long:    "\nGet the port mappings of the nodes\n",
 
-	Run: func(cmd *cobra.Command, args []string) {
-		nodes := GetNodes()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nodes, err := GetNodes()
+		if err != nil {
+			return err
+		}
 		out := []map[string]interface{}{}
 		for _, node := range nodes {
 			out = append(out, map[string]interface{}{
@@ -124,7 +152,7 @@ var getNodesExternalCmd = &cobra.Command{
 				"protocol":     node.Protocol,
 			})
 		}
-		util.Print(out)
+		return util.PrintTable(out, "absNum", "id", "protocol", "portMappings")
 	},
 }
 
@@ -136,10 +164,16 @@ Running will check whether or not there is a test running and get the name of th
 
 Response: true or false, on whether or not a test is running; The name of the test or nothing if there is not a test running.
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 0, 0)
-		util.JsonRpcCallAndPrint("state::is_running", []string{})
-		util.JsonRpcCallAndPrint("state::what_is_running", []string{})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
+		}
+		err = util.JsonRpcCallAndPrint("state::is_running", []string{})
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("state::what_is_running", []string{})
 	},
 }
 
@@ -152,9 +186,12 @@ Get the blockchain specific parameters for a deployed blockchain.
 
 Response: The params as a list of key value params, of name and type respectively
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 1)
-		util.JsonRpcCallAndPrint("get_defaults", args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("get_defaults", args)
 	},
 }
 
@@ -170,9 +207,12 @@ Params: The blockchain to get the resources of, the resource/file name
 
 Response: The resoures as a list of key value params, of name and type respectively
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 2)
-		util.JsonRpcCallAndPrint("get_resources", args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 2)
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("get_resources", args)
 	},
 }
 
@@ -197,11 +237,22 @@ Params: start unix timestamp, end unix timestamp
 
 Response: JSON representation of network statistics
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 2, 2)
-		util.JsonRpcCallAndPrint("stats", map[string]int64{
-			"startTime":  util.CheckAndConvertInt64(args[0], "start unix timestamp"),
-			"endTime":    util.CheckAndConvertInt64(args[1], "end unix timestamp"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 2, 2)
+		if err != nil {
+			return err
+		}
+		startTime, err := util.CheckAndConvertInt64(args[0], "start unix timestamp")
+		if err != nil {
+			return err
+		}
+		endTime, err := util.CheckAndConvertInt64(args[1], "end unix timestamp")
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("stats", map[string]int64{
+			"startTime":  startTime,
+			"endTime":    endTime,
 			"startBlock": 0,
 			"endBlock":   0,
 		})
@@ -216,13 +267,24 @@ Stats block will allow the user to get statistics regarding the network.
 
 Response: JSON representation of statistics
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 2, 2)
-		util.JsonRpcCallAndPrint("stats", map[string]int64{
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 2, 2)
+		if err != nil {
+			return err
+		}
+		startBlock, err := util.CheckAndConvertInt64(args[0], "start block number")
+		if err != nil {
+			return err
+		}
+		endBlock, err := util.CheckAndConvertInt64(args[1], "end block number")
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("stats", map[string]int64{
 			"startTime":  0,
 			"endTime":    0,
-			"startBlock": util.CheckAndConvertInt64(args[0], "start block number"),
-			"endBlock":   util.CheckAndConvertInt64(args[1], "end block number"),
+			"startBlock": startBlock,
+			"endBlock":   endBlock,
 		})
 	},
 }
@@ -235,12 +297,19 @@ Stats block will allow the user to get statistics regarding the network.
 
 Response: JSON representation of statistics
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 1)
-		util.JsonRpcCallAndPrint("stats", map[string]int64{
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		blocks, err := util.CheckAndConvertInt64(args[0], "blocks")
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("stats", map[string]int64{
 			"startTime":  0,
 			"endTime":    0,
-			"startBlock": util.CheckAndConvertInt64(args[0], "blocks") * -1, //Negative number signals past
+			"startBlock": blocks * -1, //Negative number signals past
 			"endBlock":   0,
 		})
 	},
@@ -253,8 +322,8 @@ var statsAllCmd = &cobra.Command{
 	Get some general statistics on the blockchain network.
 	Note: This is will be behind by a few blocks to ensure accuracy.
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.JsonRpcCallAndPrint("all_stats", []string{})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return util.JsonRpcCallAndPrint("all_stats", []string{})
 	},
 }
 
@@ -262,10 +331,13 @@ var statsAllCmd = &cobra.Command{
 Work underway on generalized use commands to consolidate all the different
 commands separated by blockchains.
 */
-func getBlockJsonRpcCall(rpc string, args []string) {
+func getBlockJsonRpcCall(rpc string, args []string) error {
 	res, err := util.JsonRpcCall(rpc, args)
 	if err != nil { //try a few nodes
-		nodes := GetNodes()
+		nodes, nodesErr := GetNodes()
+		if nodesErr != nil {
+			return nodesErr
+		}
 		for i := range nodes {
 			res, err = util.JsonRpcCall(rpc, []interface{}{args[0], i})
 			if err == nil {
@@ -274,25 +346,27 @@ func getBlockJsonRpcCall(rpc string, args []string) {
 		}
 	}
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
-	util.Print(res)
+	return util.Print(res)
 }
 
-func getBlockCobra(cmd *cobra.Command, args []string) {
-	util.CheckArguments(cmd, args, 1, 1)
+func getBlockCobra(cmd *cobra.Command, args []string) error {
+	err := util.CheckArguments(args, 1, 1)
+	if err != nil {
+		return err
+	}
 	out, err := strconv.ParseInt(args[0], 0, 32) //Check if the input is an integer-> block number
 	if err != nil {
 		// Block Hash
-		getBlockJsonRpcCall("get_block_by_hash", args)
-	} else {
-		// Block number
-		blockNum := int(out)
-		if blockNum < 1 {
-			util.PrintErrorFatal("Unable to get block information from block 0. Please provide a block number greater than 0.")
-		}
-		getBlockJsonRpcCall("get_block", args)
+		return getBlockJsonRpcCall("get_block_by_hash", args)
 	}
+	// Block number
+	blockNum := int(out)
+	if blockNum < 1 {
+		return util.NewValidationError("Unable to get block information from block 0. Please provide a block number greater than 0.")
+	}
+	return getBlockJsonRpcCall("get_block", args)
 
 	/*res, err := util.JsonRpcCall("get_block_number", []string{})
 	if err != nil {
@@ -307,16 +381,28 @@ func getBlockCobra(cmd *cobra.Command, args []string) {
 	}*/
 }
 
-func getBlockHeightByNode(cmd *cobra.Command, args []string) {
+func getBlockHeightByNode(cmd *cobra.Command, args []string) error {
 	wg := sync.WaitGroup{}
 	mux := sync.Mutex{}
 
-	util.CheckArguments(cmd, args, 0, 1)
+	err := util.CheckArguments(args, 0, 1)
+	if err != nil {
+		return err
+	}
+	all, err := util.GetBoolFlagValue(cmd, "all")
+	if err != nil {
+		return err
+	}
 
-	if util.GetBoolFlagValue(cmd, "all") {
-		nodes := len(GetNodes())
+	if all {
+		allNodes, err := GetNodes()
+		if err != nil {
+			return err
+		}
+		nodes := len(allNodes)
 
 		blockHeights := make([]string, nodes)
+		errs := make([]error, nodes)
 
 		for i := 0; i < nodes; i++ {
 			wg.Add(1)
@@ -325,7 +411,8 @@ func getBlockHeightByNode(cmd *cobra.Command, args []string) {
 
 				res, err := util.JsonRpcCall("get_block_number", []interface{}{i})
 				if err != nil {
-					util.PrintErrorFatal(err)
+					errs[i] = err
+					return
 				}
 
 				mux.Lock()
@@ -334,27 +421,26 @@ func getBlockHeightByNode(cmd *cobra.Command, args []string) {
 			}(i)
 		}
 		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
 
-		util.Print(blockHeights)
-
-		return
+		return util.Print(blockHeights)
 	}
 
 	if len(args) == 0 {
-		util.JsonRpcCallAndPrint("get_block_number", []interface{}{0})
-		return
+		return util.JsonRpcCallAndPrint("get_block_number", []interface{}{0})
 	}
 
-	util.JsonRpcCallAndPrint("get_block_number", []interface{}{args[0]})
-
-	return
+	return util.JsonRpcCallAndPrint("get_block_number", []interface{}{args[0]})
 }
 
-func getPrivateKeys(cmd *cobra.Command, args []string) {
+func getPrivateKeys(cmd *cobra.Command, args []string) error {
 	res, err := util.JsonRpcCall("state::info", args)
 	if err != nil {
-		util.PrintErrorFatal(err)
-		return
+		return err
 	}
 
 	privKeys := make([]string, 0)
@@ -369,14 +455,13 @@ func getPrivateKeys(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	util.Print(privKeys)
-	return
+	return util.Print(privKeys)
 }
 
 var getBlockCmd = &cobra.Command{
 	Use:   "block <command>",
 	Short: "Get information regarding blocks",
-	Run:   getBlockCobra,
+	RunE:  getBlockCobra,
 }
 
 var getBlockNumCmd = &cobra.Command{
@@ -387,7 +472,7 @@ Gets the most recent block number that had been added to the blockchain.
 
 Response: block number
 	`,
-	Run: getBlockHeightByNode,
+	RunE: getBlockHeightByNode,
 }
 
 var getBlockInfoCmd = &cobra.Command{
@@ -398,7 +483,7 @@ Gets the information inside a block including transactions and other information
 
 Response: JSON representation of the block
 	`,
-	Run: getBlockCobra,
+	RunE: getBlockCobra,
 }
 
 var getTxCmd = &cobra.Command{
@@ -412,14 +497,20 @@ var getTxRecentCmd = &cobra.Command{
 	Short: "Get transaction information",
 	Long: `Get the tx hash(es) of recently sent transactions
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 0, 1)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 1)
+		if err != nil {
+			return err
+		}
 		var num int = 5
 		if len(args) > 0 {
-			num = util.CheckAndConvertInt(args[0], "number of transactions")
+			num, err = util.CheckAndConvertInt(args[0], "number of transactions")
+			if err != nil {
+				return err
+			}
 		}
 
-		util.JsonRpcCallAndPrint("state::get_recent_tx", []interface{}{num})
+		return util.JsonRpcCallAndPrint("state::get_recent_tx", []interface{}{num})
 	},
 }
 
@@ -432,9 +523,12 @@ To view block information, the command 'get block info <block number>' can be us
 
 Response: JSON representation of the transaction.
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 1)
-		util.JsonRpcCallAndPrint("get_transaction", args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("get_transaction", args)
 	},
 }
 
@@ -447,9 +541,12 @@ To view block information, the command 'get block info <block number>' can be us
 
 Response: JSON representation of the transaction receipt.
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 1)
-		util.JsonRpcCallAndPrint("get_transaction_receipt", args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("get_transaction_receipt", args)
 	},
 }
 
@@ -457,8 +554,8 @@ var getAccountCmd = &cobra.Command{
 	Aliases: []string{"accounts"},
 	Use:     "account",
 	Short:   "Get account information",
-	Run: func(cmd *cobra.Command, args []string) {
-		util.JsonRpcCallAndPrint("state::get", []string{"accounts"})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return util.JsonRpcCallAndPrint("state::get", []string{"accounts"})
 	},
 }
 
@@ -471,8 +568,8 @@ Gets the account information relevant to the currently connected blockchain.
 
 Response: JSON representation of the accounts information.
 `,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.JsonRpcCallAndPrint("accounts_status", []string{})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return util.JsonRpcCallAndPrint("accounts_status", []string{})
 	},
 }
 
@@ -482,22 +579,22 @@ var getPrivateKeysCmd = &cobra.Command{
 	Long: `
 Gets the private keys of _______________________________.
 `,
-	Run: getPrivateKeys,
+	RunE: getPrivateKeys,
 }
 
 var getBiomeCmd = &cobra.Command{
 	Use:   "biome",
 	Short: "Get the biome id",
-	Run: func(cmd *cobra.Command, args []string) {
-		util.JsonRpcCallAndPrint("get_biome_id", []string{})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return util.JsonRpcCallAndPrint("get_biome_id", []string{})
 	},
 }
 
 var getBoxCmd = &cobra.Command{
 	Use: "box",
 	Short: "Get box information",
-	Run: func(cmd *cobra.Command, args []string) {
-		util.Print(conf.ServerAddr)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return util.Print(conf.ServerAddr)
 	},
 }
 
@@ -510,15 +607,15 @@ the contract name, and the contract's address.
 
 Response: JSON representation of the contract information.
 `,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		var contracts []interface{}
 		err := util.ReadTestnetStore("contracts", &contracts)
 		if err != nil {
-			util.PrintErrorFatal("No smart contract has been deployed yet." +
+			return fmt.Errorf("No smart contract has been deployed yet." +
 				" Please use the command 'whiteblock geth solc deploy <smart contract> to deploy a smart contract.")
 
 		}
-		util.Print(contracts)
+		return util.Print(contracts)
 	},
 }
 
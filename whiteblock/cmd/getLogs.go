@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/client"
 	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
 	"github.com/whiteblock/cli/whiteblock/util"
 	"golang.org/x/crypto/ssh"
 	"io"
@@ -13,43 +16,58 @@ import (
 )
 
 var getLogCmd = &cobra.Command{
-	Use:     "log <node>",
+	Use:     "log <nodes>",
 	Aliases: []string{"logs"},
 	Short:   "Log will dump data pertaining to the node.",
 	Long: `
-Get stdout and stderr from a node.
+Get stdout and stderr from each of the nodes. Only a single node may be followed with --follow.
 
-Params: node number
+Params: nodes
 
 Response: stdout and stderr of the blockchain process
-	`,
+
+` + selector.Usage,
 	//tail -f --zero-terminated /output.log
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 1)
-		testNetId := build.GetPreviousBuildID()
-		n := util.CheckAndConvertInt(args[0], "node number")
-
-		if !util.GetBoolFlagValue(cmd, "follow") {
-			util.JsonRpcCallAndPrint("log", map[string]interface{}{
-				"testnetId": testNetId,
-				"node":      n,
-				"lines":     util.GetIntFlagValue(cmd, "tail"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		nodes, err := selectNodes(args[0])
+		if err != nil {
+			return err
+		}
+
+		follow, err := util.GetBoolFlagValue(cmd, "follow")
+		if err != nil {
+			return err
+		}
+		if !follow {
+			lines, err := util.GetIntFlagValue(cmd, "tail")
+			if err != nil {
+				return err
+			}
+			c := rpcClient()
+			return nodes.run(func(ctx context.Context, node int) (interface{}, error) {
+				out, err := c.Log(ctx, client.LogRequest{TestnetID: nodes.TestnetID, Node: node, Lines: lines})
+				return out, err
 			})
-			return
 		}
 		//Forward the output from tail -f
-		nodes := GetNodes()
-		util.CheckIntegerBounds(cmd, "node", n, 0, len(nodes)-1)
+		n, err := nodes.single()
+		if err != nil {
+			return err
+		}
 
-		client, err := util.NewSshClient(fmt.Sprintf(nodes[n].IP))
+		sshClient, err := util.NewSshClient(fmt.Sprintf(nodes.Nodes[n].IP))
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
-		defer client.Close()
+		defer sshClient.Close()
 
-		session, err := client.GetSession()
+		session, err := sshClient.GetSession()
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		defer session.Close() //Open up a session
 
@@ -70,17 +88,17 @@ Response: stdout and stderr of the blockchain process
 		}
 
 		if err := session.RequestPty("xterm", int(ws.Row), int(ws.Col), modes); err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		var outReader io.Reader
 		outReader, err = session.StdoutPipe()
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 
 		err = session.Start("tail -f --zero-terminated /output.log")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 
 		scanner := bufio.NewScanner(outReader)
@@ -98,15 +116,30 @@ var getLogAllCmd = &cobra.Command{
 	Short: "Get all of the logs",
 	Long: `Gets all of the logs
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		nodes := GetNodes()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lines, err := util.GetIntFlagValue(cmd, "tail")
+		if err != nil {
+			return err
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		nodes, err := GetNodes()
+		if err != nil {
+			return err
+		}
 		for i := range nodes {
-			util.JsonRpcCallAndPrint("log", map[string]interface{}{
-				"testnetId": build.GetPreviousBuildID(),
+			err = util.JsonRpcCallAndPrint("log", map[string]interface{}{
+				"testnetId": testnetID,
 				"node":      i,
-				"lines":     util.GetIntFlagValue(cmd, "tail"),
+				"lines":     lines,
 			})
+			if err != nil {
+				return err
+			}
 		}
+		return nil
 	},
 }
 
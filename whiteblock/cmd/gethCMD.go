@@ -5,7 +5,6 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/whiteblock/cli/whiteblock/util"
-	"golang.org/x/sys/unix"
 	"os"
 	"os/exec"
 	"regexp"
@@ -58,40 +57,35 @@ func addContract(contract Contract) error {
 	return util.WriteTestnetStore("contracts", contracts)
 }
 
-func checkContractDir() {
+func checkContractDir() error {
 	cwd := os.Getenv("HOME")
 	if _, err := os.Stat(cwd + "/smart-contracts/"); os.IsNotExist(err) {
 		util.Print("'smart-contracts' directory could not be found. Creating the directory 'smart-contracts' in home directory.")
 		util.Print("Preparing the dependencies to deploy smart contracts.")
 		err := os.MkdirAll(cwd+"/smart-contracts/", 0755)
 		if err != nil {
-			log.Fatalf("could not create directory: %s", err)
-			return
+			return fmt.Errorf("could not create directory: %s", err)
 		}
 		solFile, err := os.Create(cwd + "/smart-contracts/helloworld.sol")
 		if err != nil {
-			log.Fatalf("failed creating file: %s", err)
-			return
+			return fmt.Errorf("failed creating file: %s", err)
 		}
+		defer solFile.Close()
 		solFile.Write([]byte(exSolFile))
 		compileFile, err := os.Create(cwd + "/smart-contracts/compile.js")
 		if err != nil {
-			log.Fatalf("failed creating file: %s", err)
-			return
+			return fmt.Errorf("failed creating file: %s", err)
 		}
+		defer compileFile.Close()
 		compileFile.Write([]byte(compileJS))
 		deployFile, err := os.Create(cwd + "/smart-contracts/deploy.js")
 		if err != nil {
-			log.Fatalf("failed creating file: %s", err)
+			return fmt.Errorf("failed creating file: %s", err)
 		}
-		deployFile.Write([]byte(deployJs))
-
-		defer solFile.Close()
-		defer compileFile.Close()
 		defer deployFile.Close()
-
-		return
+		deployFile.Write([]byte(deployJs))
 	}
+	return nil
 }
 
 func checkContractFiles(fileName string) bool {
@@ -189,11 +183,14 @@ var gethSolcInitCmd = &cobra.Command{
 	Long: `
 Init initialize the smart-contracts directory and will download all the necessary dependencies. This may take some time as the files are being pulled. All smart contracts should be put into the 'smart-contracts' directory.
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		util.Print("Checking Directory")
-		checkContractDir()
+		err := checkContractDir()
+		if err != nil {
+			return err
+		}
 		installNpmDeps()
-		util.Print("'smart-contracts' directory is initializd and smart contract deployment is now available.")
+		return util.Print("'smart-contracts' directory is initializd and smart contract deployment is now available.")
 	},
 }
 
@@ -205,12 +202,15 @@ Deploy will compile the smart contract and deploy it to the ethereum blockchain.
 
 Output: Deployed contract address
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 2, 2)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 2, 2)
+		if err != nil {
+			return err
+		}
 		//assertions for sanity
 		res, err := util.JsonRpcCall("get_block_number", []string{})
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		blocknum := int(res.(float64))
 		if blocknum == 0 {
@@ -218,10 +218,19 @@ Output: Deployed contract address
 		}
 
 		if checkContractFiles(args[1]) {
-			nodes := GetNodes()
+			nodes, err := GetNodes()
+			if err != nil {
+				return err
+			}
 
-			nodeNumber := util.CheckAndConvertInt(args[0], "node number")
-			util.CheckIntegerBounds(cmd, "node number", nodeNumber, 0, len(nodes)-1)
+			nodeNumber, err := util.CheckAndConvertInt(args[0], "node number")
+			if err != nil {
+				return err
+			}
+			err = util.CheckIntegerBounds("node number", nodeNumber, 0, len(nodes)-1)
+			if err != nil {
+				return err
+			}
 
 			nodeIP := nodes[nodeNumber].IP
 			deployContractOut := deployContract(args[1], nodeIP)
@@ -229,7 +238,7 @@ Output: Deployed contract address
 			log.WithFields(log.Fields{"out": deployContractOut}).Debug("deployed contract")
 			addrList := re.FindAllString(deployContractOut, -1)
 			if len(addrList) < 2 {
-				util.PrintErrorFatal("There was an issue deploying the smart contract.")
+				return fmt.Errorf("There was an issue deploying the smart contract.")
 			}
 			err = addContract(Contract{
 				DeployedNodeAddress: addrList[0],
@@ -237,9 +246,10 @@ Output: Deployed contract address
 				ContractAddress:     addrList[1],
 			})
 			if err != nil {
-				util.PrintErrorFatal(err)
+				return err
 			}
 		}
+		return nil
 	},
 }
 
@@ -250,15 +260,20 @@ var gethConsole = &cobra.Command{
 Console will log into the geth console.
 
 Response: stdout of geth console`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 1)
-		nodes := GetNodes()
-		nodeNumber := util.CheckAndConvertInt(args[0], "node number")
-		util.CheckIntegerBounds(cmd, "node number", nodeNumber, 0, len(nodes)-1)
-
-		log.Fatal(unix.Exec("/usr/bin/ssh", []string{"ssh", "-i", conf.SSHPrivateKey, "-o", "StrictHostKeyChecking no",
-			"-o", "UserKnownHostsFile=/dev/null", "-o", "PasswordAuthentication no", "-o", "ConnectTimeout=10", "-y",
-			"root@" + fmt.Sprintf(nodes[nodeNumber].IP), "-t", "geth", "attach", "/geth/geth.ipc"}, os.Environ()))
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		nodes, err := selectNodes(args[0])
+		if err != nil {
+			return err
+		}
+		node, err := nodes.single()
+		if err != nil {
+			return err
+		}
+		return sshInteractive(nodes.Nodes[node].IP, "geth attach /geth/geth.ipc")
 	},
 }
 
@@ -271,9 +286,12 @@ Get the transaction receipt by the tx hash
 Params: The transaction hash
 
 Response: JSON representation of the transaction receipt.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 1)
-		util.JsonRpcCallAndPrint("eth::get_transaction_receipt", args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("eth::get_transaction_receipt", args)
 	},
 }
 
@@ -284,9 +302,12 @@ var gethGetHashRateCmd = &cobra.Command{
 Get the current hash rate per node
 
 Response: The hash rate of a single node in the network`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 0, 1)
-		util.JsonRpcCallAndPrint("eth::get_hash_rate", []string{})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 1)
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("eth::get_hash_rate", []string{})
 	},
 }
 
@@ -300,13 +321,16 @@ Params: The number of transactions to retrieve
 
 Response: JSON object of transaction data`,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 1)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
 		num, err := strconv.Atoi(args[0])
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
-		util.JsonRpcCallAndPrint("eth::get_recent_sent_tx", []interface{}{num})
+		return util.JsonRpcCallAndPrint("eth::get_recent_sent_tx", []interface{}{num})
 	},
 }
 
@@ -145,150 +145,154 @@ Iperf will show the user network conditions and other data. This command will es
 Params: sending node, receiving node
 	`,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		var wg sync.WaitGroup
-
-		util.CheckArguments(cmd, args, 2, 2)
-		spinner := Spinner{}
-		spinner.SetText("Setting Up Iperf")
-		spinner.Run(100)
-
-		nodes := GetNodes()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 2, 2)
+		if err != nil {
+			return err
+		}
 
-		sendingNodeNumber := util.CheckAndConvertInt(args[0], "sending node number")
-		receivingNodeNumber := util.CheckAndConvertInt(args[1], "receiving node number")
+		sendingNodeNumber, err := util.CheckAndConvertInt(args[0], "sending node number")
+		if err != nil {
+			return err
+		}
+		receivingNodeNumber, err := util.CheckAndConvertInt(args[1], "receiving node number")
+		if err != nil {
+			return err
+		}
 
-		udpEnabled := util.GetBoolFlagValue(cmd, "udp")
-		dualEnabled := util.GetBoolFlagValue(cmd, "dualtest")
-		bw := util.GetStringFlagValue(cmd, "bandwidth")
+		udpEnabled, err := util.GetBoolFlagValue(cmd, "udp")
+		if err != nil {
+			return err
+		}
+		dualEnabled, err := util.GetBoolFlagValue(cmd, "dualtest")
+		if err != nil {
+			return err
+		}
+		bw, err := util.GetStringFlagValue(cmd, "bandwidth")
+		if err != nil {
+			return err
+		}
 		//testTime := util.GetStringFlagValue(cmd,"time")
-
-		util.CheckIntegerBounds(cmd, "sending node number", sendingNodeNumber, 0, len(nodes)-1)
-		util.CheckIntegerBounds(cmd, "receiving node number", receivingNodeNumber, 0, len(nodes)-1)
-
-		var outReader1 io.Reader
-		var outReader2 io.Reader
-		var awaitReaders sync.WaitGroup
-		awaitReaders.Add(2)
-		wg.Add(2)
-		// command to run iperf as a server
-		go func() {
-			defer wg.Done()
-
-			iPerfcmd := "iperf3 -s "
-
-			if udpEnabled {
-				iPerfcmd = iPerfcmd + "-u "
-			}
-
-			iPerfcmd = iPerfcmd + fmt.Sprintf(nodes[sendingNodeNumber].IP) + " -1"
-
-			client, err := util.NewSshClient(fmt.Sprintf(nodes[sendingNodeNumber].IP))
+		if bw != "" && udpEnabled {
+			_, err := strconv.Atoi(bw)
 			if err != nil {
-				util.PrintErrorFatal(err)
+				return util.NewValidationError("Invalid format given for bandwidth flag.")
 			}
-			defer client.Close()
+		}
 
-			//client.Run("pkill -9 iperf3") //Kill iperf if it is running
+		nodes, err := GetNodes()
+		if err != nil {
+			return err
+		}
+		err = util.CheckIntegerBounds("sending node number", sendingNodeNumber, 0, len(nodes)-1)
+		if err != nil {
+			return err
+		}
+		err = util.CheckIntegerBounds("receiving node number", receivingNodeNumber, 0, len(nodes)-1)
+		if err != nil {
+			return err
+		}
 
-			session, err := client.GetSession()
-			if err != nil {
-				util.PrintErrorFatal(err)
-			}
-			defer session.Close() //Open up a session
+		spinner := Spinner{}
+		spinner.SetText("Setting Up Iperf")
+		spinner.Run(100)
+		defer spinner.Kill()
 
-			modes := ssh.TerminalModes{
-				ssh.ECHO:          0,
-				ssh.TTY_OP_ISPEED: 14400, // input speed = 14.4kbaud
-				ssh.TTY_OP_OSPEED: 14400, // output speed = 14.4kbaud
-			}
+		// command to run iperf as a server
+		serverCmd := "iperf3 -s "
+		if udpEnabled {
+			serverCmd = serverCmd + "-u "
+		}
+		serverCmd = serverCmd + fmt.Sprintf(nodes[sendingNodeNumber].IP) + " -1"
 
-			if err := session.RequestPty("xterm", 40, 80, modes); err != nil {
-				util.PrintErrorFatal(err)
-			}
+		// command to run iperf as a client
+		clientCmd := "iperf3 -c "
+		if udpEnabled {
+			clientCmd = clientCmd + " -u "
+		}
+		if bw != "" && udpEnabled {
+			clientCmd = clientCmd + " -b " + bw
+		} else if bw != "" && !udpEnabled {
+			util.Print("udp needs to be enabled to set bandwidth.")
+		}
+		if dualEnabled {
+			clientCmd = clientCmd + " -d "
+		}
+		clientCmd = clientCmd + fmt.Sprintf(nodes[sendingNodeNumber].IP)
 
-			outReader1, err = session.StdoutPipe()
-			if err != nil {
-				util.PrintErrorFatal(err)
-			}
-			awaitReaders.Done()
-			//go CaptureAndOutput(outReader)
+		server, err := util.NewSshClient(fmt.Sprintf(nodes[sendingNodeNumber].IP))
+		if err != nil {
+			return err
+		}
+		defer server.Close()
+		serverSession, outReader1, err := iperfSession(server)
+		if err != nil {
+			return err
+		}
+		defer serverSession.Close()
 
-			err = session.Start(iPerfcmd)
-			if err != nil {
-				util.PrintErrorFatal(err)
-			}
-			session.Wait()
+		client, err := util.NewSshClient(fmt.Sprintf(nodes[receivingNodeNumber].IP))
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		clientSession, outReader2, err := iperfSession(client)
+		if err != nil {
+			return err
+		}
+		defer clientSession.Close()
 
-		}()
+		err = serverSession.Start(serverCmd)
+		if err != nil {
+			return err
+		}
+		time.Sleep(500 * time.Millisecond)
+		spinner.Kill()
+		err = clientSession.Start(clientCmd)
+		if err != nil {
+			return err
+		}
 
+		go CaptureAndDisplayTogether(outReader1, outReader2, 3, "SERVER", "CLIENT")
+		var wg sync.WaitGroup
+		wg.Add(2)
 		go func() {
-			// command to run iperf as a client
-			time.Sleep(500 * time.Millisecond)
 			defer wg.Done()
+			serverSession.Wait()
+		}()
+		go func() {
+			defer wg.Done()
+			clientSession.Wait()
+		}()
+		wg.Wait()
+		return nil
+	},
+}
 
-			iPerfcmd := "iperf3 -c "
-			if udpEnabled {
-				iPerfcmd = iPerfcmd + " -u "
-			}
-			if bw != "" && udpEnabled {
-				_, err := strconv.Atoi(bw)
-				if err != nil {
-					util.PrintErrorFatal("Invalid format given for bandwidth flag.")
-					return
-				}
-				iPerfcmd = iPerfcmd + " -b " + bw
-			} else if bw != "" && !udpEnabled {
-				util.Print("udp needs to be enabled to set bandwidth.")
-			}
-			if dualEnabled {
-				iPerfcmd = iPerfcmd + " -d "
-			}
-
-			iPerfcmd = iPerfcmd + fmt.Sprintf(nodes[sendingNodeNumber].IP)
-
-			client, err := util.NewSshClient(fmt.Sprintf(nodes[receivingNodeNumber].IP))
-			if err != nil {
-				util.PrintErrorFatal(err)
-			}
-			defer client.Close()
-
-			//client.Run("pkill -9 iperf3")
-			spinner.Kill()
-			session, err := client.GetSession()
-			if err != nil {
-				util.PrintErrorFatal(err)
-			}
-			defer session.Close() //Open up a session
-
-			modes := ssh.TerminalModes{
-				ssh.ECHO:          0,
-				ssh.TTY_OP_ISPEED: 14400, // input speed = 14.4kbaud
-				ssh.TTY_OP_OSPEED: 14400, // output speed = 14.4kbaud
-			}
+// iperfSession opens a session with a pty on the node, giving the session and its output
+func iperfSession(client *util.SshClient) (*ssh.Session, io.Reader, error) {
+	session, err := client.GetSession()
+	if err != nil {
+		return nil, nil, err
+	}
 
-			if err := session.RequestPty("xterm", 40, 80, modes); err != nil {
-				util.PrintErrorFatal(err)
-			}
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          0,
+		ssh.TTY_OP_ISPEED: 14400, // input speed = 14.4kbaud
+		ssh.TTY_OP_OSPEED: 14400, // output speed = 14.4kbaud
+	}
 
-			outReader2, err = session.StdoutPipe()
-			if err != nil {
-				util.PrintErrorFatal(err)
-			}
-			awaitReaders.Done()
-			awaitReaders.Wait()
-			//go CaptureAndOutput(outReader)
+	if err := session.RequestPty("xterm", 40, 80, modes); err != nil {
+		session.Close()
+		return nil, nil, err
+	}
 
-			err = session.Start(iPerfcmd)
-			if err != nil {
-				util.PrintErrorFatal(err)
-			}
-			session.Wait()
-		}()
-		awaitReaders.Wait()
-		go CaptureAndDisplayTogether(outReader1, outReader2, 3, "SERVER", "CLIENT")
-		wg.Wait()
-	},
+	out, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, nil, err
+	}
+	return session, out, nil
 }
 
 func init() {
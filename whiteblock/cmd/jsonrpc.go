@@ -2,19 +2,27 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
 	"github.com/whiteblock/cli/whiteblock/util"
+	"strconv"
 )
 
 var jsonrpcCall = &cobra.Command{
-	Use:   "jsonrpc <node> <command> [args..]",
+	Use:   "jsonrpc <nodes> <command> [args..]",
 	Short: "send a json rpc call",
-	Long:  "\nSend a json rpc call to a node.\n",
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 2, util.NoMaxArgs)
-		nodes := GetNodes()
-		nodeNumber := util.CheckAndConvertInt(args[0], "node number")
-		util.CheckIntegerBounds(cmd, "node number", nodeNumber, 0, len(nodes)-1)
-		util.JsonRpcCallAndPrint("jsonrpc_call", util.ArgsToJSON(args))
+	Long:  "\nSend a json rpc call to each of the nodes.\n\n" + selector.Usage + "\n",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 2, util.NoMaxArgs)
+		if err != nil {
+			return err
+		}
+		nodes, err := selectNodes(args[0])
+		if err != nil {
+			return err
+		}
+		return nodes.callOnNodes("jsonrpc_call", func(node int) interface{} {
+			return util.ArgsToJSON(append([]string{strconv.Itoa(node)}, args[1:]...))
+		})
 	},
 }
 
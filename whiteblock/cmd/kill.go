@@ -2,21 +2,32 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
-	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
 	"github.com/whiteblock/cli/whiteblock/util"
+	"strconv"
 )
 
 var killCmd = &cobra.Command{
 	Aliases: []string{},
-	Use:     "kill <node>",
+	Use:     "kill <nodes>",
 	Short:   "Raise SIGINT to a node's main process and wait for it to die",
-	Long: `Sends SIGINT to the node's main process, and continue to query the state of that 
+	Long: `Sends SIGINT to the main process of each of the nodes, and continue to query the state of that 
 	process until it dies. 
-`,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 1)
-		util.JsonRpcCallAndPrint("kill_node", []interface{}{build.GetPreviousBuildID(), args[0]})
+` + selector.Usage,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		nodes, err := selectNodes(args[0])
+		if err != nil {
+			return err
+		}
+		return nodes.callOnNodes("kill_node", func(node int) interface{} {
+			return []interface{}{nodes.TestnetID, strconv.Itoa(node)}
+		})
 	},
 }
 
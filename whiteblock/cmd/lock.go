@@ -13,25 +13,22 @@ var acquireCmd = &cobra.Command{
 	Long: `
 This call will block until a unique lock has been acquired on the endpoint
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		once, err := cmd.Flags().GetBool("once")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		conf.RPCRetries = 1
 		if once {
-			util.JsonRpcCallAndPrint("lock", []interface{}{})
-			return
+			return util.JsonRpcCallAndPrint("lock", []interface{}{})
 		}
 		for {
 			res, err := util.JsonRpcCall("lock", []interface{}{})
 			if err == nil {
-				util.Print(res)
-				break
+				return util.Print(res)
 			}
 			time.Sleep(time.Second * 10)
 		}
-
 	},
 }
 
@@ -39,8 +36,8 @@ var forceUnlockCmd = &cobra.Command{
 	Use:   "force-unlock",
 	Short: "Forces an unlock",
 	Long:  "\nForces an unlock, might be dangerous, but is useful if it is stuck in lock mode\n",
-	Run: func(cmd *cobra.Command, args []string) {
-		util.JsonRpcCallAndPrint("unlock", []interface{}{})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return util.JsonRpcCallAndPrint("unlock", []interface{}{})
 	},
 }
 
@@ -21,7 +21,11 @@ func GetProfileFromJwt(jwt string) (Profile, error) {
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", jwt))
 	req.Close = true
-	resp, err := http.DefaultClient.Do(req)
+	httpClient, err := util.HTTPClient()
+	if err != nil {
+		return out, err
+	}
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return out, err
 	}
@@ -44,8 +48,11 @@ var loginCmd = &cobra.Command{
 	Use:   "login <jwt> [biome id]",
 	Short: "Authorize the cli using jwt ",
 	Long:  "\nGives the user the ability to specify a jwt, within a file, to be used for authentication\n Can be given a file path or a jwt\n",
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 2)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 2)
+		if err != nil {
+			return err
+		}
 
 		jwt, err := ioutil.ReadFile(args[0])
 		if err != nil {
@@ -53,8 +60,7 @@ var loginCmd = &cobra.Command{
 		}
 		prof, err := GetProfileFromJwt(string(jwt))
 		if err != nil {
-			util.PrintStringError("Given jwt is invalid")
-			util.PrintErrorFatal(err)
+			return fmt.Errorf("Given jwt is invalid: %v", err)
 		}
 		util.Set("jwt", string(jwt))
 		util.Set("profile", prof)
@@ -69,11 +75,12 @@ var loginCmd = &cobra.Command{
 			util.Delete("jwt")
 			util.Delete("profile")
 			util.Delete("biome")
-			util.PrintErrorFatal(err)
+			return err
 		}
 
 		util.Print("Login Success")
 		fmt.Printf("Connected to endpoint: %s\n", conf.ServerAddr)
+		return nil
 	},
 }
 
@@ -82,11 +89,11 @@ var logoutCmd = &cobra.Command{
 	Use:     "logoff",
 	Short:   "Remove all auth stored",
 	Long:    "\nDeletes all stored auth\n",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		util.Delete("jwt")
 		util.Delete("profile")
 		util.Delete("biome")
-		util.Print("You have been logged off successfully")
+		return util.Print("You have been logged off successfully")
 	},
 }
 
@@ -3,7 +3,6 @@ package cmd
 import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
-	"github.com/whiteblock/cli/whiteblock/util"
 )
 
 var (
@@ -18,7 +17,7 @@ var manCmd = &cobra.Command{
 command-line interface.  By default, it creates the man page files
 in the "man" directory under the current directory.`,
 
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		header := &doc.GenManHeader{
 			Section: "1",
 			Title:   "whiteblock",
@@ -26,10 +25,7 @@ in the "man" directory under the current directory.`,
 
 		cmd.Root().DisableAutoGenTag = true
 
-		err := doc.GenManTree(cmd.Root(), header, mandir)
-		if err != nil {
-			util.PrintErrorFatal(err)
-		}
+		return doc.GenManTree(cmd.Root(), header, mandir)
 	},
 }
 
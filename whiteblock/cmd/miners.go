@@ -2,7 +2,10 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
 	"github.com/whiteblock/cli/whiteblock/util"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,25 +20,32 @@ Send commands pertaining to mining. This will be blockchain specific and will on
 }
 
 var minerStartCmd = &cobra.Command{
-	Use:   "start [node 1 number] [node 2 number]...",
+	Use:   "start [nodes]...",
 	Short: "Start Mining",
 	Long: `
 Send the start mining signal to nodes, may take a while to take effect due to DAG generation. If no arguments are given, all nodes will begin mining.
 
-Params: A list of the nodes to start mining or None for all nodes
+Params: The nodes to start mining or None for all nodes
 
-Response: The number of nodes which successfully received the signal to start mining`,
-	Run: func(cmd *cobra.Command, args []string) {
+Response: The number of nodes which successfully received the signal to start mining
+
+` + selector.Usage,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nodes, err := minerNodes(args)
+		if err != nil {
+			return err
+		}
 		spinner := &Spinner{txt: "Starting the miner", die: false}
 		spinner.Run(100)
+		defer spinner.Kill()
 
-		_, err := util.JsonRpcCall("start_mining", args)
+		_, err = util.JsonRpcCall("start_mining", nodes)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		noCheck, err := cmd.Flags().GetBool("no-hang")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		if noCheck {
 			util.Print("Miner is starting")
@@ -45,7 +55,7 @@ Response: The number of nodes which successfully received the signal to start mi
 			//fmt.Printf("\rDAG is being generated...")
 			res, err := util.JsonRpcCall("get_block_number", []string{})
 			if err != nil {
-				util.PrintErrorFatal(err)
+				return err
 			}
 			blocknum := int(res.(float64))
 			if blocknum > 1 {
@@ -56,24 +66,46 @@ Response: The number of nodes which successfully received the signal to start mi
 		//util.Print("\rDAG has been successfully generated.")
 		spinner.Kill()
 		time.Sleep(time.Millisecond * 100)
-		util.Print("\rDAG has been successfully generated.")
+		return util.Print("\rDAG has been successfully generated.")
 	},
 }
 
 var minerStopCmd = &cobra.Command{
-	Use:   "stop [node 1 number] [node 2 number]...",
+	Use:   "stop [nodes]...",
 	Short: "Stop mining",
 	Long: `
 Send the stop mining signal to nodes
 
-Params: A list of the nodes to stop mining or None for all nodes
+Params: The nodes to stop mining or None for all nodes
+
+Response: The number of nodes which successfully received the signal to stop mining
 
-Response: The number of nodes which successfully received the signal to stop mining`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.JsonRpcCallAndPrint("stop_mining", args)
+` + selector.Usage,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nodes, err := minerNodes(args)
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("stop_mining", nodes)
 	},
 }
 
+// minerNodes gets the indexes of the nodes selected by args, or none to mean all of the nodes
+func minerNodes(args []string) ([]string, error) {
+	out := []string{}
+	if len(args) == 0 {
+		return out, nil
+	}
+	nodes, err := selectNodes(strings.Join(args, ","))
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes.Selected {
+		out = append(out, strconv.Itoa(node))
+	}
+	return out, nil
+}
+
 func init() {
 	minerStartCmd.Flags().Bool("no-hang", false, "Do not wait for the blocks to start mining before returning")
 	minerCmd.AddCommand(minerStartCmd, minerStopCmd)
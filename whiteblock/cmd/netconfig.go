@@ -1,11 +1,22 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/client"
 	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/cmd/netconfig"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
+	"github.com/whiteblock/cli/whiteblock/rpc"
 	"github.com/whiteblock/cli/whiteblock/util"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 )
 
 var (
@@ -25,45 +36,117 @@ Netconfig will introduce persisting network conditions for testing.
 	Run: util.PartialCommand,
 }
 
-var netconfigSetCmd = &cobra.Command{
-	Use:     "set <node> [flags]",
-	Aliases: []string{"config", "configure"},
-	Short:   "Set network conditions",
-	Long: `
-Netconfig set will introduce persisting network conditions for testing to a specific node. Please indicate the proper flags with the amount to set.
-`,
-	Run: func(cmd *cobra.Command, args []string) {
-
-		delay := util.GetIntFlagValue(cmd, "delay")
-		rate := util.GetIntFlagValue(cmd, "bandwidth")
-		limit := util.GetIntFlagValue(cmd, "limit")
-
-		util.CheckArguments(cmd, args, 1, 1)
-
-		netInfo := make(map[string]interface{})
-		node := util.CheckAndConvertInt(args[0], "node")
-
-		netInfo["node"] = node
-		if limit != 1000 {
-			netInfo["limit"] = limit
+// flagConditions gets the network conditions given by the preset and flags of cmd, with the
+// flags taking precedence over the preset
+func flagConditions(cmd *cobra.Command) (netconfig.Conditions, error) {
+	conditions := netconfig.Conditions{}
+	preset, err := util.GetStringFlagValue(cmd, "preset")
+	if err != nil {
+		return conditions, err
+	}
+	if len(preset) > 0 {
+		conditions, err = netconfig.FindPreset(preset)
+		if err != nil {
+			return conditions, err
 		}
-		if lossFlag > 0.0 {
-			netInfo["loss"] = lossFlag
+	}
+	flags := cmd.Flags()
+	for flag, value := range map[string]*util.Duration{
+		"delay":  &conditions.Delay,
+		"jitter": &conditions.Jitter,
+	} {
+		if flags.Changed(flag) {
+			ms, err := util.GetIntFlagValue(cmd, flag)
+			if err != nil {
+				return conditions, err
+			}
+			*value = util.Duration(time.Duration(ms) * time.Millisecond)
 		}
-		if delay > 0 {
-			netInfo["delay"] = delay * 1000
+	}
+	if flags.Changed("bandwidth") {
+		rate, err := util.GetIntFlagValue(cmd, "bandwidth")
+		if err != nil {
+			return conditions, err
 		}
+		conditions.Rate = ""
 		if rate > 0 {
-			rateStr := strconv.Itoa(rate)
-			rateStr = rateStr + "mbps"
-			netInfo["rate"] = rateStr
+			conditions.Rate = strconv.Itoa(rate) + "mbps"
+		}
+	}
+	if flags.Changed("limit") {
+		conditions.Limit, err = util.GetIntFlagValue(cmd, "limit")
+		if err != nil {
+			return conditions, err
+		}
+	}
+	if flags.Changed("distribution") {
+		conditions.Distribution, err = util.GetStringFlagValue(cmd, "distribution")
+		if err != nil {
+			return conditions, err
 		}
-		networkConf := []interface{}{
-			build.GetPreviousBuildID(),
-			netInfo,
+	}
+	for flag, value := range map[string]*float64{
+		"loss":        &conditions.Loss,
+		"correlation": &conditions.Correlation,
+		"duplicate":   &conditions.Duplicate,
+		"corrupt":     &conditions.Corrupt,
+		"reorder":     &conditions.Reorder,
+	} {
+		if flags.Changed(flag) {
+			*value, err = util.GetFloat64FlagValue(cmd, flag)
+			if err != nil {
+				return conditions, err
+			}
 		}
+	}
+	err = conditions.Validate()
+	if err != nil {
+		return conditions, util.ValidationError{Err: err}
+	}
+	return conditions, nil
+}
+
+// addConditionFlags adds the flags read by flagConditions to cmd
+func addConditionFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&limitFlag, "limit", "m", 1000, "sets packet limit")
+	cmd.Flags().Float64VarP(&lossFlag, "loss", "l", 0.0, "Specifies the amount of packet loss to add [%]")
+	cmd.Flags().IntVarP(&delayFlag, "delay", "d", 0, "Specifies the latency to add [ms]")
+	cmd.Flags().IntVarP(&rateFlag, "bandwidth", "b", 0, "Specifies the bandwidth of the network in mbps")
+	cmd.Flags().Int("jitter", 0, "Specifies the variation of the latency, requires --delay [ms]")
+	cmd.Flags().Float64("correlation", 0.0, "Specifies how much each delay depends on the one before it [%]")
+	cmd.Flags().String("distribution", "", "Specifies the distribution of the delay, one of "+
+		strings.Join(netconfig.Distributions, ", "))
+	cmd.Flags().Float64("duplicate", 0.0, "Specifies the amount of packets to duplicate [%]")
+	cmd.Flags().Float64("corrupt", 0.0, "Specifies the amount of packets to corrupt [%]")
+	cmd.Flags().Float64("reorder", 0.0, "Specifies the amount of packets to send ahead of the delayed ones [%]")
+	cmd.Flags().String("preset", "", "use the conditions of a preset, see netconfig presets")
+}
 
-		util.JsonRpcCallAndPrint("netem", networkConf)
+var netconfigSetCmd = &cobra.Command{
+	Use:     "set <nodes> [flags]",
+	Aliases: []string{"config", "configure"},
+	Short:   "Set network conditions",
+	Long: `
+Netconfig set will introduce persisting network conditions for testing to each of the given nodes. Please indicate the proper flags with the amount to set.
+A preset may be given with --preset, in which case the other flags given replace its values.
+
+` + selector.Usage,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		conditions, err := flagConditions(cmd)
+		if err != nil {
+			return err
+		}
+		nodes, err := selectNodes(args[0])
+		if err != nil {
+			return err
+		}
+		return nodes.callOnNodes("netem", func(node int) interface{} {
+			return []interface{}{nodes.TestnetID, conditions.Netem(node)}
+		})
 	},
 }
 
@@ -73,36 +156,62 @@ var netconfigAllCmd = &cobra.Command{
 	Short:   "Set network conditions",
 	Long: `
 Netconfig all will introduce persisting network conditions for testing to all nodes. Please indicate the proper flags with the amount to set.
+A preset may be given with --preset, in which case the other flags given replace its values.
 	`,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 0, 0)
-		netInfo := make(map[string]interface{})
-
-		delay := util.GetIntFlagValue(cmd, "delay")
-		rateFlag := util.GetIntFlagValue(cmd, "bandwidth")
-		limit := util.GetIntFlagValue(cmd, "limit")
-		if limit != 1000 {
-			netInfo["limit"] = limit
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
 		}
-		if lossFlag > 0.0 {
-			netInfo["loss"] = lossFlag
+		conditions, err := flagConditions(cmd)
+		if err != nil {
+			return err
 		}
-		if delay > 0 {
-			netInfo["delay"] = (delay * 1000) / 2
-		}
-		if rateFlag > 0 {
-			rate := strconv.Itoa(rateFlag)
-			rate = rate + "mbps"
-			netInfo["rate"] = rate
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
 		}
+		return util.JsonRpcCallAndPrint("netem_all", []interface{}{testnetID, conditions.NetemAll()})
+	},
+}
 
-		networkConf := []interface{}{
-			build.GetPreviousBuildID(),
-			netInfo,
-		}
+var netconfigPresetsCmd = &cobra.Command{
+	Use:     "presets",
+	Aliases: []string{"preset"},
+	Short:   "List the presets of network conditions",
+	Long: `
+Lists the presets which may be given to netconfig set and netconfig all with --preset. Presets are read
+from presets.yaml in the store directory, or the file given by NETEM_PRESETS, where they replace the
+builtin presets of the same name.
 
-		util.JsonRpcCallAndPrint("netem_all", networkConf)
+Example presets.yaml:
+	lossy-mobile: {delay: 80ms, jitter: 40ms, distribution: normal, loss: 3, duplicate: 0.5}
+	datacenter: {delay: 1ms, jitter: 200us, rate: 10gbit}
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
+		}
+		presets, err := netconfig.LoadPresets(netconfig.PresetsPath())
+		if err != nil {
+			return err
+		}
+		if util.IsStructuredOutput() && util.OutputFormat() != util.OutputTable {
+			util.Print(presets)
+			return nil
+		}
+		out := []map[string]interface{}{}
+		for _, preset := range presets {
+			out = append(out, map[string]interface{}{
+				"name":       preset.Name,
+				"conditions": preset.Conditions.String(),
+				"source":     preset.Source,
+			})
+		}
+		util.PrintTable(out, "name", "conditions", "source")
+		return nil
 	},
 }
 
@@ -114,9 +223,16 @@ var netconfigClearCmd = &cobra.Command{
 Netconfig clear will reset all emulation and turn off all persisiting network conditions. 
 	`,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 0, 0)
-		util.JsonRpcCallAndPrint("netem_delete", []interface{}{build.GetPreviousBuildID()})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("netem_delete", []interface{}{testnetID})
 	},
 }
 
@@ -128,12 +244,23 @@ var netconfigGetCmd = &cobra.Command{
 Netconfig get will fetch the current network conditions
 	`,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 0, 0)
-		util.JsonRpcCallAndPrint("netem_get", []interface{}{build.GetPreviousBuildID()})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("netem_get", []interface{}{testnetID}, netemColumns...)
 	},
 }
 
+// netemColumns are the fields of the network conditions shown by --output table
+var netemColumns = []string{"node", "dst", "limit", "loss", "delay", "rate", "jitter", "correlation",
+	"distribution", "duplicate", "corrupt", "reorder"}
+
 var netconfigGetDisconnectsCmd = &cobra.Command{
 	Use:     "disconnects [node]",
 	Aliases: []string{"blocked", "disconnected"},
@@ -142,13 +269,20 @@ var netconfigGetDisconnectsCmd = &cobra.Command{
 Get a json array of the connections which are blocked. 
 	`,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 0, 1)
-		outArgs := []interface{}{build.GetPreviousBuildID()}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 1)
+		if err != nil {
+			return err
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		outArgs := []interface{}{testnetID}
 		if len(args) == 1 {
 			outArgs = append(outArgs, args[0])
 		}
-		util.JsonRpcCallAndPrint("get_outages", outArgs)
+		return util.JsonRpcCallAndPrint("get_outages", outArgs)
 	},
 }
 
@@ -156,11 +290,145 @@ var netconfigGetPartitionsCmd = &cobra.Command{
 	Use: "partitions",
 	//Aliases: []string{"blocked", "disconnected"},
 	Short: "Get the network partitions",
-	Long:  "\nGets the current network partitions\n",
+	Long:  "\nGets the current network partitions, as the groups of nodes which are able to reach each other\n",
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 0, 1)
-		util.JsonRpcCallAndPrint("get_partitions", []interface{}{build.GetPreviousBuildID()})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 1)
+		if err != nil {
+			return err
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		res, err := rpcClient().GetPartitions(context.Background(), testnetID)
+		if err != nil {
+			return err
+		}
+		groups := []netconfig.NodeList{}
+		data, err := json.Marshal(res)
+		if util.IsStructuredOutput() || err != nil || json.Unmarshal(data, &groups) != nil || len(groups) == 0 {
+			util.Print(res)
+			return nil
+		}
+		fmt.Println(netconfig.RenderGroups(groups))
+		return nil
+	},
+}
+
+var netconfigGetLinksCmd = &cobra.Command{
+	Use:   "links",
+	Short: "Get the network conditions of the links",
+	Long: `
+Gets the network conditions of the links between each pair of nodes, in the form taken by netconfig links.
+With --output yaml, the output may be saved and applied again.
+`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		links, err := rpcClient().NetemGetLinks(context.Background(), testnetID)
+		if err != nil {
+			return err
+		}
+		if util.OutputFormat() == util.OutputTable {
+			printLinks(links)
+			return nil
+		}
+		util.Print(netconfig.LinksOf(links))
+		return nil
+	},
+}
+
+// printLinks prints the conditions of each link as a row of a table
+func printLinks(links []client.Link) {
+	out := []map[string]interface{}{}
+	for _, link := range links {
+		out = append(out, map[string]interface{}{
+			"from":       link.From,
+			"to":         link.To,
+			"conditions": netconfig.ConditionsOf(link).String(),
+		})
+	}
+	util.PrintTable(out, "from", "to", "conditions")
+}
+
+var netconfigLinksCmd = &cobra.Command{
+	Use:     "links <file>",
+	Aliases: []string{"link"},
+	Short:   "Set the network conditions of the links between nodes",
+	Long: `
+Netconfig links sets the network conditions of the links between pairs of nodes, replacing those set before.
+The conditions of a link only apply to the traffic from one node to the other, unless both is given. Links
+may be given as a list, as a matrix of them or both, between nodes such as "0-2,5" or named groups of nodes.
+Where a link is given more than once, the last conditions given are used, with the matrix before the list.
+
+The links are shown by netconfig get links, and removed by --clear or netconfig clear.
+
+Example:
+	groups:
+	  us: 0-3
+	  eu: 4-7
+	  asia: 8-9
+	matrix:
+	  us: {eu: {delay: 120ms}, asia: {delay: 180ms, loss: 0.5}}
+	  eu: {us: {delay: 110ms}, asia: {delay: 200ms}}
+	links:
+	  - {from: asia, to: us, delay: 170ms, rate: 100mbps}
+	  - {from: 0, to: 9, delay: 300ms, both: true}
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clear, err := util.GetBoolFlagValue(cmd, "clear")
+		if err != nil {
+			return err
+		}
+		if clear {
+			err = util.CheckArguments(args, 0, 0)
+		} else {
+			err = util.CheckArguments(args, 1, 1)
+		}
+		if err != nil {
+			return err
+		}
+		links := []client.Link{}
+		if !clear {
+			spec, err := netconfig.LoadLinks(args[0])
+			if err != nil {
+				return err
+			}
+			links, err = spec.Expand()
+			if err != nil {
+				return err
+			}
+		}
+		dryRun, err := util.GetBoolFlagValue(cmd, "dry-run")
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			printLinks(links)
+			return nil
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		err = rpcClient().NetemLinks(context.Background(), testnetID, links)
+		if err != nil {
+			return err
+		}
+		if clear {
+			util.Print("Removed the network conditions of the links")
+		} else {
+			util.Print(fmt.Sprintf("Set the network conditions of %d links", len(links)))
+		}
+		return nil
 	},
 }
 
@@ -172,12 +440,24 @@ var netconfigUncutCmd = &cobra.Command{
 Allow the given pair of nodes to connect
 	`,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 2, 2)
-		util.JsonRpcCallAndPrint("remove_outage", []interface{}{
-			build.GetPreviousBuildID(),
-			util.CheckAndConvertInt(args[0], "node1"),
-			util.CheckAndConvertInt(args[1], "node2")})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 2, 2)
+		if err != nil {
+			return err
+		}
+		node1, err := util.CheckAndConvertInt(args[0], "node1")
+		if err != nil {
+			return err
+		}
+		node2, err := util.CheckAndConvertInt(args[1], "node2")
+		if err != nil {
+			return err
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("remove_outage", []interface{}{testnetID, node1, node2})
 	},
 }
 
@@ -189,12 +469,24 @@ var netconfigCutCmd = &cobra.Command{
 Prevent the given pair of nodes from connecting
 	`,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 2, 2)
-		util.JsonRpcCallAndPrint("make_outage", []interface{}{
-			build.GetPreviousBuildID(),
-			util.CheckAndConvertInt(args[0], "node1"),
-			util.CheckAndConvertInt(args[1], "node2")})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 2, 2)
+		if err != nil {
+			return err
+		}
+		node1, err := util.CheckAndConvertInt(args[0], "node1")
+		if err != nil {
+			return err
+		}
+		node2, err := util.CheckAndConvertInt(args[1], "node2")
+		if err != nil {
+			return err
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("make_outage", []interface{}{testnetID, node1, node2})
 	},
 }
 
@@ -206,12 +498,20 @@ var netconfigPartitionCmd = &cobra.Command{
 Partition the given nodes from the rest of the network
 	`,
 
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		nodes := []int{}
 		for i, arg := range args {
-			nodes = append(nodes, util.CheckAndConvertInt(arg, fmt.Sprintf("argument %d", i)))
+			node, err := util.CheckAndConvertInt(arg, fmt.Sprintf("argument %d", i))
+			if err != nil {
+				return err
+			}
+			nodes = append(nodes, node)
 		}
-		util.JsonRpcCallAndPrint("partition_outage", []interface{}{build.GetPreviousBuildID(), nodes})
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("partition_outage", []interface{}{testnetID, nodes})
 	},
 }
 
@@ -222,27 +522,447 @@ var netconfigMarryCmd = &cobra.Command{
 Remove any outages and allow connections between all nodes
 	`,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 0, 0)
-		util.JsonRpcCallAndPrint("remove_all_outages", []interface{}{build.GetPreviousBuildID()})
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		return util.JsonRpcCallAndPrint("remove_all_outages", []interface{}{testnetID})
+	},
+}
+
+// testnetNodeCount gets the current testnet along with its number of nodes
+func testnetNodeCount() (string, int, error) {
+	testnetID, err := build.GetPreviousBuildID()
+	if err != nil {
+		return "", 0, err
+	}
+	nodes, err := rpcClient().Nodes(context.Background(), testnetID)
+	if err != nil {
+		return "", 0, err
+	}
+	return testnetID, len(nodes), nil
+}
+
+// applyOutages replaces the outages of the testnet with the given outages and prints the
+// resulting partitions, or prints which nodes each node would reach with --dry-run
+func applyOutages(cmd *cobra.Command, testnetID string, n int, outages []netconfig.Outage) error {
+	groups := netconfig.Groups(n, outages)
+	dryRun, err := util.GetBoolFlagValue(cmd, "dry-run")
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		if util.IsStructuredOutput() {
+			util.Print(map[string]interface{}{"outages": outages, "groups": groups, "peers": netconfig.Peers(n, outages)})
+			return nil
+		}
+		fmt.Printf("%d outages, each node would reach:\n%s\n", len(outages), netconfig.RenderPeers(netconfig.Peers(n, outages)))
+		fmt.Println(netconfig.RenderGroups(groups))
+		return nil
+	}
+	ctx, cancel := rpc.InterruptContext(context.Background())
+	defer cancel()
+	err = netconfig.ApplyOutages(ctx, rpcClient(), testnetID, outages)
+	if err != nil {
+		return err
+	}
+	if util.IsStructuredOutput() {
+		util.Print(map[string]interface{}{"outages": outages, "groups": groups})
+		return nil
+	}
+	fmt.Println(netconfig.RenderGroups(groups))
+	return nil
+}
+
+var netconfigSplitCmd = &cobra.Command{
+	Use:   "split <nodes> / <nodes>...",
+	Short: "Split the network into groups of nodes",
+	Long: `
+Netconfig split divides the network into groups of nodes, where each group is only able to reach the nodes
+within it. The groups are separated by slashes and the nodes not given are able to reach every node. Any
+outages made before are replaced.
+
+Example:
+	whiteblock netconfig split 0,1,2 / 3,4 / 5-7
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, util.NoMaxArgs)
+		if err != nil {
+			return err
+		}
+		groups, err := netconfig.ParseGroups(args)
+		if err != nil {
+			return err
+		}
+		testnetID, n, err := testnetNodeCount()
+		if err != nil {
+			return err
+		}
+		outages, err := netconfig.Split(n, groups)
+		if err != nil {
+			return err
+		}
+		return applyOutages(cmd, testnetID, n, outages)
+	},
+}
+
+var netconfigTopologyCmd = &cobra.Command{
+	Use:   "topology <ring|line|star>",
+	Short: "Connect the nodes in a ring, line or star",
+	Long: `
+Netconfig topology cuts the connections between the nodes which are not next to each other in the given
+shape. In a ring each node reaches the nodes either side of it, in a line the same except that the first
+and last nodes are not connected, and in a star every node only reaches the center node. Any outages made
+before are replaced.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		testnetID, n, err := testnetNodeCount()
+		if err != nil {
+			return err
+		}
+		center, err := util.GetIntFlagValue(cmd, "center")
+		if err != nil {
+			return err
+		}
+		outages, err := netconfig.Topology(n, args[0], center)
+		if err != nil {
+			return err
+		}
+		return applyOutages(cmd, testnetID, n, outages)
+	},
+}
+
+var netconfigEclipseCmd = &cobra.Command{
+	Use:   "eclipse <node>",
+	Short: "Leave a node only able to reach the given nodes",
+	Long: `
+Netconfig eclipse cuts a node off from every node other than those given with --by, such as the nodes of
+an attacker. Without --by, the node is cut off from the whole network. Any outages made before are replaced.
+
+Example:
+	whiteblock netconfig eclipse 4 --by 7-9
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		node, err := strconv.Atoi(args[0])
+		if err != nil {
+			return util.NewValidationError(`invalid node "%s"`, args[0])
+		}
+		byFlag, err := util.GetStringFlagValue(cmd, "by")
+		if err != nil {
+			return err
+		}
+		by, err := netconfig.ParseNodeList(byFlag)
+		if err != nil {
+			return util.ValidationError{Err: err}
+		}
+		testnetID, n, err := testnetNodeCount()
+		if err != nil {
+			return err
+		}
+		outages, err := netconfig.Eclipse(n, node, by)
+		if err != nil {
+			return err
+		}
+		return applyOutages(cmd, testnetID, n, outages)
+	},
+}
+
+// describeSnapshot summarizes what a snapshot holds
+func describeSnapshot(snapshot netconfig.Snapshot) string {
+	links := 0
+	if snapshot.Links != nil {
+		expanded, err := snapshot.Links.Expand()
+		if err == nil {
+			links = len(expanded)
+		}
+	}
+	return fmt.Sprintf("%d nodes, %d with conditions, %d links and %d outages", snapshot.Nodes,
+		len(snapshot.Conditions), links, len(snapshot.Outages))
+}
+
+var netconfigSaveCmd = &cobra.Command{
+	Use:     "save <name>",
+	Aliases: []string{"snapshot"},
+	Short:   "Save the network state of the testnet",
+	Long: `
+Netconfig save takes a snapshot of the network conditions, links and outages of the testnet and keeps it
+under the given name, to be applied again with netconfig load. With --file, the snapshot is written to a
+yaml or json file instead, which may be shared and loaded on another machine.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		snapshot, err := netconfig.Capture(context.Background(), rpcClient(), testnetID)
+		if err != nil {
+			return err
+		}
+		snapshot.Name = args[0]
+
+		file, err := util.GetStringFlagValue(cmd, "file")
+		if err != nil {
+			return err
+		}
+		if len(file) == 0 {
+			err = netconfig.SaveSnapshot(*snapshot)
+		} else {
+			var data []byte
+			if strings.HasSuffix(file, ".json") {
+				data, err = json.MarshalIndent(snapshot, "", "  ")
+			} else {
+				data, err = util.MarshalYAML(snapshot)
+			}
+			if err == nil {
+				err = ioutil.WriteFile(file, data, 0644)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		util.Print(fmt.Sprintf("Saved %s with %s", snapshot.Name, describeSnapshot(*snapshot)))
+		return nil
+	},
+}
+
+var netconfigLoadCmd = &cobra.Command{
+	Use:     "load <name|file>",
+	Aliases: []string{"restore"},
+	Short:   "Apply a saved network state to the testnet",
+	Long: `
+Netconfig load applies a snapshot taken by netconfig save, replacing the network conditions, links and
+outages of the testnet. The snapshot is read from the file if one exists at the given path, and otherwise
+from the snapshots saved under the given name. The testnet need not be the one the snapshot was taken of,
+but must have the same number of nodes.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		var snapshot netconfig.Snapshot
+		if info, statErr := os.Stat(args[0]); statErr == nil && !info.IsDir() {
+			loaded, err := netconfig.LoadSnapshotFile(args[0])
+			if err != nil {
+				return err
+			}
+			snapshot = *loaded
+			if len(snapshot.Name) == 0 {
+				snapshot.Name = filepath.Base(args[0])
+			}
+		} else {
+			snapshot, err = netconfig.FindSnapshot(args[0])
+			if err != nil {
+				return err
+			}
+		}
+		dryRun, err := util.GetBoolFlagValue(cmd, "dry-run")
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			util.Print(snapshot)
+			return nil
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+		err = netconfig.Restore(context.Background(), rpcClient(), testnetID, snapshot)
+		if err != nil {
+			return err
+		}
+		util.Print(fmt.Sprintf("Loaded %s with %s", snapshot.Name, describeSnapshot(snapshot)))
+		return nil
+	},
+}
+
+var netconfigSnapshotsCmd = &cobra.Command{
+	Use:     "snapshots",
+	Aliases: []string{"saved"},
+	Short:   "List the saved network states",
+	Long: `
+Lists the snapshots saved by netconfig save.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
+		}
+		snapshots, err := netconfig.ListSnapshots()
+		if err != nil {
+			return err
+		}
+		out := []map[string]interface{}{}
+		for _, snapshot := range snapshots {
+			out = append(out, map[string]interface{}{
+				"name":     snapshot.Name,
+				"testnet":  snapshot.TestnetID,
+				"contents": describeSnapshot(snapshot),
+				"created":  snapshot.Created.Format(time.RFC3339),
+			})
+		}
+		util.PrintTable(out, "name", "testnet", "contents", "created")
+		return nil
+	},
+}
+
+var netconfigSnapshotsRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove", "delete"},
+	Short:   "Remove a saved network state",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		return netconfig.RemoveSnapshot(args[0])
+	},
+}
+
+var netconfigScheduleCmd = &cobra.Command{
+	Use:     "schedule <file>",
+	Aliases: []string{"timeline", "play"},
+	Short:   "Play a timeline of network conditions",
+	Long: `
+Netconfig schedule makes each change to the network given in the file at its time from the start. The
+network is left as the last change leaves it, unless --revert is given. On Ctrl-C, the network conditions
+and outages are removed.
+
+The changes are all, node, partition, cut, uncut, heal and clear. Nodes may be given as a list such as
+[0, 1] or as ranges such as "0-2,5".
+
+Example:
+	events:
+	  - at: 0s
+	    all: {delay: 50ms}
+	  - at: 60s
+	    partition: 0-2
+	  - at: 120s
+	    heal: true
+	  - at: 180s
+	    node: {nodes: 4, loss: 5}
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		schedule, err := netconfig.LoadSchedule(args[0])
+		if err != nil {
+			return err
+		}
+		dryRun, err := util.GetBoolFlagValue(cmd, "dry-run")
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			plan := []map[string]interface{}{}
+			for i, event := range schedule.Events {
+				plan = append(plan, map[string]interface{}{
+					"event":  i + 1,
+					"at":     time.Duration(event.At).String(),
+					"action": event.Action(),
+					"change": event.String(),
+				})
+			}
+			util.PrintTable(plan, "event", "at", "action", "change")
+			return nil
+		}
+		testnetID, err := build.GetPreviousBuildID()
+		if err != nil {
+			return err
+		}
+
+		player := netconfig.Player{Client: rpcClient(), TestnetID: testnetID}
+		total := len(schedule.Events)
+		start := time.Now()
+		if util.OutputFormat() == util.OutputDefault {
+			player.OnEvent = func(index int, event netconfig.Event) {
+				if util.IsTTY() {
+					fmt.Print("\r\033[K")
+				}
+				fmt.Printf("[%v] (%d/%d) %s\n", time.Since(start).Round(time.Second), index, total, event)
+			}
+			if util.IsTTY() {
+				player.OnWait = func(index int, next netconfig.Event, remaining time.Duration) {
+					fmt.Printf("\r\033[K(%d/%d) %s in %v", index, total, next, remaining.Round(time.Second))
+				}
+			}
+		}
+
+		ctx, cancel := rpc.InterruptContext(context.Background())
+		defer cancel()
+		err = player.Play(ctx, schedule)
+		if ctx.Err() != nil {
+			util.Print("\nInterrupted, removing the network conditions and outages")
+			revertErr := player.Revert(context.Background())
+			if revertErr != nil {
+				return revertErr
+			}
+			return util.InterruptError{}
+		}
+		if err != nil {
+			return err
+		}
+		revert, err := util.GetBoolFlagValue(cmd, "revert")
+		if err != nil {
+			return err
+		}
+		if revert {
+			err = player.Revert(context.Background())
+			if err != nil {
+				return err
+			}
+		}
+		util.Print("Finished the schedule")
+		return nil
 	},
 }
 
 func init() {
-	netconfigSetCmd.Flags().IntVarP(&limitFlag, "limit", "m", 1000, "sets packet limit")
-	netconfigSetCmd.Flags().Float64VarP(&lossFlag, "loss", "l", 0.0, "Specifies the amount of packet loss to add [%]")
-	netconfigSetCmd.Flags().IntVarP(&delayFlag, "delay", "d", 0, "Specifies the latency to add [ms]")
-	netconfigSetCmd.Flags().IntVarP(&rateFlag, "bandwidth", "b", 0, "Specifies the bandwidth of the network in mbps")
+	netconfigScheduleCmd.Flags().Bool("dry-run", false, "print the plan without making any changes")
+	netconfigScheduleCmd.Flags().Bool("revert", false, "remove the network conditions and outages once the schedule finishes")
+
+	addConditionFlags(netconfigSetCmd)
+	addConditionFlags(netconfigAllCmd)
+
+	netconfigLinksCmd.Flags().Bool("dry-run", false, "print the conditions of each link without setting them")
+	netconfigLinksCmd.Flags().Bool("clear", false, "remove the network conditions of all of the links")
+
+	for _, cmd := range []*cobra.Command{netconfigSplitCmd, netconfigTopologyCmd, netconfigEclipseCmd} {
+		cmd.Flags().Bool("dry-run", false, "print which nodes each node would reach, without making any outages")
+	}
+	netconfigTopologyCmd.Flags().Int("center", 0, "the node at the center of a star")
+	netconfigEclipseCmd.Flags().String("by", "", "the nodes the eclipsed node is still able to reach, such as 7-9")
 
-	netconfigAllCmd.Flags().IntVarP(&limitFlag, "limit", "m", 1000, "sets packet limit")
-	netconfigAllCmd.Flags().Float64VarP(&lossFlag, "loss", "l", 0.0, "Specifies the amount of packet loss to add [%]")
-	netconfigAllCmd.Flags().IntVarP(&delayFlag, "delay", "d", 0, "Specifies the latency to add [ms]")
-	netconfigAllCmd.Flags().IntVarP(&rateFlag, "bandwidth", "b", 0, "Specifies the bandwidth of the network in mbps")
+	netconfigSaveCmd.Flags().String("file", "", "write the snapshot to a yaml or json file instead of saving it")
+	netconfigLoadCmd.Flags().Bool("dry-run", false, "print the snapshot without applying it")
+	netconfigSnapshotsCmd.AddCommand(netconfigSnapshotsRmCmd)
 
-	netconfigGetCmd.AddCommand(netconfigGetDisconnectsCmd, netconfigGetPartitionsCmd)
+	netconfigGetCmd.AddCommand(netconfigGetDisconnectsCmd, netconfigGetPartitionsCmd, netconfigGetLinksCmd)
 
 	netconfigCmd.AddCommand(netconfigSetCmd, netconfigAllCmd, netconfigClearCmd, netconfigGetCmd, netconfigUncutCmd,
-		netconfigCutCmd, netconfigPartitionCmd, netconfigMarryCmd)
+		netconfigCutCmd, netconfigPartitionCmd, netconfigMarryCmd, netconfigScheduleCmd,
+		netconfigLinksCmd, netconfigPresetsCmd, netconfigSplitCmd, netconfigTopologyCmd, netconfigEclipseCmd,
+		netconfigSaveCmd, netconfigLoadCmd, netconfigSnapshotsCmd)
 
 	RootCmd.AddCommand(netconfigCmd)
 }
@@ -0,0 +1,199 @@
+// Package netconfig holds the network conditions and timelines of them used by the
+// netconfig command, apart from the command itself.
+package netconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/client"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Conditions are the network conditions to apply to a node
+type Conditions struct {
+	// Delay is the latency to add, such as "100ms"
+	Delay util.Duration `json:"delay,omitempty"`
+	// Loss is the percentage of packets to drop
+	Loss float64 `json:"loss,omitempty"`
+	// Rate is the bandwidth limit, such as "100mbps"
+	Rate  string `json:"rate,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+	// Jitter is the variation of the delay, such as "10ms"
+	Jitter util.Duration `json:"jitter,omitempty"`
+	// Correlation is the percentage by which each delay depends on the one before it
+	Correlation float64 `json:"correlation,omitempty"`
+	// Distribution is the distribution of the delay, one of Distributions
+	Distribution string `json:"distribution,omitempty"`
+	// Duplicate is the percentage of packets to send twice
+	Duplicate float64 `json:"duplicate,omitempty"`
+	// Corrupt is the percentage of packets to flip a bit of
+	Corrupt float64 `json:"corrupt,omitempty"`
+	// Reorder is the percentage of packets to send straight away, ahead of the delayed ones
+	Reorder float64 `json:"reorder,omitempty"`
+}
+
+// Distributions are the distributions the delay may follow
+var Distributions = []string{"normal", "pareto", "paretonormal"}
+
+// Validate checks that the percentages are between 0 and 100, and that the options which
+// depend on a delay or jitter are given with them
+func (c Conditions) Validate() error {
+	for name, percent := range map[string]float64{
+		"loss":        c.Loss,
+		"correlation": c.Correlation,
+		"duplicate":   c.Duplicate,
+		"corrupt":     c.Corrupt,
+		"reorder":     c.Reorder,
+	} {
+		if percent < 0 || percent > 100 {
+			return fmt.Errorf("%s must be a percentage between 0 and 100, given %v", name, percent)
+		}
+	}
+	switch {
+	case c.Delay < 0:
+		return fmt.Errorf("delay cannot be negative")
+	case c.Jitter < 0:
+		return fmt.Errorf("jitter cannot be negative")
+	case c.Jitter > 0 && c.Delay == 0:
+		return fmt.Errorf("jitter requires a delay")
+	case c.Reorder > 0 && c.Delay == 0:
+		return fmt.Errorf("reorder requires a delay")
+	case c.Correlation > 0 && c.Jitter == 0:
+		return fmt.Errorf("correlation requires jitter")
+	case len(c.Distribution) > 0 && c.Jitter == 0:
+		return fmt.Errorf("distribution requires jitter")
+	}
+	if len(c.Distribution) == 0 {
+		return nil
+	}
+	for _, distribution := range Distributions {
+		if c.Distribution == distribution {
+			return nil
+		}
+	}
+	return fmt.Errorf(`unknown distribution "%s", expected one of %s`, c.Distribution, strings.Join(Distributions, ", "))
+}
+
+// Netem converts the conditions into those of the given node
+func (c Conditions) Netem(node int) client.Netem {
+	return client.Netem{
+		Node:         node,
+		Limit:        c.Limit,
+		Loss:         c.Loss,
+		Delay:        int(time.Duration(c.Delay) / time.Microsecond),
+		Rate:         c.Rate,
+		Jitter:       int(time.Duration(c.Jitter) / time.Microsecond),
+		Correlation:  c.Correlation,
+		Distribution: c.Distribution,
+		Duplicate:    c.Duplicate,
+		Corrupt:      c.Corrupt,
+		Reorder:      c.Reorder,
+	}
+}
+
+// NetemAll converts the conditions into those for every node. As with netconfig all,
+// the delay and jitter are split between both ends of each connection.
+func (c Conditions) NetemAll() client.Netem {
+	out := c.Netem(0)
+	out.Delay /= 2
+	out.Jitter /= 2
+	return out
+}
+
+// String describes the conditions, such as "delay 50ms, loss 1%"
+func (c Conditions) String() string {
+	out := []string{}
+	if c.Delay > 0 {
+		out = append(out, "delay "+time.Duration(c.Delay).String())
+	}
+	if c.Jitter > 0 {
+		out = append(out, "jitter "+time.Duration(c.Jitter).String())
+	}
+	if c.Correlation > 0 {
+		out = append(out, fmt.Sprintf("correlation %v%%", c.Correlation))
+	}
+	if len(c.Distribution) > 0 {
+		out = append(out, "distribution "+c.Distribution)
+	}
+	for _, percent := range []struct {
+		name  string
+		value float64
+	}{{"loss", c.Loss}, {"duplicate", c.Duplicate}, {"corrupt", c.Corrupt}, {"reorder", c.Reorder}} {
+		if percent.value > 0 {
+			out = append(out, fmt.Sprintf("%s %v%%", percent.name, percent.value))
+		}
+	}
+	if len(c.Rate) > 0 {
+		out = append(out, "rate "+c.Rate)
+	}
+	if c.Limit > 0 {
+		out = append(out, fmt.Sprintf("limit %d", c.Limit))
+	}
+	if len(out) == 0 {
+		return "no conditions"
+	}
+	return strings.Join(out, ", ")
+}
+
+// NodeList is a list of nodes, given as a single node, an array of nodes or a string
+// of nodes and ranges of nodes such as "0-2,5"
+type NodeList []int
+
+// UnmarshalJSON parses a NodeList from a number, an array of numbers or a string
+func (nl *NodeList) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var node int
+	if json.Unmarshal(data, &node) == nil {
+		*nl = NodeList{node}
+		return nil
+	}
+	var nodes []int
+	if json.Unmarshal(data, &nodes) == nil {
+		*nl = NodeList(nodes)
+		return nil
+	}
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return fmt.Errorf(`expected nodes such as [0, 1] or "0-2,5", got %s`, string(data))
+	}
+	*nl, err = ParseNodeList(str)
+	return err
+}
+
+// ParseNodeList parses a comma separated list of nodes and ranges of nodes, such as "0-2,5",
+// as given to the node selectors
+func ParseNodeList(str string) (NodeList, error) {
+	if len(strings.TrimSpace(str)) == 0 {
+		return NodeList{}, nil
+	}
+	nodes, err := selector.Indexes(str)
+	return NodeList(nodes), err
+}
+
+// String gives the nodes in the form ParseNodeList accepts, with consecutive nodes as ranges
+func (nl NodeList) String() string {
+	nodes := append([]int{}, nl...)
+	sort.Ints(nodes)
+	out := []string{}
+	for i := 0; i < len(nodes); {
+		j := i
+		for j+1 < len(nodes) && nodes[j+1] <= nodes[j]+1 {
+			j++
+		}
+		if nodes[j] == nodes[i] {
+			out = append(out, strconv.Itoa(nodes[i]))
+		} else {
+			out = append(out, fmt.Sprintf("%d-%d", nodes[i], nodes[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(out, ",")
+}
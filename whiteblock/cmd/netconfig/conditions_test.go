@@ -0,0 +1,53 @@
+package netconfig
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestConditionsValidate(t *testing.T) {
+	var tests = []struct {
+		conditions Conditions
+		err        bool
+	}{
+		{conditions: Conditions{}},
+		{conditions: Conditions{Delay: ms(50), Jitter: ms(10), Correlation: 25, Distribution: "pareto", Reorder: 5}},
+		{conditions: Conditions{Loss: 100, Duplicate: 1, Corrupt: 0.1}},
+		{conditions: Conditions{Loss: 101}, err: true},
+		{conditions: Conditions{Corrupt: -1}, err: true},
+		{conditions: Conditions{Jitter: ms(10)}, err: true},
+		{conditions: Conditions{Reorder: 10}, err: true},
+		{conditions: Conditions{Delay: ms(50), Correlation: 25}, err: true},
+		{conditions: Conditions{Delay: ms(50), Distribution: "normal"}, err: true},
+		{conditions: Conditions{Delay: ms(50), Jitter: ms(10), Distribution: "uniform"}, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := tt.conditions.Validate()
+			if tt.err && err == nil {
+				t.Error("expected an error")
+			} else if !tt.err && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestConditionsNetemAll(t *testing.T) {
+	netem := Conditions{Delay: ms(50), Jitter: ms(10), Distribution: "normal", Duplicate: 2}.NetemAll()
+	if netem.Delay != 25000 || netem.Jitter != 5000 {
+		t.Errorf("expected the delay and jitter to be halved, got %d and %d", netem.Delay, netem.Jitter)
+	}
+	if netem.Distribution != "normal" || netem.Duplicate != 2 {
+		t.Errorf("unexpected conditions %+v", netem)
+	}
+}
+
+func TestConditionsString(t *testing.T) {
+	str := Conditions{Delay: ms(80), Jitter: ms(40), Distribution: "normal", Loss: 3, Reorder: 1, Rate: "10mbps"}.String()
+	expected := "delay 80ms, jitter 40ms, distribution normal, loss 3%, reorder 1%, rate 10mbps"
+	if str != expected {
+		t.Errorf("expected %s, got %s", expected, str)
+	}
+}
@@ -0,0 +1,229 @@
+package netconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/client"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"time"
+	"unicode"
+)
+
+// Endpoint is one end of a link, either the name of a group or nodes in the form ParseNodeList
+// accepts, such as "0-2,5". A single node may also be given as a number.
+type Endpoint string
+
+// UnmarshalJSON parses an Endpoint from a number or a string
+func (e *Endpoint) UnmarshalJSON(data []byte) error {
+	var node int
+	if json.Unmarshal(data, &node) == nil {
+		*e = Endpoint(strconv.Itoa(node))
+		return nil
+	}
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return fmt.Errorf(`expected a group or nodes such as "0-2,5", got %s`, string(data))
+	}
+	*e = Endpoint(str)
+	return nil
+}
+
+// MarshalJSON gives a single node as a number, and anything else as a string
+func (e Endpoint) MarshalJSON() ([]byte, error) {
+	node, err := strconv.Atoi(string(e))
+	if err == nil {
+		return json.Marshal(node)
+	}
+	return json.Marshal(string(e))
+}
+
+// Link is the network conditions of the links from one set of nodes to another
+type Link struct {
+	From Endpoint `json:"from"`
+	To   Endpoint `json:"to"`
+	// Both applies the conditions in both directions, otherwise they only apply from From to To
+	Both bool `json:"both,omitempty"`
+	Conditions
+}
+
+// Links are the network conditions of the links between the nodes, given as a list of links, a
+// matrix of them or both. Groups name sets of nodes, such as those of a region, to use as the
+// ends of the links.
+type Links struct {
+	Groups map[string]NodeList `json:"groups,omitempty"`
+	Links  []Link              `json:"links,omitempty"`
+	// Matrix gives the conditions of the links from each set of nodes to each other set
+	Matrix map[Endpoint]map[Endpoint]Conditions `json:"matrix,omitempty"`
+}
+
+// LoadLinks reads links from a yaml or json file
+func LoadLinks(path string) (*Links, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	links, err := ParseLinks(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return links, nil
+}
+
+// ParseLinks parses links, checking that each end of every link is a group or valid nodes
+func ParseLinks(data []byte) (*Links, error) {
+	links := new(Links)
+	err := util.UnmarshalYAMLStrict(data, links)
+	if err != nil {
+		return nil, util.ValidationError{Err: err}
+	}
+	for name, nodes := range links.Groups {
+		if len(name) == 0 || unicode.IsDigit(rune(name[0])) {
+			return nil, util.NewValidationError(`invalid group name "%s", it must not start with a digit`, name)
+		}
+		if len(nodes) == 0 {
+			return nil, util.NewValidationError("group %s has no nodes", name)
+		}
+	}
+	if len(links.Links) == 0 && len(links.Matrix) == 0 {
+		return nil, util.NewValidationError("no links given")
+	}
+	_, err = links.Expand()
+	if err != nil {
+		return nil, util.ValidationError{Err: err}
+	}
+	return links, nil
+}
+
+func (links Links) resolve(end Endpoint) (NodeList, error) {
+	if nodes, ok := links.Groups[string(end)]; ok {
+		return nodes, nil
+	}
+	nodes, err := ParseNodeList(string(end))
+	if err != nil || len(nodes) == 0 {
+		return nil, fmt.Errorf(`unknown group or nodes "%s"`, end)
+	}
+	return nodes, nil
+}
+
+// Expand gets the conditions of the link between each pair of nodes, in order of the nodes. The
+// matrix is applied before the list of links, and where a link is given more than once the last
+// conditions given are used. Links from a node to itself are left out.
+func (links Links) Expand() ([]client.Link, error) {
+	pairs := map[[2]int]client.Link{}
+	add := func(from Endpoint, to Endpoint, conditions Conditions) error {
+		err := conditions.Validate()
+		if err != nil {
+			return err
+		}
+		fromNodes, err := links.resolve(from)
+		if err != nil {
+			return err
+		}
+		toNodes, err := links.resolve(to)
+		if err != nil {
+			return err
+		}
+		for _, src := range fromNodes {
+			for _, dst := range toNodes {
+				if src != dst {
+					pairs[[2]int{src, dst}] = conditions.Link(src, dst)
+				}
+			}
+		}
+		return nil
+	}
+
+	froms := []string{}
+	for from := range links.Matrix {
+		froms = append(froms, string(from))
+	}
+	sort.Strings(froms)
+	for _, from := range froms {
+		row := links.Matrix[Endpoint(from)]
+		tos := []string{}
+		for to := range row {
+			tos = append(tos, string(to))
+		}
+		sort.Strings(tos)
+		for _, to := range tos {
+			err := add(Endpoint(from), Endpoint(to), row[Endpoint(to)])
+			if err != nil {
+				return nil, fmt.Errorf("matrix %s to %s: %v", from, to, err)
+			}
+		}
+	}
+	for i, link := range links.Links {
+		err := add(link.From, link.To, link.Conditions)
+		if err == nil && link.Both {
+			err = add(link.To, link.From, link.Conditions)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("link %d: %v", i+1, err)
+		}
+	}
+
+	out := []client.Link{}
+	for _, link := range pairs {
+		out = append(out, link)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].From != out[j].From {
+			return out[i].From < out[j].From
+		}
+		return out[i].To < out[j].To
+	})
+	return out, nil
+}
+
+// Link converts the conditions into those of the link from one node to another
+func (c Conditions) Link(from int, to int) client.Link {
+	netem := c.Netem(from)
+	return client.Link{
+		From:         from,
+		To:           to,
+		Limit:        netem.Limit,
+		Loss:         netem.Loss,
+		Delay:        netem.Delay,
+		Rate:         netem.Rate,
+		Jitter:       netem.Jitter,
+		Correlation:  netem.Correlation,
+		Distribution: netem.Distribution,
+		Duplicate:    netem.Duplicate,
+		Corrupt:      netem.Corrupt,
+		Reorder:      netem.Reorder,
+	}
+}
+
+// LinksOf converts the links between each pair of nodes, as given by the server, back into
+// a list of links which may be applied again
+func LinksOf(in []client.Link) Links {
+	out := Links{Links: []Link{}}
+	for _, link := range in {
+		out.Links = append(out.Links, Link{
+			From:       Endpoint(strconv.Itoa(link.From)),
+			To:         Endpoint(strconv.Itoa(link.To)),
+			Conditions: ConditionsOf(link),
+		})
+	}
+	return out
+}
+
+// ConditionsOf gets the conditions of a link between two nodes
+func ConditionsOf(link client.Link) Conditions {
+	return Conditions{
+		Delay:        util.Duration(time.Duration(link.Delay) * time.Microsecond),
+		Loss:         link.Loss,
+		Rate:         link.Rate,
+		Limit:        link.Limit,
+		Jitter:       util.Duration(time.Duration(link.Jitter) * time.Microsecond),
+		Correlation:  link.Correlation,
+		Distribution: link.Distribution,
+		Duplicate:    link.Duplicate,
+		Corrupt:      link.Corrupt,
+		Reorder:      link.Reorder,
+	}
+}
@@ -0,0 +1,93 @@
+package netconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/client"
+	"strconv"
+	"testing"
+)
+
+func TestParseLinks(t *testing.T) {
+	var tests = []struct {
+		data     string
+		expected []client.Link
+		err      bool
+	}{
+		{
+			data: `
+groups:
+  us: 0-1
+  eu: 2
+matrix:
+  us: {eu: {delay: 120ms}}
+  eu: {us: {delay: 100ms, loss: 1}}
+links:
+  - {from: 0, to: eu, delay: 1s}
+  - {from: 0, to: 1, rate: 10mbps, both: true}`,
+			expected: []client.Link{
+				{From: 0, To: 1, Rate: "10mbps"},
+				{From: 0, To: 2, Delay: 1000000},
+				{From: 1, To: 0, Rate: "10mbps"},
+				{From: 1, To: 2, Delay: 120000},
+				{From: 2, To: 0, Delay: 100000, Loss: 1},
+				{From: 2, To: 1, Delay: 100000, Loss: 1},
+			},
+		},
+		{
+			data:     `links: [{from: "0-1", to: "0-1", delay: 5ms}]`,
+			expected: []client.Link{{From: 0, To: 1, Delay: 5000}, {From: 1, To: 0, Delay: 5000}},
+		},
+		{data: `groups: {us: 0}`, err: true},
+		{data: `links: [{from: us, to: 1, delay: 5ms}]`, err: true},
+		{data: `groups: {1a: 0}
+links: [{from: 1a, to: 1}]`, err: true},
+		{data: `groups: {us: []}
+links: [{from: us, to: 1}]`, err: true},
+		{data: `matrix: {0: {x: {delay: 5ms}}}`, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			links, err := ParseLinks([]byte(tt.data))
+			if tt.err {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := links.Expand()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(out) != fmt.Sprint(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, out)
+			}
+		})
+	}
+}
+
+func TestLinksOfRoundTrip(t *testing.T) {
+	in := []client.Link{
+		{From: 0, To: 1, Delay: 120000, Loss: 0.5},
+		{From: 1, To: 0, Rate: "100mbps", Limit: 500},
+	}
+	data, err := json.Marshal(LinksOf(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	links, err := ParseLinks(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := links.Expand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(out) != fmt.Sprint(in) {
+		t.Errorf("expected %v, got %v from %s", in, out, data)
+	}
+}
@@ -0,0 +1,123 @@
+package netconfig
+
+import (
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Preset is a named set of network conditions
+type Preset struct {
+	Name       string     `json:"name"`
+	Conditions Conditions `json:"conditions"`
+	// Source is the file the preset is defined in, or builtin
+	Source string `json:"source"`
+}
+
+func ms(n int64) util.Duration {
+	return util.Duration(time.Duration(n) * time.Millisecond)
+}
+
+// BuiltinPresets are the presets available without a presets file, which may be replaced
+// by presets of the same name in the file
+var BuiltinPresets = map[string]Conditions{
+	"lossy-mobile": {
+		Delay:        ms(80),
+		Jitter:       ms(40),
+		Correlation:  25,
+		Distribution: "normal",
+		Loss:         3,
+		Duplicate:    0.5,
+		Reorder:      1,
+		Rate:         "10mbps",
+	},
+	"transatlantic": {
+		Delay:        ms(40),
+		Jitter:       ms(4),
+		Distribution: "normal",
+		Loss:         0.1,
+	},
+	"satellite": {
+		Delay:        ms(300),
+		Jitter:       ms(50),
+		Distribution: "paretonormal",
+		Loss:         1,
+		Corrupt:      0.1,
+		Rate:         "20mbps",
+	},
+}
+
+// PresetsPath gets the path of the presets file, which is presets.yaml in the store
+// directory unless NETEM_PRESETS is set
+func PresetsPath() string {
+	conf := util.GetConfig()
+	if len(conf.NetemPresets) > 0 {
+		return conf.NetemPresets
+	}
+	return conf.StoreDirectory + "presets.yaml"
+}
+
+// ParsePresets parses presets, given as a map of their names to their conditions
+func ParsePresets(data []byte) (map[string]Conditions, error) {
+	out := map[string]Conditions{}
+	err := util.UnmarshalYAMLStrict(data, &out)
+	if err != nil {
+		return nil, util.ValidationError{Err: err}
+	}
+	for name, conditions := range out {
+		err = conditions.Validate()
+		if err != nil {
+			return nil, util.NewValidationError("preset %s: %v", name, err)
+		}
+	}
+	return out, nil
+}
+
+// LoadPresets gets the builtin presets along with those of the presets file at path, if it exists
+func LoadPresets(path string) ([]Preset, error) {
+	all := map[string]Preset{}
+	for name, conditions := range BuiltinPresets {
+		all[name] = Preset{Name: name, Conditions: conditions, Source: "builtin"}
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		presets, err := ParsePresets(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for name, conditions := range presets {
+			all[name] = Preset{Name: name, Conditions: conditions, Source: path}
+		}
+	}
+	out := []Preset{}
+	for _, preset := range all {
+		out = append(out, preset)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// FindPreset gets the conditions of the preset with the given name
+func FindPreset(name string) (Conditions, error) {
+	presets, err := LoadPresets(PresetsPath())
+	if err != nil {
+		return Conditions{}, err
+	}
+	names := []string{}
+	for _, preset := range presets {
+		if preset.Name == name {
+			return preset.Conditions, nil
+		}
+		names = append(names, preset.Name)
+	}
+	return Conditions{}, util.NewValidationError(`unknown preset "%s", expected one of %s`, name, strings.Join(names, ", "))
+}
@@ -0,0 +1,68 @@
+package netconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltinPresets(t *testing.T) {
+	for name, conditions := range BuiltinPresets {
+		err := conditions.Validate()
+		if err != nil {
+			t.Errorf("preset %s: %v", name, err)
+		}
+	}
+}
+
+func TestLoadPresets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "presets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "presets.yaml")
+
+	presets, err := LoadPresets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presets) != len(BuiltinPresets) {
+		t.Errorf("expected only the builtin presets without a file, got %d", len(presets))
+	}
+
+	err = ioutil.WriteFile(path, []byte(`
+satellite: {delay: 600ms, loss: 2}
+lan: {delay: 1ms, jitter: 200us}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	presets, err = LoadPresets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]Preset{}
+	for _, preset := range presets {
+		found[preset.Name] = preset
+	}
+	if len(found) != len(BuiltinPresets)+1 {
+		t.Errorf("expected the presets of the file to be added, got %d", len(found))
+	}
+	if found["satellite"].Source != path || found["satellite"].Conditions.String() != "delay 600ms, loss 2%" {
+		t.Errorf("expected the file to replace the builtin preset, got %+v", found["satellite"])
+	}
+	if found["transatlantic"].Source != "builtin" {
+		t.Errorf("expected the builtin preset to remain, got %+v", found["transatlantic"])
+	}
+
+	err = ioutil.WriteFile(path, []byte(`bad: {loss: 200}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadPresets(path)
+	if err == nil {
+		t.Error("expected an error for an invalid preset")
+	}
+}
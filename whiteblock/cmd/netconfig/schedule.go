@@ -0,0 +1,249 @@
+package netconfig
+
+import (
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/whiteblock/cli/whiteblock/client"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Schedule is a timeline of changes to the network conditions
+type Schedule struct {
+	Events []Event `json:"events"`
+}
+
+// NodeConditions are network conditions for some of the nodes
+type NodeConditions struct {
+	Nodes NodeList `json:"nodes"`
+	Conditions
+}
+
+// Event is a change to the network made at a point in the schedule. Exactly one of
+// the changes must be given.
+type Event struct {
+	// At is the time since the start of the schedule to make the change at
+	At util.Duration `json:"at"`
+
+	// All applies the conditions to every node
+	All *Conditions `json:"all"`
+	// Node applies the conditions to the given nodes
+	Node *NodeConditions `json:"node"`
+	// Partition cuts the given nodes off from the rest of the network
+	Partition NodeList `json:"partition"`
+	// Cut prevents the two given nodes from connecting
+	Cut NodeList `json:"cut"`
+	// Uncut allows the two given nodes to connect again
+	Uncut NodeList `json:"uncut"`
+	// Heal removes all of the outages
+	Heal bool `json:"heal"`
+	// Clear removes all of the network conditions
+	Clear bool `json:"clear"`
+}
+
+func (event Event) actions() []string {
+	out := []string{}
+	for action, given := range map[string]bool{
+		"all":       event.All != nil,
+		"node":      event.Node != nil,
+		"partition": event.Partition != nil,
+		"cut":       event.Cut != nil,
+		"uncut":     event.Uncut != nil,
+		"heal":      event.Heal,
+		"clear":     event.Clear,
+	} {
+		if given {
+			out = append(out, action)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Action gets the name of the change made by the event
+func (event Event) Action() string {
+	actions := event.actions()
+	if len(actions) != 1 {
+		return ""
+	}
+	return actions[0]
+}
+
+// String describes the change made by the event
+func (event Event) String() string {
+	switch event.Action() {
+	case "all":
+		return "set " + event.All.String() + " on all nodes"
+	case "node":
+		return fmt.Sprintf("set %s on nodes %s", event.Node.Conditions, event.Node.Nodes)
+	case "partition":
+		return "partition off nodes " + event.Partition.String()
+	case "cut":
+		return fmt.Sprintf("cut the connection between nodes %d and %d", event.Cut[0], event.Cut[1])
+	case "uncut":
+		return fmt.Sprintf("restore the connection between nodes %d and %d", event.Uncut[0], event.Uncut[1])
+	case "heal":
+		return "remove all of the outages"
+	case "clear":
+		return "remove all of the network conditions"
+	}
+	return "nothing"
+}
+
+func (event Event) validate() error {
+	actions := event.actions()
+	switch len(actions) {
+	case 0:
+		return fmt.Errorf("no change given")
+	case 1:
+	default:
+		return fmt.Errorf("only one change may be given per event, given %s", strings.Join(actions, ", "))
+	}
+	switch {
+	case event.At < 0:
+		return fmt.Errorf("at cannot be negative")
+	case event.Node != nil && len(event.Node.Nodes) == 0:
+		return fmt.Errorf("node: nodes are required")
+	case event.Partition != nil && len(event.Partition) == 0:
+		return fmt.Errorf("partition: nodes are required")
+	case event.Cut != nil && len(event.Cut) != 2:
+		return fmt.Errorf("cut: expected 2 nodes, given %d", len(event.Cut))
+	case event.Uncut != nil && len(event.Uncut) != 2:
+		return fmt.Errorf("uncut: expected 2 nodes, given %d", len(event.Uncut))
+	}
+	var err error
+	switch {
+	case event.All != nil:
+		err = event.All.Validate()
+	case event.Node != nil:
+		err = event.Node.Conditions.Validate()
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", event.Action(), err)
+	}
+	return nil
+}
+
+// LoadSchedule reads a schedule from a yaml or json file
+func LoadSchedule(path string) (*Schedule, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := ParseSchedule(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schedule, nil
+}
+
+// ParseSchedule parses a schedule, putting its events in order of time
+func ParseSchedule(data []byte) (*Schedule, error) {
+	schedule := new(Schedule)
+	err := util.UnmarshalYAMLStrict(data, schedule)
+	if err != nil {
+		return nil, util.ValidationError{Err: err}
+	}
+	if len(schedule.Events) == 0 {
+		return nil, util.NewValidationError("the schedule has no events")
+	}
+	for i, event := range schedule.Events {
+		err = event.validate()
+		if err != nil {
+			return nil, util.NewValidationError("event %d: %v", i+1, err)
+		}
+	}
+	sort.SliceStable(schedule.Events, func(i, j int) bool {
+		return schedule.Events[i].At < schedule.Events[j].At
+	})
+	return schedule, nil
+}
+
+// Player makes the changes of a schedule to a testnet
+type Player struct {
+	Client    *client.Client
+	TestnetID string
+	// OnEvent is called before each event is applied, if it is not nil
+	OnEvent func(index int, event Event)
+	// OnWait is called about every second while waiting for the next event, if it is not nil
+	OnWait func(index int, next Event, remaining time.Duration)
+}
+
+// Play applies each event of the schedule at its time, stopping at the first error or once
+// ctx is done. The network is left as it is by the last event.
+func (p Player) Play(ctx context.Context, schedule *Schedule) error {
+	start := time.Now()
+	for i, event := range schedule.Events {
+		due := start.Add(time.Duration(event.At))
+		for {
+			remaining := time.Until(due)
+			if remaining <= 0 {
+				break
+			}
+			if p.OnWait != nil {
+				p.OnWait(i+1, event, remaining)
+			}
+			wait := remaining
+			if wait > time.Second {
+				wait = time.Second
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+		if p.OnEvent != nil {
+			p.OnEvent(i+1, event)
+		}
+		err := p.Apply(ctx, event)
+		if err != nil {
+			return fmt.Errorf("event %d, %s: %v", i+1, event, err)
+		}
+	}
+	return nil
+}
+
+// Apply makes the change of a single event
+func (p Player) Apply(ctx context.Context, event Event) error {
+	switch event.Action() {
+	case "all":
+		return p.Client.NetemAll(ctx, p.TestnetID, event.All.NetemAll())
+	case "node":
+		for _, node := range event.Node.Nodes {
+			err := p.Client.Netem(ctx, p.TestnetID, event.Node.Conditions.Netem(node))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case "partition":
+		return p.Client.PartitionOutage(ctx, p.TestnetID, event.Partition)
+	case "cut":
+		return p.Client.MakeOutage(ctx, p.TestnetID, event.Cut[0], event.Cut[1])
+	case "uncut":
+		return p.Client.RemoveOutage(ctx, p.TestnetID, event.Uncut[0], event.Uncut[1])
+	case "heal":
+		return p.Client.RemoveAllOutages(ctx, p.TestnetID)
+	case "clear":
+		return p.Client.NetemDelete(ctx, p.TestnetID)
+	}
+	return fmt.Errorf("unknown change")
+}
+
+// Revert puts the network back into a clean state, with no conditions or outages
+func (p Player) Revert(ctx context.Context) error {
+	err := p.Client.NetemDelete(ctx, p.TestnetID)
+	outageErr := p.Client.RemoveAllOutages(ctx, p.TestnetID)
+	if err != nil {
+		if outageErr != nil {
+			log.WithFields(log.Fields{"error": outageErr}).Error("unable to remove the outages")
+		}
+		return err
+	}
+	return outageErr
+}
@@ -0,0 +1,189 @@
+package netconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/client"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseNodeList(t *testing.T) {
+	var tests = []struct {
+		str      string
+		expected string
+		err      bool
+	}{
+		{str: "0-2,5", expected: "[0 1 2 5]"},
+		{str: "3", expected: "[3]"},
+		{str: " 1 , 2 ", expected: "[1 2]"},
+		{str: "2-1", err: true},
+		{str: "a", err: true},
+		{str: "-1", err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			nl, err := ParseNodeList(tt.str)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected an error for %s", tt.str)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint([]int(nl)) != tt.expected {
+				t.Errorf("expected %s, got %v", tt.expected, []int(nl))
+			}
+		})
+	}
+}
+
+func TestNodeListString(t *testing.T) {
+	var tests = []struct {
+		nodes    NodeList
+		expected string
+	}{
+		{nodes: NodeList{0, 1, 2, 5}, expected: "0-2,5"},
+		{nodes: NodeList{4}, expected: "4"},
+		{nodes: NodeList{7, 3, 4}, expected: "3-4,7"},
+		{nodes: NodeList{}, expected: ""},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if tt.nodes.String() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, tt.nodes.String())
+			}
+		})
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	var tests = []struct {
+		data    string
+		actions string
+		err     bool
+	}{
+		{
+			data: `
+events:
+  - at: 180s
+    node: {nodes: 4, loss: 5}
+  - at: 0s
+    all: {delay: 50ms}
+  - at: 60s
+    partition: 0-2
+  - at: 2m
+    heal: true`,
+			actions: "[all partition heal node]",
+		},
+		{data: `events: [{at: 0, cut: [0, 1]}, {at: 1, uncut: "0-1"}]`, actions: "[cut uncut]"},
+		{data: `events: []`, err: true},
+		{data: `events: [{at: 0}]`, err: true},
+		{data: `events: [{at: 0, heal: true, clear: true}]`, err: true},
+		{data: `events: [{at: 0, cut: [0]}]`, err: true},
+		{data: `events: [{at: -1s, heal: true}]`, err: true},
+		{data: `events: [{at: 0, partition: "x"}]`, err: true},
+		{data: `events: [{at: 0, heal: true, unknown: 1}]`, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			schedule, err := ParseSchedule([]byte(tt.data))
+			if tt.err {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			actions := []string{}
+			for _, event := range schedule.Events {
+				actions = append(actions, event.Action())
+			}
+			if fmt.Sprint(actions) != tt.actions {
+				t.Errorf("expected %s, got %v", tt.actions, actions)
+			}
+		})
+	}
+}
+
+// fakeTransport records the calls made, succeeding every call
+type fakeTransport struct {
+	methods []string
+}
+
+func (ft *fakeTransport) Call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	ft.methods = append(ft.methods, method)
+	return nil
+}
+
+func TestPlayerPlay(t *testing.T) {
+	schedule, err := ParseSchedule([]byte(`
+events:
+  - at: 0.02
+    node: {nodes: "1-2", loss: 5}
+  - at: 0
+    all: {delay: 50ms}
+  - at: 0.01
+    partition: [0]
+  - at: 0.03
+    heal: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ft := &fakeTransport{}
+	events := []int{}
+	player := Player{
+		Client:    client.New(ft),
+		TestnetID: "testnet1",
+		OnEvent: func(index int, event Event) {
+			events = append(events, index)
+		},
+	}
+	start := time.Now()
+	err = player.Play(context.Background(), schedule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) < 30*time.Millisecond {
+		t.Errorf("expected the schedule to take at least 30ms, took %v", time.Since(start))
+	}
+	if fmt.Sprint(events) != "[1 2 3 4]" {
+		t.Errorf("unexpected events %v", events)
+	}
+	expected := []string{"netem_all", "partition_outage", "netem", "netem", "remove_all_outages"}
+	if fmt.Sprint(ft.methods) != fmt.Sprint(expected) {
+		t.Errorf("expected the calls %v, got %v", expected, ft.methods)
+	}
+}
+
+func TestPlayerPlayCancel(t *testing.T) {
+	schedule, err := ParseSchedule([]byte(`events: [{at: 0, clear: true}, {at: 1h, heal: true}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ft := &fakeTransport{}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	player := Player{Client: client.New(ft), TestnetID: "testnet1"}
+	err = player.Play(ctx, schedule)
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+	err = player.Revert(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"netem_delete", "netem_delete", "remove_all_outages"}
+	if fmt.Sprint(ft.methods) != fmt.Sprint(expected) {
+		t.Errorf("expected the calls %v, got %v", expected, ft.methods)
+	}
+}
@@ -0,0 +1,303 @@
+package netconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/client"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"io/ioutil"
+	"sort"
+	"time"
+)
+
+const snapshotsKey = "network_snapshots"
+
+// Snapshot is the whole network state of a testnet, which may be applied again to any
+// testnet with the same number of nodes
+type Snapshot struct {
+	Name      string    `json:"name"`
+	TestnetID string    `json:"testnetId"`
+	Nodes     int       `json:"nodes"`
+	Created   time.Time `json:"created"`
+	// Conditions are the network conditions of each node which has any
+	Conditions []NodeConditions `json:"conditions"`
+	Links      *Links           `json:"links,omitempty"`
+	Outages    []Outage         `json:"outages"`
+	// Partitions are the groups of nodes able to reach each other, which follow from the outages
+	// and are only kept for reading
+	Partitions []NodeList `json:"partitions,omitempty"`
+}
+
+// UnmarshalJSON parses an Outage from a pair of nodes, given either as an array or as an
+// object with node1 and node2
+func (o *Outage) UnmarshalJSON(data []byte) error {
+	var pair []int
+	if json.Unmarshal(data, &pair) != nil {
+		var obj struct {
+			Node1 *int `json:"node1"`
+			Node2 *int `json:"node2"`
+		}
+		err := json.Unmarshal(data, &obj)
+		if err != nil || obj.Node1 == nil || obj.Node2 == nil {
+			return fmt.Errorf("expected an outage such as [0, 1], got %s", string(data))
+		}
+		pair = []int{*obj.Node1, *obj.Node2}
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("expected an outage between 2 nodes, got %s", string(data))
+	}
+	if pair[0] > pair[1] {
+		pair[0], pair[1] = pair[1], pair[0]
+	}
+	*o = Outage{pair[0], pair[1]}
+	return nil
+}
+
+// ConditionsOfNetem gets the conditions of a node as given by the server
+func ConditionsOfNetem(netem client.Netem) Conditions {
+	return ConditionsOf(client.Link{
+		Limit:        netem.Limit,
+		Loss:         netem.Loss,
+		Delay:        netem.Delay,
+		Rate:         netem.Rate,
+		Jitter:       netem.Jitter,
+		Correlation:  netem.Correlation,
+		Distribution: netem.Distribution,
+		Duplicate:    netem.Duplicate,
+		Corrupt:      netem.Corrupt,
+		Reorder:      netem.Reorder,
+	})
+}
+
+// decode converts the loosely typed result of a call into out
+func decode(in interface{}, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// Capture takes a snapshot of the network state of a testnet
+func Capture(ctx context.Context, c *client.Client, testnetID string) (*Snapshot, error) {
+	nodes, err := c.Nodes(ctx, testnetID)
+	if err != nil {
+		return nil, err
+	}
+	out := &Snapshot{TestnetID: testnetID, Nodes: len(nodes), Created: time.Now(), Outages: []Outage{}}
+
+	res, err := c.NetemGet(ctx, testnetID)
+	if err != nil {
+		return nil, err
+	}
+	netems := []client.Netem{}
+	if res != nil {
+		err = decode(res, &netems)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected network conditions from the server: %v", err)
+		}
+	}
+	out.Conditions = []NodeConditions{}
+	for _, netem := range netems {
+		conditions := ConditionsOfNetem(netem)
+		if len(netem.Dst) == 0 && conditions != (Conditions{}) {
+			out.Conditions = append(out.Conditions, NodeConditions{Nodes: NodeList{netem.Node}, Conditions: conditions})
+		}
+	}
+
+	if links := client.LinksOfNetems(netems); len(links) > 0 {
+		linksOf := LinksOf(links)
+		out.Links = &linksOf
+	}
+
+	res, err = c.GetOutages(ctx, testnetID, "")
+	if err != nil {
+		return nil, err
+	}
+	if res != nil {
+		err = decode(res, &out.Outages)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected outages from the server: %v", err)
+		}
+	}
+	seen := map[Outage]bool{}
+	outages := []Outage{}
+	for _, outage := range out.Outages {
+		if !seen[outage] && outage[0] != outage[1] {
+			seen[outage] = true
+			outages = append(outages, outage)
+		}
+	}
+	sort.Slice(outages, func(i, j int) bool {
+		if outages[i][0] != outages[j][0] {
+			return outages[i][0] < outages[j][0]
+		}
+		return outages[i][1] < outages[j][1]
+	})
+	out.Outages = outages
+	out.Partitions = Groups(out.Nodes, out.Outages)
+	return out, nil
+}
+
+// Validate checks that every node of the snapshot is within its number of nodes
+func (s Snapshot) Validate() error {
+	if s.Nodes <= 0 {
+		return util.NewValidationError("the snapshot has no nodes")
+	}
+	for i, nc := range s.Conditions {
+		for _, node := range nc.Nodes {
+			if err := checkNode(s.Nodes, node); err != nil {
+				return util.NewValidationError("conditions %d: %v", i+1, err)
+			}
+		}
+		if err := nc.Conditions.Validate(); err != nil {
+			return util.NewValidationError("conditions %d: %v", i+1, err)
+		}
+	}
+	for _, outage := range s.Outages {
+		for _, node := range outage {
+			if err := checkNode(s.Nodes, node); err != nil {
+				return util.NewValidationError("outage %v: %v", outage, err)
+			}
+		}
+	}
+	if s.Links == nil {
+		return nil
+	}
+	links, err := s.Links.Expand()
+	if err != nil {
+		return util.ValidationError{Err: err}
+	}
+	for _, link := range links {
+		if checkNode(s.Nodes, link.From) != nil || checkNode(s.Nodes, link.To) != nil {
+			return util.NewValidationError("the link from %d to %d is outside of the %d nodes", link.From, link.To, s.Nodes)
+		}
+	}
+	return nil
+}
+
+// Restore applies the network state of a snapshot to a testnet, replacing its network conditions
+// and outages. The testnet must have the same number of nodes as the snapshot.
+func Restore(ctx context.Context, c *client.Client, testnetID string, s Snapshot) error {
+	err := s.Validate()
+	if err != nil {
+		return err
+	}
+	nodes, err := c.Nodes(ctx, testnetID)
+	if err != nil {
+		return err
+	}
+	if len(nodes) != s.Nodes {
+		return util.NewValidationError("the snapshot is of %d nodes, but the testnet has %d", s.Nodes, len(nodes))
+	}
+	err = c.NetemDelete(ctx, testnetID)
+	if err != nil {
+		return err
+	}
+	for _, nc := range s.Conditions {
+		for _, node := range nc.Nodes {
+			err = c.Netem(ctx, testnetID, nc.Conditions.Netem(node))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if s.Links != nil {
+		links, err := s.Links.Expand()
+		if err != nil {
+			return err
+		}
+		for _, netem := range client.LinkNetems(links) {
+			err = c.Netem(ctx, testnetID, netem)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return ApplyOutages(ctx, c, testnetID, s.Outages)
+}
+
+// ParseSnapshot parses a snapshot from yaml or json
+func ParseSnapshot(data []byte) (*Snapshot, error) {
+	out := new(Snapshot)
+	err := util.UnmarshalYAMLStrict(data, out)
+	if err != nil {
+		return nil, util.ValidationError{Err: err}
+	}
+	return out, out.Validate()
+}
+
+// LoadSnapshotFile reads a snapshot from a yaml or json file
+func LoadSnapshotFile(path string) (*Snapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	out, err := ParseSnapshot(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
+
+// GetSnapshots gets the snapshots kept in the local store, by name
+func GetSnapshots() (map[string]Snapshot, error) {
+	out := map[string]Snapshot{}
+	if !util.Exists(snapshotsKey) {
+		return out, nil
+	}
+	return out, util.GetP(snapshotsKey, &out)
+}
+
+// ListSnapshots gets the snapshots kept in the local store, in order of name
+func ListSnapshots() ([]Snapshot, error) {
+	snapshots, err := GetSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	out := []Snapshot{}
+	for _, snapshot := range snapshots {
+		out = append(out, snapshot)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// SaveSnapshot keeps a snapshot in the local store under its name, replacing any of the same name
+func SaveSnapshot(s Snapshot) error {
+	snapshots, err := GetSnapshots()
+	if err != nil {
+		return err
+	}
+	snapshots[s.Name] = s
+	return util.Set(snapshotsKey, snapshots)
+}
+
+// FindSnapshot gets the snapshot with the given name from the local store
+func FindSnapshot(name string) (Snapshot, error) {
+	snapshots, err := GetSnapshots()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	s, ok := snapshots[name]
+	if !ok {
+		return Snapshot{}, util.NewValidationError(`no snapshot named "%s"`, name)
+	}
+	return s, nil
+}
+
+// RemoveSnapshot removes a snapshot from the local store
+func RemoveSnapshot(name string) error {
+	snapshots, err := GetSnapshots()
+	if err != nil {
+		return err
+	}
+	if _, ok := snapshots[name]; !ok {
+		return util.NewValidationError(`no snapshot named "%s"`, name)
+	}
+	delete(snapshots, name)
+	return util.Set(snapshotsKey, snapshots)
+}
@@ -0,0 +1,130 @@
+package netconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/client"
+	"strconv"
+	"testing"
+)
+
+func TestOutageUnmarshal(t *testing.T) {
+	var tests = []struct {
+		data     string
+		expected Outage
+		err      bool
+	}{
+		{data: `[0, 1]`, expected: Outage{0, 1}},
+		{data: `[3, 2]`, expected: Outage{2, 3}},
+		{data: `{"node1": 4, "node2": 1}`, expected: Outage{1, 4}},
+		{data: `[1]`, err: true},
+		{data: `{"node1": 4}`, err: true},
+		{data: `"0-1"`, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var outage Outage
+			err := json.Unmarshal([]byte(tt.data), &outage)
+			if tt.err {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if outage != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, outage)
+			}
+		})
+	}
+}
+
+// resultTransport replies to the calls with the given results, and with null to the rest
+type resultTransport struct {
+	fakeTransport
+	results map[string]string
+}
+
+func (rt *resultTransport) Call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	rt.fakeTransport.Call(ctx, method, params, out)
+	res, ok := rt.results[method]
+	if !ok {
+		res = "null"
+	}
+	return json.Unmarshal([]byte(res), out)
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	rt := &resultTransport{results: map[string]string{
+		"nodes": `[{"id":"a"},{"id":"b"},{"id":"c"}]`,
+		"netem_get": `[{"node":0,"delay":50000,"jitter":5000,"distribution":"normal"},{"node":1},{"node":2,"loss":2},
+			{"node":0,"dst":[2],"delay":120000}]`,
+		"get_outages": `[[1,0],{"node1":0,"node2":1},[2,1]]`,
+	}}
+	snapshot, err := Capture(context.Background(), client.New(rt), "testnet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Nodes != 3 || len(snapshot.Conditions) != 2 || snapshot.Links == nil {
+		t.Fatalf("unexpected snapshot %+v", snapshot)
+	}
+	if fmt.Sprint(snapshot.Outages) != "[[0 1] [1 2]]" {
+		t.Errorf("expected the outages to be deduplicated, got %v", snapshot.Outages)
+	}
+	if fmt.Sprint(snapshot.Partitions) != "[0,2 1]" {
+		t.Errorf("unexpected partitions %v", snapshot.Partitions)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseSnapshot(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt = &resultTransport{results: map[string]string{"nodes": `[{"id":"d"},{"id":"e"},{"id":"f"}]`}}
+	err = Restore(context.Background(), client.New(rt), "testnet2", *parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "[nodes netem_delete netem netem netem remove_all_outages make_outage make_outage]"
+	if fmt.Sprint(rt.methods) != expected {
+		t.Errorf("expected the calls %s, got %v", expected, rt.methods)
+	}
+
+	rt = &resultTransport{results: map[string]string{"nodes": `[{"id":"d"}]`}}
+	err = Restore(context.Background(), client.New(rt), "testnet2", *parsed)
+	if err == nil {
+		t.Error("expected an error restoring to a testnet with a different number of nodes")
+	}
+}
+
+func TestParseSnapshot(t *testing.T) {
+	var tests = []struct {
+		data string
+		err  bool
+	}{
+		{data: `{nodes: 2, conditions: [{nodes: 0-1, delay: 10ms}], outages: [[0, 1]]}`},
+		{data: `{nodes: 0}`, err: true},
+		{data: `{nodes: 2, conditions: [{nodes: 2, delay: 10ms}]}`, err: true},
+		{data: `{nodes: 2, outages: [[0, 5]]}`, err: true},
+		{data: `{nodes: 2, links: {links: [{from: 0, to: 3, delay: 1ms}]}}`, err: true},
+		{data: `{nodes: 2, conditions: [{nodes: 0, jitter: 10ms}]}`, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := ParseSnapshot([]byte(tt.data))
+			if tt.err && err == nil {
+				t.Error("expected an error")
+			} else if !tt.err && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
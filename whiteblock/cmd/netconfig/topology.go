@@ -0,0 +1,248 @@
+package netconfig
+
+import (
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/whiteblock/cli/whiteblock/client"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"sort"
+	"strings"
+)
+
+// Outage is a pair of nodes which are unable to connect to each other, lowest node first
+type Outage [2]int
+
+// Topologies are the shapes of network which Topology accepts
+var Topologies = []string{"ring", "line", "star"}
+
+// outagesExcept gets the outages between every pair of the n nodes which are not connected
+func outagesExcept(n int, connected func(a int, b int) bool) []Outage {
+	out := []Outage{}
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			if !connected(a, b) {
+				out = append(out, Outage{a, b})
+			}
+		}
+	}
+	return out
+}
+
+func checkNode(n int, node int) error {
+	if node < 0 || node >= n {
+		return util.NewValidationError("node %d does not exist, the testnet has %d nodes", node, n)
+	}
+	return nil
+}
+
+// Split gets the outages which divide the n nodes into the given groups, where each group is
+// only able to reach the nodes within it. The nodes which are not in any group are left able
+// to reach every node.
+func Split(n int, groups []NodeList) ([]Outage, error) {
+	if len(groups) < 2 {
+		return nil, util.NewValidationError("expected at least 2 groups of nodes, given %d", len(groups))
+	}
+	groupOf := map[int]int{}
+	for i, group := range groups {
+		if len(group) == 0 {
+			return nil, util.NewValidationError("group %d has no nodes", i+1)
+		}
+		for _, node := range group {
+			err := checkNode(n, node)
+			if err != nil {
+				return nil, err
+			}
+			if other, ok := groupOf[node]; ok && other != i {
+				return nil, util.NewValidationError("node %d is in both group %d and group %d", node, other+1, i+1)
+			}
+			groupOf[node] = i
+		}
+	}
+	return outagesExcept(n, func(a int, b int) bool {
+		groupA, okA := groupOf[a]
+		groupB, okB := groupOf[b]
+		return !okA || !okB || groupA == groupB
+	}), nil
+}
+
+// Topology gets the outages which leave the n nodes connected in the given shape. In a ring each
+// node reaches the nodes either side of it, in a line the same except for the first and last
+// nodes, and in a star every node only reaches center.
+func Topology(n int, shape string, center int) ([]Outage, error) {
+	switch shape {
+	case "ring":
+		return outagesExcept(n, func(a int, b int) bool {
+			return b-a == 1 || (a == 0 && b == n-1)
+		}), nil
+	case "line":
+		return outagesExcept(n, func(a int, b int) bool {
+			return b-a == 1
+		}), nil
+	case "star":
+		err := checkNode(n, center)
+		if err != nil {
+			return nil, err
+		}
+		return outagesExcept(n, func(a int, b int) bool {
+			return a == center || b == center
+		}), nil
+	}
+	return nil, util.NewValidationError(`unknown topology "%s", expected one of %s`, shape, strings.Join(Topologies, ", "))
+}
+
+// Eclipse gets the outages which leave node only able to reach the nodes given by, such as
+// those of an attacker. The node is cut off entirely if by is empty.
+func Eclipse(n int, node int, by NodeList) ([]Outage, error) {
+	err := checkNode(n, node)
+	if err != nil {
+		return nil, err
+	}
+	allowed := map[int]bool{}
+	for _, peer := range by {
+		err = checkNode(n, peer)
+		if err != nil {
+			return nil, err
+		}
+		if peer == node {
+			return nil, util.NewValidationError("node %d cannot eclipse itself", node)
+		}
+		allowed[peer] = true
+	}
+	return outagesExcept(n, func(a int, b int) bool {
+		switch node {
+		case a:
+			return allowed[b]
+		case b:
+			return allowed[a]
+		}
+		return true
+	}), nil
+}
+
+// Groups gets the groups of nodes which are able to reach each other, directly or through
+// other nodes, once the outages are made
+func Groups(n int, outages []Outage) []NodeList {
+	cut := map[Outage]bool{}
+	for _, outage := range outages {
+		cut[outage] = true
+	}
+	seen := map[int]bool{}
+	out := []NodeList{}
+	for start := 0; start < n; start++ {
+		if seen[start] {
+			continue
+		}
+		group := NodeList{}
+		queue := []int{start}
+		seen[start] = true
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			group = append(group, node)
+			for peer := 0; peer < n; peer++ {
+				pair := Outage{node, peer}
+				if peer < node {
+					pair = Outage{peer, node}
+				}
+				if !seen[peer] && peer != node && !cut[pair] {
+					seen[peer] = true
+					queue = append(queue, peer)
+				}
+			}
+		}
+		sort.Ints(group)
+		out = append(out, group)
+	}
+	return out
+}
+
+// Peers gets the nodes each node is able to connect to directly once the outages are made
+func Peers(n int, outages []Outage) []NodeList {
+	cut := map[Outage]bool{}
+	for _, outage := range outages {
+		cut[outage] = true
+	}
+	out := make([]NodeList, n)
+	for a := 0; a < n; a++ {
+		out[a] = NodeList{}
+		for b := 0; b < n; b++ {
+			pair := Outage{a, b}
+			if b < a {
+				pair = Outage{b, a}
+			}
+			if a != b && !cut[pair] {
+				out[a] = append(out[a], b)
+			}
+		}
+	}
+	return out
+}
+
+// RenderGroups describes the groups of nodes as text, one group per line
+func RenderGroups(groups []NodeList) string {
+	if len(groups) == 1 {
+		return fmt.Sprintf("no partitions, all %d nodes are connected", len(groups[0]))
+	}
+	out := []string{}
+	for i, group := range groups {
+		noun := "nodes"
+		if len(group) == 1 {
+			noun = "node"
+		}
+		out = append(out, fmt.Sprintf("group %d: %s (%d %s)", i+1, group, len(group), noun))
+	}
+	return strings.Join(out, "\n")
+}
+
+// RenderPeers describes which nodes each node is able to connect to as text, one node per line
+func RenderPeers(peers []NodeList) string {
+	out := []string{}
+	for node, nodes := range peers {
+		if len(nodes) == 0 {
+			out = append(out, fmt.Sprintf("%d: none", node))
+			continue
+		}
+		out = append(out, fmt.Sprintf("%d: %s", node, nodes))
+	}
+	return strings.Join(out, "\n")
+}
+
+// ApplyOutages replaces the outages of a testnet with the given outages. If any of the outages
+// cannot be made, all of the outages are removed, so that the network is never left with only
+// part of a topology.
+func ApplyOutages(ctx context.Context, c *client.Client, testnetID string, outages []Outage) error {
+	err := c.RemoveAllOutages(ctx, testnetID)
+	if err != nil {
+		return err
+	}
+	for i, outage := range outages {
+		err = c.MakeOutage(ctx, testnetID, outage[0], outage[1])
+		if err == nil {
+			continue
+		}
+		log.WithFields(log.Fields{"made": i, "total": len(outages), "error": err}).Debug("undoing the outages")
+		rollbackErr := c.RemoveAllOutages(context.Background(), testnetID)
+		if rollbackErr != nil {
+			log.WithFields(log.Fields{"error": rollbackErr}).Error("unable to remove the outages made")
+			return fmt.Errorf("unable to cut %d from %d, %d of %d outages were left made: %w",
+				outage[0], outage[1], i, len(outages), err)
+		}
+		return fmt.Errorf("unable to cut %d from %d, so all of the outages were removed: %w", outage[0], outage[1], err)
+	}
+	return nil
+}
+
+// ParseGroups parses groups of nodes separated by slashes, such as "0,1,2 / 3,4 / 5-7". The
+// groups may be given across several arguments.
+func ParseGroups(args []string) ([]NodeList, error) {
+	out := []NodeList{}
+	for i, part := range strings.Split(strings.Join(args, " "), "/") {
+		group, err := ParseNodeList(strings.Replace(strings.TrimSpace(part), " ", ",", -1))
+		if err != nil {
+			return nil, util.NewValidationError("group %d: %v", i+1, err)
+		}
+		out = append(out, group)
+	}
+	return out, nil
+}
@@ -0,0 +1,153 @@
+package netconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/client"
+	"strconv"
+	"testing"
+)
+
+func TestTopologies(t *testing.T) {
+	var tests = []struct {
+		n       int
+		outages func(n int) ([]Outage, error)
+		groups  string
+		peers   string
+		err     bool
+	}{
+		{
+			n: 6,
+			outages: func(n int) ([]Outage, error) {
+				groups, err := ParseGroups([]string{"0,1", "/", "2", "/3-4"})
+				if err != nil {
+					return nil, err
+				}
+				return Split(n, groups)
+			},
+			groups: "[0-5]",
+			peers:  "[1,5 0,5 5 4-5 3,5 0-4]",
+		},
+		{
+			n: 5,
+			outages: func(n int) ([]Outage, error) {
+				return Split(n, []NodeList{{0, 1}, {2, 3, 4}})
+			},
+			groups: "[0-1 2-4]",
+			peers:  "[1 0 3-4 2,4 2-3]",
+		},
+		{
+			n:       5,
+			outages: func(n int) ([]Outage, error) { return Topology(n, "ring", 0) },
+			groups:  "[0-4]",
+			peers:   "[1,4 0,2 1,3 2,4 0,3]",
+		},
+		{
+			n:       4,
+			outages: func(n int) ([]Outage, error) { return Topology(n, "line", 0) },
+			groups:  "[0-3]",
+			peers:   "[1 0,2 1,3 2]",
+		},
+		{
+			n:       4,
+			outages: func(n int) ([]Outage, error) { return Topology(n, "star", 2) },
+			groups:  "[0-3]",
+			peers:   "[2 2 0-1,3 2]",
+		},
+		{
+			n:       4,
+			outages: func(n int) ([]Outage, error) { return Eclipse(n, 1, NodeList{3}) },
+			groups:  "[0-3]",
+			peers:   "[2-3 3 0,3 0-2]",
+		},
+		{
+			n:       3,
+			outages: func(n int) ([]Outage, error) { return Eclipse(n, 0, nil) },
+			groups:  "[0 1-2]",
+			peers:   "[ 2 1]",
+		},
+		{n: 4, outages: func(n int) ([]Outage, error) { return Topology(n, "mesh", 0) }, err: true},
+		{n: 4, outages: func(n int) ([]Outage, error) { return Topology(n, "star", 4) }, err: true},
+		{n: 4, outages: func(n int) ([]Outage, error) { return Eclipse(n, 1, NodeList{1}) }, err: true},
+		{n: 4, outages: func(n int) ([]Outage, error) { return Split(n, []NodeList{{0, 1}}) }, err: true},
+		{n: 4, outages: func(n int) ([]Outage, error) { return Split(n, []NodeList{{0, 1}, {1, 2}}) }, err: true},
+		{n: 4, outages: func(n int) ([]Outage, error) { return Split(n, []NodeList{{0}, {9}}) }, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			outages, err := tt.outages(tt.n)
+			if tt.err {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, outage := range outages {
+				if outage[0] >= outage[1] {
+					t.Errorf("expected the lowest node first in %v", outage)
+				}
+			}
+			groups := fmt.Sprint(Groups(tt.n, outages))
+			if groups != tt.groups {
+				t.Errorf("expected the groups %s, got %s", tt.groups, groups)
+			}
+			peers := fmt.Sprint(Peers(tt.n, outages))
+			if peers != tt.peers {
+				t.Errorf("expected the peers %s, got %s", tt.peers, peers)
+			}
+		})
+	}
+}
+
+func TestRenderGroups(t *testing.T) {
+	out := RenderGroups([]NodeList{{0, 1, 2}, {3}})
+	expected := "group 1: 0-2 (3 nodes)\ngroup 2: 3 (1 node)"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+// failingTransport fails the calls to the given method after the first limit of them
+type failingTransport struct {
+	fakeTransport
+	method string
+	limit  int
+}
+
+func (ft *failingTransport) Call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	ft.fakeTransport.Call(ctx, method, params, out)
+	if method == ft.method {
+		ft.limit--
+		if ft.limit < 0 {
+			return fmt.Errorf("%s failed", method)
+		}
+	}
+	return nil
+}
+
+func TestApplyOutages(t *testing.T) {
+	outages := []Outage{{0, 1}, {0, 2}, {1, 2}}
+	ft := &failingTransport{method: "make_outage", limit: 3}
+	err := ApplyOutages(context.Background(), client.New(ft), "testnet1", outages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "[remove_all_outages make_outage make_outage make_outage]"
+	if fmt.Sprint(ft.methods) != expected {
+		t.Errorf("expected the calls %s, got %v", expected, ft.methods)
+	}
+
+	ft = &failingTransport{method: "make_outage", limit: 1}
+	err = ApplyOutages(context.Background(), client.New(ft), "testnet1", outages)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected = "[remove_all_outages make_outage make_outage remove_all_outages]"
+	if fmt.Sprint(ft.methods) != expected {
+		t.Errorf("expected the outages to be removed, got the calls %v", ft.methods)
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
+	"github.com/whiteblock/cli/whiteblock/rpc"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"strings"
+)
+
+// nodeSelection is the nodes of a testnet picked out by a selector
+type nodeSelection struct {
+	Selector  selector.Selector
+	TestnetID string
+	Nodes     []Node
+	Selected  []int
+}
+
+// selectNodes gets the nodes of the testnet which are selected by expr, see selector.Usage
+func selectNodes(expr string) (*nodeSelection, error) {
+	sel, err := selector.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	testnetID, err := build.GetPreviousBuildID()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := rpcClient().Nodes(context.Background(), testnetID)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := sel.Select(nodes)
+	if err != nil {
+		return nil, err
+	}
+	return &nodeSelection{Selector: sel, TestnetID: testnetID, Nodes: nodes, Selected: selected}, nil
+}
+
+// reselect selects other nodes of the same testnet, without getting its nodes again
+func (s *nodeSelection) reselect(expr string) (*nodeSelection, error) {
+	sel, err := selector.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := sel.Select(s.Nodes)
+	if err != nil {
+		return nil, err
+	}
+	return &nodeSelection{Selector: sel, TestnetID: s.TestnetID, Nodes: s.Nodes, Selected: selected}, nil
+}
+
+// single gets the node selected, for the commands which may only act on one node
+func (s *nodeSelection) single() (int, error) {
+	if len(s.Selected) != 1 {
+		return 0, util.NewValidationError(`"%s" selects %d nodes, but only one node may be given`,
+			s.Selector.Expr, len(s.Selected))
+	}
+	return s.Selected[0], nil
+}
+
+// run runs fn on each of the selected nodes in parallel, then prints the result of each
+func (s *nodeSelection) run(fn func(ctx context.Context, node int) (interface{}, error)) error {
+	ctx, cancel := rpc.InterruptContext(context.Background())
+	defer cancel()
+	results := selector.FanOut(ctx, s.Selected, 0, fn)
+	return s.print(results)
+}
+
+// print prints the result of each node. If a single node was given by its index, its result is
+// printed alone as it was before selectors.
+func (s *nodeSelection) print(results []selector.Result) error {
+	if s.Selector.Single() && len(results) == 1 {
+		if err := results[0].Err(); err != nil {
+			return err
+		}
+		return util.Print(results[0].Result)
+	}
+	if util.IsStructuredOutput() {
+		if err := util.PrintTable(results, "node", "result", "error"); err != nil {
+			return err
+		}
+		return selector.Failures(results)
+	}
+	for _, res := range results {
+		if err := res.Err(); err != nil {
+			util.PrintStringError(fmt.Sprintf("node %d: %v", res.Node, err))
+			continue
+		}
+		text := formatResult(res.Result)
+		if strings.Contains(text, "\n") {
+			fmt.Printf("==> node %d <==\n%s\n", res.Node, strings.TrimRight(text, "\n"))
+			continue
+		}
+		fmt.Printf("node %d: %s\n", res.Node, text)
+	}
+	return selector.Failures(results)
+}
+
+func formatResult(result interface{}) string {
+	if str, ok := result.(string); ok {
+		return str
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprint(result)
+	}
+	return string(data)
+}
+
+// callOnNodes makes an rpc call for each of the selected nodes, with the params given for each
+func (s *nodeSelection) callOnNodes(method string, params func(node int) interface{}) error {
+	c := rpcClient()
+	return s.run(func(ctx context.Context, node int) (interface{}, error) {
+		var out interface{}
+		err := c.Call(ctx, method, params(node), &out)
+		return out, err
+	})
+}
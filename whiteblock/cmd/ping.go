@@ -1,42 +1,57 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
 	"github.com/whiteblock/cli/whiteblock/util"
-	"golang.org/x/sys/unix"
-	"log"
-	"os"
 )
 
 var pingCmd = &cobra.Command{
-	Use:   "ping <sending node> <receiving node>",
+	Use:   "ping <sending nodes> <receiving node>",
 	Short: "Ping will send packets to a node.",
 	Long: `
-Ping will send packets to a node and will output information
-
-Params: sending node, receiving node
-	`,
-
-	Run: func(cmd *cobra.Command, args []string) {
-
-		util.CheckArguments(cmd, args, 2, 2)
-		nodes := GetNodes()
-
-		sendingNodeNumber := util.CheckAndConvertInt(args[0], "sending node number")
-		receivingNodeNumber := util.CheckAndConvertInt(args[1], "receiving node number")
-
-		util.CheckIntegerBounds(cmd, "sending node number", sendingNodeNumber, 0, len(nodes)-1)
-		util.CheckIntegerBounds(cmd, "receiving node number", receivingNodeNumber, 0, len(nodes)-1)
-
-		log.Fatal(unix.Exec(conf.SSHBinary, []string{
-			"ssh", "-i", conf.SSHPrivateKey, "-o", "StrictHostKeyChecking no",
-			"-o", "UserKnownHostsFile=/dev/null", "-o", "PasswordAuthentication no", "-o", "ConnectTimeout=10", "-y",
-			"root@" + fmt.Sprintf(nodes[sendingNodeNumber].IP), "ping",
-			fmt.Sprintf(nodes[receivingNodeNumber].IP)}, os.Environ()))
+Ping will send packets to a node and will output information. When more than one node is sending,
+each sends --count packets in parallel.
+
+Params: sending nodes, receiving node
+
+` + selector.Usage,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 2, 2)
+		if err != nil {
+			return err
+		}
+		senders, err := selectNodes(args[0])
+		if err != nil {
+			return err
+		}
+		receivers, err := senders.reselect(args[1])
+		if err != nil {
+			return err
+		}
+		receiver, err := receivers.single()
+		if err != nil {
+			return err
+		}
+		receiverIP := receivers.Nodes[receiver].IP
+
+		if len(senders.Selected) > 1 {
+			count, err := cmd.Flags().GetInt("count")
+			if err != nil {
+				return err
+			}
+			return senders.run(func(ctx context.Context, node int) (interface{}, error) {
+				return sshRun(ctx, node, senders.Nodes[node].IP, fmt.Sprintf("ping -c %d %s", count, receiverIP))
+			})
+		}
+		return sshInteractive(senders.Nodes[senders.Selected[0]].IP, "ping "+receiverIP)
 	},
 }
 
 func init() {
+	pingCmd.Flags().IntP("count", "c", 4, "the number of packets each node sends, when more than one node is sending")
 	RootCmd.AddCommand(pingCmd)
 }
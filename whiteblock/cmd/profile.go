@@ -67,7 +67,10 @@ func GetBiome(org Organization) (map[string]interface{}, error) {
 		for _, biome := range org.Biomes {
 			biomeChoices = append(biomeChoices, biome["alias"].(string))
 		}
-		index := util.OptionListPrompt("Please select a biome", biomeChoices)
+		index, err := util.OptionListPrompt("Please select a biome", biomeChoices)
+		if err != nil {
+			return nil, err
+		}
 		util.Set("biome", fmt.Sprint(org.Biomes[index]["id"]))
 
 		return org.Biomes[index], nil
@@ -110,6 +113,9 @@ func LoadBiomeAddress() error {
 	if err != nil {
 		return err
 	}
+	if sctx, err := util.GetContext(util.ActiveContext()); err == nil && len(sctx.ServerAddr) > 0 {
+		return nil //the server given with the context wins over the biome
+	}
 	conf.ServerAddr = biome["host"].(string) + ":5001"
 	return nil
 }
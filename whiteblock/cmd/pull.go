@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"github.com/pkg/sftp"
+	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
+	"github.com/whiteblock/cli/whiteblock/rpc"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// pullRemotePaths gets the absolute paths on the node which match pattern
+func pullRemotePaths(client *sftp.Client, pattern string) ([]string, error) {
+	if !path.IsAbs(pattern) {
+		wd, err := client.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		pattern = path.Join(wd, pattern)
+	}
+	matches, err := client.Glob(pattern)
+	if err != nil {
+		return nil, util.NewValidationError("invalid pattern %s: %v", pattern, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("nothing on the node matches %s", pattern)
+	}
+	return matches, nil
+}
+
+// pullArchived has the node put the paths into a gzipped tar archive, which is then copied and
+// extracted into dir. The archive is left on the node if the pull is stopped part way through, so
+// that the next pull picks up where it left off, as long as none of the paths have changed since
+// it was made. It is removed once it has been extracted, or the pull fails.
+func pullArchived(ctx context.Context, client *util.SshClient, sftpClient *sftp.Client,
+	paths []string, dir string) (out util.PullStats, err error) {
+
+	archive := fmt.Sprintf("/tmp/whiteblock-pull-%x.tar.gz", sha1.Sum([]byte(strings.Join(paths, "\n"))))
+	defer func() {
+		if err != nil && ctx.Err() == nil {
+			sftpClient.Remove(archive + ".tmp")
+			sftpClient.Remove(archive)
+		}
+	}()
+	fresh := false
+	if _, err := sftpClient.Stat(archive); err == nil {
+		args := append([]string{"find"}, paths...)
+		newer, _, code, err := client.RunContext(ctx, util.ShellJoin(append(args, "-newer", archive, "-print", "-quit")))
+		if err != nil {
+			return out, err
+		}
+		fresh = code == 0 && len(strings.TrimSpace(newer)) == 0
+	}
+	if !fresh {
+		args := []string{"tar", "-czf", archive + ".tmp", "-C", "/", "--"}
+		for _, p := range paths {
+			args = append(args, strings.TrimPrefix(p, "/"))
+		}
+		// tar exits 1 when a file changed while it was being read, which is expected of logs
+		command := util.ShellJoin(args) + "; [ $? -le 1 ] && mv " + archive + ".tmp " + archive
+		_, stderr, code, err := client.RunContext(ctx, command)
+		if err != nil {
+			return out, err
+		}
+		if code != 0 {
+			return out, fmt.Errorf("could not archive the files on the node: %s", strings.TrimSpace(stderr))
+		}
+	}
+
+	local := dir + ".tar.gz"
+	n, _, err := util.PullFile(sftpClient, archive, local)
+	out.Bytes = n
+	if err != nil {
+		return out, err
+	}
+	file, err := os.Open(local)
+	if err != nil {
+		return out, err
+	}
+	defer os.Remove(local)
+	defer file.Close()
+	out.Files, err = util.ExtractTarGz(file, dir)
+	if err != nil {
+		return out, err
+	}
+	return out, sftpClient.Remove(archive)
+}
+
+// pullFromNode copies everything which matches pattern on the node at ip into dir, at the same
+// path as it is on the node
+func pullFromNode(ctx context.Context, ip string, pattern string, dir string, compress bool) (interface{}, error) {
+	client, err := util.NewSshClient(ip)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// closing the connection stops any copy, leaving what was copied for the next pull
+		select {
+		case <-ctx.Done():
+			client.Close()
+		case <-done:
+		}
+	}()
+
+	sftpClient, err := client.SFTP()
+	if err != nil {
+		return nil, err
+	}
+	defer sftpClient.Close()
+	paths, err := pullRemotePaths(sftpClient, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := util.PullStats{}
+	if compress {
+		stats, err = pullArchived(ctx, client, sftpClient, paths, dir)
+	} else {
+		for _, p := range paths {
+			var res util.PullStats
+			res, err = util.PullPath(sftpClient, p, filepath.Join(dir, filepath.FromSlash(p)))
+			stats.Files += res.Files
+			stats.Unchanged += res.Unchanged
+			stats.Bytes += res.Bytes
+			if err != nil {
+				break
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("stopped after copying %d bytes, pull again to resume", stats.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	plural := "s"
+	if stats.Files == 1 {
+		plural = ""
+	}
+	msg := fmt.Sprintf("pulled %d file%s (%d bytes) into %s", stats.Files, plural, stats.Bytes, dir)
+	if stats.Unchanged > 0 {
+		msg += fmt.Sprintf(", %d of which were already pulled", stats.Unchanged)
+	}
+	return msg, nil
+}
+
+var pullCmd = &cobra.Command{
+	Use:   "pull <nodes> <remote path> <local dir>",
+	Short: "Copy files and directories from the nodes",
+	Long: `
+Pull copies the files and directories on each of the nodes which match the remote path, which may
+be a pattern such as /var/log/*.log, into a directory for each node inside of the local dir. They
+are put at the same path inside of it as they are on the node, so /geth/genesis.json from node 2
+goes in <local dir>/2/geth/genesis.json.
+
+Pulling again resumes any file which was not completely copied, as long as it has not changed on
+the node since, and skips any which has already been copied and has not changed. With --compress,
+the files are put in a gzipped tar archive on the node first, which is then copied and extracted.
+The archive of a pull which was stopped is reused by the next pull of the same paths, unless any
+of them have changed since it was made.
+
+Example:
+	whiteblock pull all /geth/genesis.json ./pulled
+	whiteblock pull label=validator '/var/log/*.log' ./logs --compress
+
+` + selector.Usage,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 3, 3)
+		if err != nil {
+			return err
+		}
+		compress, err := cmd.Flags().GetBool("compress")
+		if err != nil {
+			return err
+		}
+		parallel, err := cmd.Flags().GetInt("parallel")
+		if err != nil {
+			return err
+		}
+		nodes, err := selectNodes(args[0])
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := rpc.InterruptContext(context.Background())
+		defer cancel()
+		results := selector.FanOut(ctx, nodes.Selected, parallel, func(ctx context.Context, node int) (interface{}, error) {
+			return pullFromNode(ctx, nodes.Nodes[node].IP, args[1], filepath.Join(args[2], strconv.Itoa(node)), compress)
+		})
+		return nodes.print(results)
+	},
+}
+
+func init() {
+	pullCmd.Flags().BoolP("compress", "z", false, "compress the files on the node before copying them")
+	pullCmd.Flags().IntP("parallel", "p", 10, "the number of nodes to pull from at once, 0 for all of them")
+	RootCmd.AddCommand(pullCmd)
+}
@@ -2,18 +2,30 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
-	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
 	"github.com/whiteblock/cli/whiteblock/util"
+	"strconv"
 )
 
 var restartNodeCmd = &cobra.Command{
-	Use:   "restart [node number]",
+	Use:   "restart <nodes>",
 	Short: "Attempt to restart a node",
 	Long: `
-Kill a node by sending SIGINT and then re-run the original command used to run it`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 1)
-		util.JsonRpcCallAndPrint("restart_node", []interface{}{build.GetPreviousBuildID(), args[0]})
+Kill each of the nodes by sending SIGINT and then re-run the original command used to run it
+
+` + selector.Usage,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		nodes, err := selectNodes(args[0])
+		if err != nil {
+			return err
+		}
+		return nodes.callOnNodes("restart_node", func(node int) interface{} {
+			return []interface{}{nodes.TestnetID, strconv.Itoa(node)}
+		})
 	},
 }
 
@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/client"
 	"github.com/whiteblock/cli/whiteblock/util"
 	"os"
 )
@@ -12,6 +15,31 @@ import (
 
 var conf = util.GetConfig()
 
+// rpcClient gets a client for the server which is currently in use
+func rpcClient() *client.Client {
+	httpClient, err := util.HTTPClient()
+	if err != nil {
+		return client.New(errTransport{err})
+	}
+	return client.New(client.HTTPTransport{
+		URL:           util.RPCURL(),
+		Client:        httpClient,
+		Header:        util.AuthHeader(),
+		Attempts:      conf.RPCRetries,
+		Timeout:       util.RPCTimeout(),
+		Interruptible: true,
+	})
+}
+
+// errTransport fails every call, for when the transport could not be set up
+type errTransport struct {
+	err error
+}
+
+func (t errTransport) Call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	return t.err
+}
+
 var RootCmd = &cobra.Command{
 	Use:     "whiteblock",
 	Version: VERSION,
@@ -20,12 +48,82 @@ var RootCmd = &cobra.Command{
 Documentation, usages, and exmaples can be found at https://docs.whiteblock.io/.
 To report an issue: https://github.com/whiteblock/cli/issues/new?assignees=&labels=&template=bug_report.md&title=
 	`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		err = util.SetOutputFormat(output)
+		if err != nil {
+			return err
+		}
+		context, err := cmd.Flags().GetString("context")
+		if err != nil {
+			return err
+		}
+		err = loadContext(context)
+		if err != nil {
+			return err
+		}
+		testnet, err := cmd.Flags().GetString("testnet")
+		if err != nil {
+			return err
+		}
+		util.SelectTestnet(testnet)
+		return nil
+	},
 }
 
+// loadContext switches over to the given context, or the current context if name is empty,
+// and loads the profile stored for it
+func loadContext(name string) error {
+	if len(name) == 0 {
+		name = conf.Context
+	}
+	if len(name) == 0 {
+		name = util.CurrentContextName()
+	}
+	err := util.UseContext(name)
+	if err != nil {
+		return err
+	}
+	//Possibly update this on load.
+	if util.Exists("profile") {
+
+		err := LoadProfile() //Load the profile into the profile global
+		if err != nil {
+			util.Delete("profile")
+			return err
+		}
+
+		err = LoadBiomeAddress()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Execute runs the root command, exiting with the exit code for the category of the error returned
+// by the command, if any.
 func Execute() {
-	if err := RootCmd.Execute(); err != nil {
-		util.PrintErrorFatal(err)
+	RootCmd.SilenceErrors = true
+	RootCmd.SilenceUsage = true
+	RootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return util.ValidationError{Err: err}
+	})
+	cmd, err := RootCmd.ExecuteC()
+	if err == nil {
+		return
+	}
+	if exit, ok := err.(util.RemoteExitError); ok {
+		// the command run on the node has already given its own output
+		os.Exit(exit.Code)
+	}
+	if _, ok := err.(util.ValidationError); ok && util.OutputFormat() == util.OutputDefault {
+		fmt.Println(cmd.UsageString())
 	}
+	util.PrintErrorFatal(err)
 }
 
 var completionCmd = &cobra.Command{
@@ -35,27 +133,16 @@ var completionCmd = &cobra.Command{
 	Long: `To load completion run
 . <(whiteblock completion)
 `,
-	Run: func(cmd *cobra.Command, args []string) {
-		RootCmd.GenBashCompletion(os.Stdout)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return RootCmd.GenBashCompletion(os.Stdout)
 	},
 }
 
 func init() {
 	util.CheckLoad()
 	//RootCmd.PersistentFlags().StringVarP(&serverAddr, "server-addr", "a", "localhost:5000", "server address with port 5000")
+	RootCmd.PersistentFlags().String("output", conf.Output, "output format, one of json, yaml or table")
+	RootCmd.PersistentFlags().String("context", "", "the server context to use, instead of the current one")
+	RootCmd.PersistentFlags().String("testnet", "", "the testnet to use, by name or id, instead of the previous build")
 	RootCmd.AddCommand(completionCmd)
-	//Possibly update this on load.
-	if util.Exists("profile") {
-
-		err := LoadProfile() //Load the profile into the profile global
-		if err != nil {
-			util.Delete("profile")
-			util.PrintErrorFatal(err)
-		}
-
-		err = LoadBiomeAddress()
-		if err != nil {
-			util.PrintErrorFatal(err)
-		}
-	}
 }
@@ -1,37 +1,95 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
 	"github.com/whiteblock/cli/whiteblock/util"
-	"golang.org/x/sys/unix"
-	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 )
 
+// copyToNode copies source on this machine to destination on the node at ip
+func copyToNode(ip string, source string, destination string) (interface{}, error) {
+	client, err := util.NewSshClient(ip)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	err = client.Upload(source, destination)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("copied %s to %s", source, destination), nil
+}
+
+// copyFromNode copies source on the node at ip to destination on this machine
+func copyFromNode(ip string, source string, destination string) (interface{}, error) {
+	client, err := util.NewSshClient(ip)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	err = client.Download(source, destination)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("copied %s to %s", source, destination), nil
+}
+
 var scpCmd = &cobra.Command{
-	Use:   "scp <node> <source> <destination>",
+	Use:   "scp <nodes> <source> <destination>",
 	Short: "Scp will copy a file into the node.",
 	Long: `
 
-Scp will allow the user to copy a file and add it to a node.
-Format: <node>, <source>, <destination>
-Params: node number, file/dir source, file/dir destination
-	`,
-
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 3, 3)
-		nodes := GetNodes()
-		nodeNumber := util.CheckAndConvertInt(args[0], "node")
-		util.CheckIntegerBounds(cmd, "node number", nodeNumber, 0, len(nodes)-1)
-
-		log.Fatal(unix.Exec("/usr/bin/scp", []string{"scp", "-i", conf.SSHPrivateKey,
-			"-r", "-o", "UserKnownHostsFile=/dev/null",
-			"-o", "StrictHostKeyChecking no", args[1],
-			"root@" + fmt.Sprintf(nodes[nodeNumber].IP) + ":" + args[2]}, os.Environ()))
+Scp will allow the user to copy a file and add it to each of the nodes, or with --from-nodes to
+copy a file from each of the nodes to this machine. Directories are copied along with everything
+in them. When copying from more than one node, the copy from each node is put in a directory
+named after the node, inside of the destination.
+Format: <nodes>, <source>, <destination>
+Params: nodes, file/dir source, file/dir destination
+
+Example:
+	whiteblock scp all ./genesis.json /geth/genesis.json
+	whiteblock scp label=validator /output.log ./logs --from-nodes
+
+` + selector.Usage,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 3, 3)
+		if err != nil {
+			return err
+		}
+		fromNodes, err := cmd.Flags().GetBool("from-nodes")
+		if err != nil {
+			return err
+		}
+		nodes, err := selectNodes(args[0])
+		if err != nil {
+			return err
+		}
+		if !fromNodes {
+			return nodes.run(func(ctx context.Context, node int) (interface{}, error) {
+				return copyToNode(nodes.Nodes[node].IP, args[1], args[2])
+			})
+		}
+		return nodes.run(func(ctx context.Context, node int) (interface{}, error) {
+			destination := args[2]
+			if len(nodes.Selected) > 1 {
+				destination = filepath.Join(destination, strconv.Itoa(node))
+				err := os.MkdirAll(destination, 0755)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return copyFromNode(nodes.Nodes[node].IP, args[1], destination)
+		})
 	},
 }
 
 func init() {
+	scpCmd.Flags().Bool("from-nodes", false, "copy the source on the nodes to the destination on this machine")
 	RootCmd.AddCommand(scpCmd)
 }
@@ -0,0 +1,81 @@
+package selector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Result is the outcome of running something on a node
+type Result struct {
+	Node   int         `json:"node"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+
+	err error
+}
+
+// Err gets the error the node failed with, if any
+func (r Result) Err() error {
+	return r.err
+}
+
+// FanOut runs fn on each of the nodes, with at most limit running at once, or all of them at
+// once if limit is 0. The results are given in the order of the nodes.
+func FanOut(ctx context.Context, nodes []int, limit int, fn func(ctx context.Context, node int) (interface{}, error)) []Result {
+	if limit <= 0 || limit > len(nodes) {
+		limit = len(nodes)
+	}
+	out := make([]Result, len(nodes))
+	sem := make(chan struct{}, limit)
+	wg := sync.WaitGroup{}
+	for i, node := range nodes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, node int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res, err := fn(ctx, node)
+			out[i] = Result{Node: node, Result: res, err: err}
+			if err != nil {
+				out[i].Error = err.Error()
+			}
+		}(i, node)
+	}
+	wg.Wait()
+	return out
+}
+
+// FailedError is given when a command fails on some of the nodes it was run on
+type FailedError struct {
+	Failed int
+	Total  int
+	// Err is the error of the first node to fail
+	Err error
+}
+
+func (e FailedError) Error() string {
+	return fmt.Sprintf("failed on %d of %d nodes: %v", e.Failed, e.Total, e.Err)
+}
+
+func (e FailedError) Unwrap() error {
+	return e.Err
+}
+
+// Failures gets a FailedError if any of the nodes failed, or nil if none did
+func Failures(results []Result) error {
+	out := FailedError{Total: len(results)}
+	for _, res := range results {
+		if res.err == nil {
+			continue
+		}
+		if out.Failed == 0 {
+			out.Err = res.err
+		}
+		out.Failed++
+	}
+	if out.Failed == 0 {
+		return nil
+	}
+	return out
+}
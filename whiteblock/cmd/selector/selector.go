@@ -0,0 +1,251 @@
+// Package selector picks the nodes of a testnet for a command to act on, and runs the command
+// on each of them in parallel.
+package selector
+
+import (
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Usage describes the selector syntax, for the help of the commands which take one
+const Usage = `Nodes are selected by a selector, which is any of the following, separated by commas:
+	all             every node
+	3, 0-4          a node, or a range of nodes, by index
+	label=validator the nodes whose field is the value, with the fields id, ip, image, label,
+	                protocol, server, localId and absNum
+	image=~geth     the nodes whose field matches a regular expression, which may have commas
+	                in it as long as they are not followed by another selector
+	label!=observer the nodes whose field is not the value, or !~ to not match
+Such as 0-2,label=validator`
+
+// Fields are the fields of a node which may be selected on
+var Fields = []string{"id", "ip", "image", "label", "protocol", "server", "localId", "absNum"}
+
+type term struct {
+	all    bool
+	from   int
+	to     int
+	field  string
+	op     string
+	value  string
+	regexp *regexp.Regexp
+}
+
+// Selector selects nodes, given as in Usage
+type Selector struct {
+	Expr  string
+	terms []term
+}
+
+// maxNode is the largest index of a node which may be given, so that a range such as
+// 0-2000000000 is not expanded before the nodes of the testnet are known
+const maxNode = 100000
+
+// operators are the comparisons of a field, longest first so that != is not taken for =
+var operators = []string{"!=", "=~", "!~", "="}
+
+func parseTerm(expr string) (term, error) {
+	if expr == "all" || expr == "*" {
+		return term{all: true}, nil
+	}
+	for _, op := range operators {
+		i := strings.Index(expr, op)
+		if i == -1 {
+			continue
+		}
+		t := term{field: fieldName(expr[:i]), op: op, value: expr[i+len(op):]}
+		if len(t.field) == 0 {
+			return term{}, fmt.Errorf(`unknown field "%s", expected one of %s`, expr[:i], strings.Join(Fields, ", "))
+		}
+		if op == "=~" || op == "!~" {
+			re, err := regexp.Compile(t.value)
+			if err != nil {
+				return term{}, fmt.Errorf("invalid regular expression %s: %v", t.value, err)
+			}
+			t.regexp = re
+		}
+		return t, nil
+	}
+	bounds := strings.SplitN(expr, "-", 2)
+	from, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return term{}, fmt.Errorf(`expected a node, a range of nodes, all or field=value, got "%s"`, expr)
+	}
+	to := from
+	if len(bounds) == 2 {
+		to, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+		if err != nil || to < from {
+			return term{}, fmt.Errorf(`invalid range of nodes "%s"`, expr)
+		}
+	}
+	if from < 0 {
+		return term{}, fmt.Errorf(`invalid node "%s"`, expr)
+	}
+	if to > maxNode {
+		return term{}, fmt.Errorf(`node %d is past the largest node supported, %d`, to, maxNode)
+	}
+	return term{from: from, to: to}, nil
+}
+
+// fieldName gets the name of the field as given in Fields, ignoring case, or "" if there is none
+func fieldName(name string) string {
+	for _, field := range Fields {
+		if strings.EqualFold(field, strings.TrimSpace(name)) {
+			return field
+		}
+	}
+	return ""
+}
+
+// nodeRange matches a node or a range of nodes, such as 3 or 0-4
+var nodeRange = regexp.MustCompile(`^\d+\s*(-\s*\d+)?$`)
+
+// startsTerm checks if the part of a selector after a comma is a term of its own, rather than
+// the rest of a regular expression with a comma in it
+func startsTerm(part string) bool {
+	part = strings.TrimSpace(part)
+	if part == "all" || part == "*" || nodeRange.MatchString(part) {
+		return true
+	}
+	for _, op := range operators {
+		if i := strings.Index(part, op); i != -1 {
+			return len(fieldName(part[:i])) > 0
+		}
+	}
+	return false
+}
+
+// splitTerms splits a selector into its terms at the commas, except for those in a regular
+// expression which are not followed by another term, such as in image=~geth{1,2}
+func splitTerms(expr string) []string {
+	out := []string{}
+	for _, part := range strings.Split(expr, ",") {
+		if len(out) > 0 && !startsTerm(part) {
+			prev := out[len(out)-1]
+			if strings.Contains(prev, "=~") || strings.Contains(prev, "!~") {
+				out[len(out)-1] = prev + "," + part
+				continue
+			}
+		}
+		out = append(out, part)
+	}
+	return out
+}
+
+// Parse parses a selector, such as "0-2,label=validator"
+func Parse(expr string) (Selector, error) {
+	out := Selector{Expr: expr}
+	if len(strings.TrimSpace(expr)) == 0 {
+		return out, util.NewValidationError("no nodes selected")
+	}
+	for _, part := range splitTerms(expr) {
+		t, err := parseTerm(strings.TrimSpace(part))
+		if err != nil {
+			return Selector{}, util.NewValidationError("%s: %v", expr, err)
+		}
+		out.terms = append(out.terms, t)
+	}
+	return out, nil
+}
+
+// Indexes gets the nodes of a selector made only of nodes and ranges of nodes, such as "0-2,5",
+// which can be resolved without the nodes of the testnet. The nodes are in the order given.
+func Indexes(expr string) ([]int, error) {
+	s, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	out := []int{}
+	for _, t := range s.terms {
+		if t.all || len(t.field) > 0 {
+			return nil, util.NewValidationError(`%s: expected nodes and ranges of nodes such as "0-2,5"`, expr)
+		}
+		for node := t.from; node <= t.to; node++ {
+			out = append(out, node)
+		}
+	}
+	return out, nil
+}
+
+// Single checks whether the selector is just the index of a node, as the commands once took
+func (s Selector) Single() bool {
+	return len(s.terms) == 1 && !s.terms[0].all && len(s.terms[0].field) == 0 && s.terms[0].from == s.terms[0].to
+}
+
+func fieldValue(node build.Node, field string) string {
+	switch field {
+	case "id":
+		return node.ID
+	case "ip":
+		return node.IP
+	case "image":
+		return node.Image
+	case "label":
+		return node.Label
+	case "protocol":
+		return node.Protocol
+	case "server":
+		return strconv.Itoa(node.Server)
+	case "localId":
+		return strconv.Itoa(node.LocalID)
+	case "absNum":
+		return strconv.Itoa(node.AbsoluteNum)
+	}
+	return ""
+}
+
+func (t term) matches(node build.Node) bool {
+	value := fieldValue(node, t.field)
+	switch t.op {
+	case "=":
+		return value == t.value
+	case "!=":
+		return value != t.value
+	case "=~":
+		return t.regexp.MatchString(value)
+	case "!~":
+		return !t.regexp.MatchString(value)
+	}
+	return false
+}
+
+// Select gets the indexes of the nodes which the selector selects, in order. It is an error
+// for an index to be out of range, or for no node to be selected.
+func (s Selector) Select(nodes []build.Node) ([]int, error) {
+	selected := map[int]bool{}
+	for _, t := range s.terms {
+		switch {
+		case t.all:
+			for i := range nodes {
+				selected[i] = true
+			}
+		case len(t.field) > 0:
+			for i, node := range nodes {
+				if t.matches(node) {
+					selected[i] = true
+				}
+			}
+		default:
+			if t.to >= len(nodes) {
+				return nil, util.NewValidationError("node %d does not exist, the testnet has %d nodes", t.to, len(nodes))
+			}
+			for i := t.from; i <= t.to; i++ {
+				selected[i] = true
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return nil, util.NewValidationError(`"%s" does not select any nodes`, s.Expr)
+	}
+	out := []int{}
+	for i := range selected {
+		out = append(out, i)
+	}
+	sort.Ints(out)
+	return out, nil
+}
@@ -0,0 +1,173 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testNodes = []build.Node{
+	{ID: "a", IP: "10.0.0.1", Server: 1, Label: "validator", Image: "gcr.io/whiteblock/geth:master"},
+	{ID: "b", IP: "10.0.0.2", Server: 1, Label: "validator", Image: "gcr.io/whiteblock/geth:master"},
+	{ID: "c", IP: "10.0.0.3", Server: 2, Label: "observer", Image: "gcr.io/whiteblock/parity:stable"},
+	{ID: "d", IP: "10.0.0.4", Server: 2, Label: "observer", Image: "gcr.io/whiteblock/geth:dev"},
+}
+
+func TestSelect(t *testing.T) {
+	var tests = []struct {
+		expr     string
+		expected []int
+		single   bool
+		err      bool
+	}{
+		{expr: "2", expected: []int{2}, single: true},
+		{expr: "all", expected: []int{0, 1, 2, 3}},
+		{expr: "1-2", expected: []int{1, 2}},
+		{expr: "3,0 , 1", expected: []int{0, 1, 3}},
+		{expr: "label=validator", expected: []int{0, 1}},
+		{expr: "LABEL=observer,0", expected: []int{0, 2, 3}},
+		{expr: "image=~geth", expected: []int{0, 1, 3}},
+		{expr: "image!~geth", expected: []int{2}},
+		{expr: "image=~geth:[a-z]{3,3}$,0", expected: []int{0, 3}},
+		{expr: "image=~(parity|geth):[a-z]{3,6}$,label=validator", expected: []int{0, 1, 2, 3}},
+		{expr: "image=~y:s{1,2}, 1", expected: []int{1, 2}},
+		{expr: "label!=validator", expected: []int{2, 3}},
+		{expr: "server=2", expected: []int{2, 3}},
+		{expr: "ip=10.0.0.4", expected: []int{3}},
+		{expr: "label=miner", err: true},
+		{expr: "4", err: true},
+		{expr: "2-1", err: true},
+		{expr: "-1", err: true},
+		{expr: "color=red", err: true},
+		{expr: "image=~(", err: true},
+		{expr: "", err: true},
+		{expr: "first", err: true},
+		{expr: "0,first", err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			sel, err := Parse(tt.expr)
+			var out []int
+			if err == nil {
+				out, err = sel.Select(testNodes)
+			}
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected an error for %s, got %v", tt.expr, out)
+				} else if util.ExitCode(err) != util.ExitValidation {
+					t.Errorf("expected a validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(out, tt.expected) {
+				t.Errorf("return value of Select does not match expected value: %v", out)
+			}
+			if sel.Single() != tt.single {
+				t.Errorf("expected Single to be %v", tt.single)
+			}
+		})
+	}
+}
+
+func TestIndexes(t *testing.T) {
+	var tests = []struct {
+		expr     string
+		expected []int
+		err      bool
+	}{
+		{expr: "0-2,5", expected: []int{0, 1, 2, 5}},
+		{expr: " 4 , 1 ", expected: []int{4, 1}},
+		{expr: "all", err: true},
+		{expr: "label=validator", err: true},
+		{expr: "2-1", err: true},
+		{expr: "0-2000000000", err: true},
+		{expr: "99999999999", err: true},
+		{expr: "", err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			out, err := Indexes(tt.expr)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected an error for %s, got %v", tt.expr, out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(out, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, out)
+			}
+		})
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	var tests = []struct {
+		nodes   []int
+		limit   int
+		maxSeen int32
+		failed  int
+	}{
+		{nodes: []int{0, 1, 2, 3}, limit: 0, maxSeen: 4},
+		{nodes: []int{0, 1, 2, 3, 4, 5}, limit: 2, maxSeen: 2, failed: 1},
+		{nodes: []int{1, 3}, limit: 5, maxSeen: 2},
+		{nodes: []int{5, 6, 7}, limit: 1, maxSeen: 1, failed: 3},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var running, seen int32
+			results := FanOut(context.Background(), tt.nodes, tt.limit, func(ctx context.Context, node int) (interface{}, error) {
+				now := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					max := atomic.LoadInt32(&seen)
+					if now <= max || atomic.CompareAndSwapInt32(&seen, max, now) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				if node >= 5 {
+					return nil, fmt.Errorf("node %d failed", node)
+				}
+				return node * 10, nil
+			})
+			if seen != tt.maxSeen {
+				t.Errorf("expected at most %d running at once, got %d", tt.maxSeen, seen)
+			}
+			for j, res := range results {
+				if res.Node != tt.nodes[j] {
+					t.Errorf("expected the results in order of the nodes, got %v", results)
+				}
+				if res.Err() == nil && res.Result != res.Node*10 {
+					t.Errorf("unexpected result for node %d: %v", res.Node, res.Result)
+				}
+			}
+			err := Failures(results)
+			var failed FailedError
+			if tt.failed == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &failed) || failed.Failed != tt.failed || failed.Total != len(tt.nodes) {
+				t.Errorf("expected %d of %d nodes to fail, got %v", tt.failed, len(tt.nodes), err)
+			}
+		})
+	}
+}
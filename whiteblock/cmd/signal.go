@@ -2,24 +2,35 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
-	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
 	"github.com/whiteblock/cli/whiteblock/util"
+	"strconv"
 )
 
 var signalCmd = &cobra.Command{
 	Aliases: []string{"raise"},
-	Use:     "signal <node> [sig=SIGTERM]",
+	Use:     "signal <nodes> [sig=SIGTERM]",
 	Short:   "Raise a signal to a node's main process",
-	Long:    `Sends a signal to the node's main process, see signal(7) for more details about signal`,
+	Long: `Sends a signal to the main process of each of the nodes, see signal(7) for more details about signal
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 2)
+` + selector.Usage,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 2)
+		if err != nil {
+			return err
+		}
 		signal := "SIGTERM"
 		if len(args) > 1 {
 			signal = args[1]
 		}
-
-		util.JsonRpcCallAndPrint("signal_node", []interface{}{build.GetPreviousBuildID(), args[0], signal})
+		nodes, err := selectNodes(args[0])
+		if err != nil {
+			return err
+		}
+		return nodes.callOnNodes("signal_node", func(node int) interface{} {
+			return []interface{}{nodes.TestnetID, strconv.Itoa(node), signal}
+		})
 	},
 }
 
@@ -110,7 +110,11 @@ type WebsocketTransport struct {
 }
 
 func (wst *WebsocketTransport) Connect(url string) (conn transport.Connection, err error) {
-	dialer := websocket.Dialer{}
+	tlsConf, err := util.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	dialer := websocket.Dialer{TLSClientConfig: tlsConf}
 
 	auth, err := util.CreateAuthNHeader()
 	if err != nil {
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/whiteblock/cli/whiteblock/util"
 	"time"
 )
 
@@ -11,6 +12,9 @@ type Spinner struct {
 }
 
 func (this *Spinner) Run(ms int) {
+	if !util.IsTTY() || util.OutputFormat() != util.OutputDefault {
+		return //only noise when the output is not being watched
+	}
 	go func() {
 		states := []string{"/", "-", "\\", "|", "/", "-", "\\", "|"}
 
@@ -29,21 +29,24 @@ sql list will return a list of current tables in the database
 Response: JSON representation of the table list in the database
 	`,
 
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		payload := []byte{}
 
 		id, err := cmd.Flags().GetInt("organization-id")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 
 		if id == 0 {
-			id = getOrgId()
+			id, err = getOrgId()
+			if err != nil {
+				return err
+			}
 		}
 
 		data, err := apiRequest(fmt.Sprintf("/organizations/%d/dw/tables", id), "GET", payload)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 
 		var tables struct {
@@ -62,7 +65,7 @@ Response: JSON representation of the table list in the database
 
 		err = json.Unmarshal(data, &tables)
 
-		util.Print(tables)
+		return util.Print(tables)
 	},
 }
 
@@ -77,42 +80,48 @@ var sqlQueryCmd = &cobra.Command{
 This command will run a SQL query to the database to retrieve structured log data
 	`,
 
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		query := SqlQueryRequestPayload{Q: args[0]}
 		payload, err := json.Marshal(query)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 
 		id, err := cmd.Flags().GetInt("organization-id")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 
 		if id == 0 {
-			id = getOrgId()
+			id, err = getOrgId()
+			if err != nil {
+				return err
+			}
 		}
 
 		data, err := apiRequest(fmt.Sprintf("/organizations/%d/dw/metrics", id), "POST", payload)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 
 		var response metrics
 		err = json.Unmarshal(data, &response)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 
 		outRows := make([][]interface{}, 0)
 		outRows = append(outRows, response.Rows...)
 
 		for response.PageToken != "" {
-			response = response.next(id)
+			response, err = response.next(id)
+			if err != nil {
+				return err
+			}
 			outRows = append(outRows, response.Rows...)
 		}
 
-		util.Print(
+		return util.Print(
 			struct {
 				Schema interface{}     `json:"schema"`
 				Rows   [][]interface{} `json:"rows"`
@@ -145,7 +154,11 @@ func apiRequest(path string, method string, body []byte) ([]byte, error) {
 	}
 	request.Close = true
 
-	resp, err := http.DefaultClient.Do(request)
+	httpClient, err := util.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(request)
 	if err != nil {
 		return nil, err
 	}
@@ -176,34 +189,33 @@ type metrics struct {
 	Error     interface{}     `json:"error"`
 }
 
-func (m *metrics) next(id int) metrics {
+func (m *metrics) next(id int) (metrics, error) {
 	path := fmt.Sprintf("/organizations/%d/dw/metrics?job_id=%s&page_token=%s", id, m.JobReference.JobID, m.PageToken)
+	response := metrics{}
 	data, err := apiRequest(path, "GET", []byte{})
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return response, err
 	}
 
-	response := metrics{}
-
 	err = json.Unmarshal(data, &response)
-	if err != nil {
-		util.PrintErrorFatal(err)
-	}
-
-	return response
+	return response, err
 }
 
-func getOrgId() int {
+func getOrgId() (int, error) {
 	id, err := apiRequest("/agent", "GET", []byte{})
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return 0, err
 	}
 
 	var response map[string]interface{}
 	err = json.Unmarshal(id, &response)
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return 0, err
 	}
 
-	return int(response["organization_id"].(float64))
+	orgID, ok := response["organization_id"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("unable to find the organization id in %s", id)
+	}
+	return int(orgID), nil
 }
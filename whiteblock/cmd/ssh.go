@@ -1,59 +1,92 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/cmd/selector"
 	"github.com/whiteblock/cli/whiteblock/util"
-	"golang.org/x/sys/unix"
 	"os"
 	"strings"
 )
 
-type Node struct {
-	ID           string            `json:"id"`
-	TestNetID    string            `json:"testnetId"`
-	Server       int               `json:"server"`
-	LocalID      int               `json:"localId"`
-	AbsoluteNum  int               `json:"absNum"`
-	IP           string            `json:"ip"`
-	Label        string            `json:"label"`
-	Image        string            `json:"image"`
-	Protocol     string            `json:"protocol"`
-	PortMappings map[string]string `json:"portMappings,omitonempty"`
+// Node is kept here for the many commands which use it
+type Node = build.Node
+
+// sshInteractive runs a command on the node at ip attached to this terminal, or a shell if
+// command is empty
+func sshInteractive(ip string, command string) error {
+	log.WithFields(log.Fields{"ip": ip, "command": command}).Trace("ssh")
+	client, err := util.NewSshClient(ip)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Interactive(command)
+}
+
+// sshRun runs a command on a node, giving its output
+func sshRun(ctx context.Context, node int, ip string, command string) (interface{}, error) {
+	res := execOnNode(ctx, node, ip, command)
+	if len(res.Error) > 0 {
+		return nil, errors.New(res.Error)
+	}
+	if stderr := strings.TrimSpace(res.Stderr); res.ExitCode != 0 && len(stderr) > 0 {
+		return nil, fmt.Errorf("exited %d: %s", res.ExitCode, stderr)
+	} else if res.ExitCode != 0 {
+		return nil, fmt.Errorf("exited %d", res.ExitCode)
+	}
+	return res.Stdout + res.Stderr, nil
 }
 
 var sshCmd = &cobra.Command{
-	Use:   "ssh <node>",
+	Use:   "ssh <nodes> [command]",
 	Short: "SSH into an existing container.",
 	Long: `
-SSH will allow the user to go into the container where the specified node exists.
-	`,
-
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, -1)
+SSH will allow the user to go into the container where the specified node exists. If a command is
+given, it is run on each of the nodes instead, in parallel. The exit status of a command run on a
+single node is given as the exit status of the cli.
 
-		nodes := GetNodes()
-		nodeNumber := util.CheckAndConvertInt(args[0], "node")
-		util.CheckIntegerBounds(cmd, "node number", nodeNumber, 0, len(nodes)-1)
+` + selector.Usage,
 
-		sshArgs := []string{"ssh", "-i", conf.SSHPrivateKey, "-o", "StrictHostKeyChecking no",
-			"-o", "UserKnownHostsFile=/dev/null", "-o", "PasswordAuthentication no", "-o", "ConnectTimeout=10"}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, util.NoMaxArgs)
+		if err != nil {
+			return err
+		}
+		nodes, err := selectNodes(args[0])
+		if err != nil {
+			return err
+		}
 		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			return err
+		}
 
-		if err == nil && verbose {
-			sshArgs = append(sshArgs, "-v")
-		} else {
-			sshArgs = append(sshArgs, "-y")
+		command := util.ShellJoin(args[1:])
+		if verbose {
+			for _, node := range nodes.Selected {
+				fmt.Fprintf(os.Stderr, "connecting to node %d at root@%s:22 with the key %s\n",
+					node, nodes.Nodes[node].IP, conf.SSHPrivateKey)
+			}
 		}
 
-		sshArgs = append(sshArgs, "root@"+nodes[nodeNumber].IP)
-		sshArgs = append(sshArgs, args[1:]...)
-		log.WithFields(log.Fields{"command": strings.Join(sshArgs, " ")}).Trace("ssh")
-		log.Fatal(unix.Exec(conf.SSHBinary, sshArgs, os.Environ()))
+		if len(nodes.Selected) > 1 {
+			if len(args) == 1 {
+				return util.NewValidationError("a command must be given to run on more than one node")
+			}
+			return nodes.run(func(ctx context.Context, node int) (interface{}, error) {
+				return sshRun(ctx, node, nodes.Nodes[node].IP, command)
+			})
+		}
+		return sshInteractive(nodes.Nodes[nodes.Selected[0]].IP, command)
 	},
 }
 
 func init() {
-	sshCmd.Flags().BoolP("verbose", "v", false, "run ssh in verbose mode")
+	sshCmd.Flags().BoolP("verbose", "v", false, "print the details of each connection")
 	RootCmd.AddCommand(sshCmd)
 }
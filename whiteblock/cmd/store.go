@@ -13,30 +13,38 @@ var storeCommand = &cobra.Command{
 	Use:    "store",
 	Short:  "store",
 	Long:   `store`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 1)
-		wg := sync.WaitGroup{}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
 		data, err := ioutil.ReadFile(args[0])
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 		var extras map[string]interface{}
 		err = json.Unmarshal(data, &extras)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
+		wg := sync.WaitGroup{}
+		errs := make(chan error, len(extras))
 		for key, val := range extras {
 			wg.Add(1)
 			go func(key string, val interface{}) {
 				defer wg.Done()
 				_, err := util.JsonRpcCall("set_extra", []interface{}{key, val})
-				if err != nil {
-					util.PrintErrorFatal(err)
-				}
+				errs <- err
 			}(key, val)
 		}
 		wg.Wait()
-		util.Print("done")
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+		return util.Print("done")
 	},
 }
 
@@ -1,29 +1,35 @@
 package cmd
 
 import (
+	"context"
 	"github.com/spf13/cobra"
 	"github.com/whiteblock/cli/whiteblock/util"
 )
 
 var syncCmd = &cobra.Command{
-	Aliases: []string{"pull"},
-	Use:     "sync",
-	Short:   "Sync up with your current state",
+	Use:   "sync",
+	Short: "Sync up with your current state",
 	Long: `
 	Sync up with your current state.
 `,
 
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 
-		res, err := util.JsonRpcCall("get_last_build", []interface{}{})
+		lastBuild, err := rpcClient().GetLastBuild(context.Background())
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
-		err = util.Set("previous_build_id", res.(map[string]interface{})["id"].(string))
+		err = util.Set("previous_build_id", lastBuild.ID)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
-		util.Print("synced up with the latest build")
+		if _, err := util.FindTestnet(lastBuild.ID); err != nil {
+			err = util.SaveTestnet(util.Testnet{ID: lastBuild.ID, Blockchain: lastBuild.Blockchain, Nodes: lastBuild.Nodes})
+			if err != nil {
+				return err
+			}
+		}
+		return util.Print("synced up with the latest build")
 	},
 }
 
@@ -1,52 +1,203 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/scenario"
+	"github.com/whiteblock/cli/whiteblock/rpc"
 	"github.com/whiteblock/cli/whiteblock/util"
-	"io/ioutil"
+	"strings"
+	"time"
 )
 
+// scenarioTestnet gets the testnet for a scenario to start on, which is the testnet given with
+// --testnet, the testnet named by the scenario or the current testnet, in that order
+func scenarioTestnet(sc *scenario.Scenario) (string, error) {
+	if len(util.SelectedTestnet()) == 0 && len(sc.Testnet) > 0 {
+		testnet, err := util.FindTestnet(sc.Testnet)
+		if err != nil {
+			return "", err
+		}
+		return testnet.ID, nil
+	}
+	testnetID, err := build.GetPreviousBuildID()
+	if err != nil && sc.Steps[0].Build == nil {
+		return "", err
+	}
+	return testnetID, nil
+}
+
+// parseReportFlags gets the report files to write by their format, from the format=path pairs
+// given with --report
+func parseReportFlags(reports []string) ([][2]string, error) {
+	out := [][2]string{}
+	for _, report := range reports {
+		kv := strings.SplitN(report, "=", 2)
+		if len(kv) != 2 || len(kv[1]) == 0 {
+			return nil, util.NewValidationError(`invalid report "%s", expected format=path`, report)
+		}
+		known := false
+		for _, format := range scenario.ReportFormats {
+			known = known || format == kv[0]
+		}
+		if !known {
+			return nil, util.NewValidationError(`unknown report format "%s", expected one of %s`,
+				kv[0], strings.Join(scenario.ReportFormats, ", "))
+		}
+		out = append(out, [2]string{kv[0], kv[1]})
+	}
+	return out, nil
+}
+
+func printStepResult(res scenario.Result) {
+	status := res.Status
+	switch res.Status {
+	case scenario.StatusPassed:
+		status = util.Colorize(status, "32")
+	case scenario.StatusFailed:
+		status = util.Colorize(status, "31")
+	}
+	line := fmt.Sprintf("      %s", status)
+	if res.Status != scenario.StatusSkipped {
+		line += fmt.Sprintf(" (%v)", time.Duration(res.Duration))
+	}
+	if len(res.Message) > 0 {
+		line += ": " + res.Message
+	}
+	fmt.Println(line)
+	if len(res.Error) > 0 {
+		fmt.Println("      " + res.Error)
+	}
+}
+
 var testCmd = &cobra.Command{
-	// Hidden: true,
-	Use:   "test <file>",
-	Short: "Run test cases.",
+	Use:     "test <scenario file>",
+	Aliases: []string{"scenario"},
+	Short:   "Run a test scenario",
 	Long: `
+Test runs the steps of a scenario file in order, stopping at the first step which fails. The steps
+marked always are run regardless, such as to restore the network. The exit code is 0 if every step
+passed and 7 if any failed.
 
-This command will read from a file to run a test.
-	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 1, 1)
+Reports can be written for CI with --report format=path, where the format is junit, tap or json and
+a path of - writes to stdout. The reports include the duration and errors of each step, the testnet
+and its final build.
 
-		b, err := ioutil.ReadFile(args[0])
+The actions of the steps are build, waitForBlock, netconfig, partition, txStream, auto, sleep, signal,
+kill, restart and assert. Each step may have a name, a timeout, continueOnFailure and always.
+
+Example:
+	name: partition recovery
+	timeout: 1h
+	steps:
+	  - build: {spec: geth.yaml, name: partition-test}
+	  - waitForBlock: {height: 20, node: 0}
+	  - txStream: {tps: 50, value: 1}
+	  - netconfig: {delay: 100ms, loss: 0.5}
+	  - partition: {nodes: [0, 1]}
+	  - sleep: 2m
+	  - partition: {heal: true}
+	    always: true
+	  - assert: {stat: blockNumber, node: 0, gte: 40, eventually: true}
+	    timeout: 10m
+	  - assert: {stat: blockTime, blocks: 20, lt: 30}
+
+	whiteblock test partition.yaml --report junit=results/partition.xml --report json=summary.json
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
+		}
+		reportFlags, err := cmd.Flags().GetStringSlice("report")
+		if err != nil {
+			return err
+		}
+		reports, err := parseReportFlags(reportFlags)
+		if err != nil {
+			return err
+		}
+		sc, err := scenario.Load(args[0])
+		if err != nil {
+			return err
+		}
+		dryRun, err := util.GetBoolFlagValue(cmd, "dry-run")
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			out := []map[string]interface{}{}
+			for i, step := range sc.Steps {
+				out = append(out, map[string]interface{}{
+					"step":    i + 1,
+					"name":    step.Title(),
+					"action":  step.Action(),
+					"timeout": "",
+					"always":  step.Always,
+				})
+				if step.Timeout > 0 {
+					out[i]["timeout"] = time.Duration(step.Timeout).String()
+				} else if step.DefaultTimeout() > 0 {
+					out[i]["timeout"] = step.DefaultTimeout().String()
+				}
+			}
+			util.PrintTable(out, "step", "name", "action", "timeout", "always")
+			return nil
 		}
 
-		util.Print(util.Prettyp(string(b)))
-
-		var cont map[string]interface{}
-		err = json.Unmarshal(b, &cont)
+		testnetID, err := scenarioTestnet(sc)
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
+		}
+		runner := scenario.Runner{Client: rpcClient(), TestnetID: testnetID}
+		if util.OutputFormat() == util.OutputDefault {
+			runner.OnStep = func(index int, step scenario.Step) {
+				fmt.Printf("[%d/%d] %s\n", index, len(sc.Steps), step.Title())
+			}
+			runner.OnResult = func(res scenario.Result) {
+				if res.Status == scenario.StatusSkipped {
+					fmt.Printf("[%d/%d] %s\n", res.Index, len(sc.Steps), res.Name)
+				}
+				printStepResult(res)
+			}
 		}
 
-		util.Print(cont["build"])
-		util.Print(cont["netconfig"])
-		util.Print(cont["rpc"])
-		util.Print(cont["tests"])
+		ctx, cancel := rpc.InterruptContext(context.Background())
+		defer cancel()
+		report := runner.Run(ctx, sc)
+		if len(report.TestnetID) > 0 && report.TestnetID != testnetID {
+			err = util.Set("previous_build_id", report.TestnetID)
+			if err != nil {
+				return err
+			}
+		}
 
-		util.JsonRpcCallAndPrint("add_commands", cont["rpc"])
-		res, err := util.JsonRpcCall("build", cont["build"])
-		if err != nil {
-			util.PrintErrorFatal(err)
+		var reportErr error
+		for _, rf := range reports {
+			err = scenario.WriteReportFile(rf[1], rf[0], report)
+			if err != nil {
+				log.WithFields(log.Fields{"format": rf[0], "path": rf[1], "error": err}).Error("unable to write the report")
+				reportErr = err
+			}
+		}
+		if util.IsStructuredOutput() {
+			util.Print(report)
+		} else {
+			fmt.Printf("\n%d passed, %d failed, %d skipped in %v\n", report.Count(scenario.StatusPassed),
+				report.Count(scenario.StatusFailed), report.Count(scenario.StatusSkipped), time.Duration(report.Duration))
+		}
+		if err := report.Err(); err != nil {
+			return err
 		}
-		buildListener(res.(string))
-		util.JsonRpcCallAndPrint("netem", cont["netconfig"])
-		util.JsonRpcCallAndPrint("run_tests", cont["tests"])
+		return reportErr
 	},
 }
 
 func init() {
+	testCmd.Flags().StringSlice("report", nil, "write a report as format=path, the formats are junit, tap and json")
+	testCmd.Flags().Bool("dry-run", false, "check the scenario and list its steps, without running it")
 	RootCmd.AddCommand(testCmd)
 }
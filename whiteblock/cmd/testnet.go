@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"sort"
+	"strings"
+	"time"
+)
+
+var testnetCmd = &cobra.Command{
+	Use:     "testnet <command>",
+	Aliases: []string{"testnets", "tn"},
+	Short:   "Manage the testnets built from this machine",
+	Long: `
+Testnet keeps track of the testnets which have been built, so that several can be run side by side.
+The commands run against the testnet chosen with "testnet use", which is the last one built unless
+another is chosen. The testnet for a single command can be given with --testnet, by name or id.
+`,
+	Run: util.PartialCommand,
+}
+
+func formatLabels(labels map[string]string) string {
+	out := []string{}
+	for key, value := range labels {
+		out = append(out, key+"="+value)
+	}
+	sort.Strings(out)
+	return strings.Join(out, ",")
+}
+
+var testnetListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the testnets",
+	Long:    "\nList the testnets in the registry, the current testnet is marked with a *.\n",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 0)
+		if err != nil {
+			return err
+		}
+		testnets, err := util.ListTestnets()
+		if err != nil {
+			return err
+		}
+		var previous string
+		util.GetP("previous_build_id", &previous)
+
+		out := []map[string]interface{}{}
+		for _, testnet := range testnets {
+			current := ""
+			if testnet.ID == previous {
+				current = "*"
+			}
+			out = append(out, map[string]interface{}{
+				"current":    current,
+				"name":       testnet.Name,
+				"id":         testnet.ID,
+				"blockchain": testnet.Blockchain,
+				"nodes":      testnet.Nodes,
+				"labels":     formatLabels(testnet.Labels),
+				"created":    testnet.Created.Format(time.RFC3339),
+			})
+		}
+		util.PrintTable(out, "current", "name", "id", "blockchain", "nodes", "labels", "created")
+		return nil
+	},
+}
+
+var testnetUseCmd = &cobra.Command{
+	Use:     "use <testnet>",
+	Aliases: []string{"switch", "select"},
+	Short:   "Switch to a testnet",
+	Long:    "\nSwitch to a testnet, by name or id. The commands run afterwards will use it in place of the last build.\n",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		testnet, err := util.FindTestnet(args[0])
+		if err != nil {
+			return err
+		}
+		err = util.Set("previous_build_id", testnet.ID)
+		if err != nil {
+			return err
+		}
+		util.Printf("Switched to testnet %s", testnet.ID)
+		return nil
+	},
+}
+
+var testnetLabelCmd = &cobra.Command{
+	Use:   "label <testnet> [key=value...]",
+	Short: "Name or label a testnet",
+	Long: `
+Set the labels of a testnet, a label given without a value is removed.
+
+Example:
+	whiteblock testnet label 4f2c --name geth-small team=infra
+	whiteblock testnet label geth-small team=
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, util.NoMaxArgs)
+		if err != nil {
+			return err
+		}
+		testnet, err := util.FindTestnet(args[0])
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("name") {
+			testnet.Name, err = util.GetStringFlagValue(cmd, "name")
+			if err != nil {
+				return err
+			}
+		}
+		if testnet.Labels == nil {
+			testnet.Labels = map[string]string{}
+		}
+		for _, label := range args[1:] {
+			kv := strings.SplitN(label, "=", 2)
+			if len(kv) != 2 || len(kv[0]) == 0 {
+				return util.NewValidationError(`invalid label "%s", expected key=value`, label)
+			}
+			if len(kv[1]) == 0 {
+				delete(testnet.Labels, kv[0])
+				continue
+			}
+			testnet.Labels[kv[0]] = kv[1]
+		}
+		err = util.SaveTestnet(testnet)
+		if err != nil {
+			return err
+		}
+		util.Printf("Updated testnet %s", testnet.ID)
+		return nil
+	},
+}
+
+var testnetRemoveCmd = &cobra.Command{
+	Use:     "rm <testnet>",
+	Aliases: []string{"remove", "delete"},
+	Short:   "Remove a testnet from the registry",
+	Long:    "\nRemove a testnet from the registry. The testnet itself is left running unless --teardown is given.\n",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		testnet, err := util.FindTestnet(args[0])
+		if err != nil {
+			return err
+		}
+		teardown, err := util.GetBoolFlagValue(cmd, "teardown")
+		if err != nil {
+			return err
+		}
+		if teardown {
+			err = rpcClient().DeleteTestnet(context.Background(), testnet.ID)
+			if err != nil {
+				return err
+			}
+		}
+		err = util.RemoveTestnet(testnet.ID)
+		if err != nil {
+			return err
+		}
+		var previous string
+		if util.GetP("previous_build_id", &previous) == nil && previous == testnet.ID {
+			util.Delete("previous_build_id")
+		}
+		util.Printf("Removed testnet %s", testnet.ID)
+		return nil
+	},
+}
+
+func init() {
+	testnetLabelCmd.Flags().String("name", "", "the name to refer to the testnet by")
+	testnetRemoveCmd.Flags().Bool("teardown", false, "tear down the testnet as well")
+
+	testnetCmd.AddCommand(testnetListCmd, testnetUseCmd, testnetLabelCmd, testnetRemoveCmd)
+	RootCmd.AddCommand(testnetCmd)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/whiteblock/cli/whiteblock/cmd/build"
@@ -52,15 +53,18 @@ Required Parameters:
 Optional Parameters:
 	eos:  --symbol [symbol=SYS] --code [code=eosio.token] --memo [memo=]
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.RequireFlags(cmd, "from", "destination", "gas", "gasprice", "value")
-
-		util.JsonRpcCallAndPrint("send_transaction", []interface{}{
-			util.GetStringFlagValue(cmd, "from"),
-			util.GetStringFlagValue(cmd, "destination"),
-			util.GetStringFlagValue(cmd, "gas"),
-			util.GetStringFlagValue(cmd, "gasprice"),
-			strconv.Itoa(util.GetIntFlagValue(cmd, "value")),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.RequireFlags(cmd, "from", "destination", "gas", "gasprice", "value")
+		if err != nil {
+			return err
+		}
+
+		return util.JsonRpcCallAndPrint("send_transaction", []interface{}{
+			fromFlag,
+			toFlag,
+			gasFlag,
+			gasPriceFlag,
+			strconv.Itoa(valueFlag),
 		})
 	},
 }
@@ -75,14 +79,21 @@ Send a transaction data to an account.
 Required Parameters: 
 	--destination <address> --value <amount> --data <transaction data>
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		util.RequireFlags(cmd, "destination", "value")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.RequireFlags(cmd, "destination", "value")
+		if err != nil {
+			return err
+		}
+		params := []interface{}{}
+		for _, flag := range []string{"destination", "value", "data"} {
+			val, err := util.GetStringFlagValue(cmd, flag)
+			if err != nil {
+				return err
+			}
+			params = append(params, val)
+		}
 
-		util.JsonRpcCallAndPrint("send_to", []interface{}{
-			util.GetStringFlagValue(cmd, "destination"),
-			util.GetStringFlagValue(cmd, "value"),
-			util.GetStringFlagValue(cmd, "data"),
-		})
+		return util.JsonRpcCallAndPrint("send_to", params)
 	},
 }
 
@@ -107,36 +118,54 @@ Stream will send transactions as a continuous flow of tps.
 The user will need to run the command tx stop to stop running transactions.
 `,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.RequireFlags(cmd, "tps")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.RequireFlags(cmd, "tps")
+		if err != nil {
+			return err
+		}
 
-		tps := util.GetIntFlagValue(cmd, "tps")
+		tps, err := util.GetIntFlagValue(cmd, "tps")
+		if err != nil {
+			return err
+		}
 		newForm, err := cmd.Flags().GetBool("new")
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
+		}
+		value, err := util.GetIntFlagValue(cmd, "value")
+		if err != nil {
+			return err
+		}
+		valueInEth := strconv.Itoa(value) + "000000000000000000"
+		size, err := util.GetIntFlagValue(cmd, "size")
+		if err != nil {
+			return err
+		}
+		mode, err := util.GetStringFlagValue(cmd, "mode")
+		if err != nil {
+			return err
+		}
+		dest, err := util.GetStringFlagValue(cmd, "destination")
+		if err != nil {
+			return err
 		}
-
-		valueInEth := strconv.Itoa(util.GetIntFlagValue(cmd, "value")) + "000000000000000000"
-		size := util.GetIntFlagValue(cmd, "size")
 
 		if !newForm {
-			util.JsonRpcCallAndPrint("run_constant_tps", []interface{}{tps, valueInEth, size})
-			return
+			return util.JsonRpcCallAndPrint("run_constant_tps", []interface{}{tps, valueInEth, size})
 		}
 
 		params := map[string]interface{}{
 			"tps":    tps,
 			"value":  valueInEth,
 			"txSize": size,
-			"mode":   util.GetStringFlagValue(cmd, "mode"),
+			"mode":   mode,
 		}
 
-		dest := util.GetStringFlagValue(cmd, "destination")
 		if len(dest) > 0 {
 			params["destination"] = dest
 		}
 		log.WithFields(log.Fields{"params": params}).Debug("Sending the request to start sending tx")
-		util.JsonRpcCallAndPrint("run_constant_tps", []interface{}{params})
+		return util.JsonRpcCallAndPrint("run_constant_tps", []interface{}{params})
 	},
 }
 
@@ -154,11 +183,11 @@ Required Parameters:
 Optional Parameters:
 	--size [tx size]
 `,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		params := []string{strconv.Itoa(txsFlag)}
 		previousBuild, err := build.GetPreviousBuild()
 		if err != nil {
-			util.PrintErrorFatal(err)
+			return err
 		}
 
 		switch previousBuild.Blockchain {
@@ -167,23 +196,22 @@ Optional Parameters:
 			if valueFlag != 0 {
 				util.Print("Invalid \"valueFlag\" flag has been provided.")
 				cmd.Help()
-				return
+				return nil
 			}
 			if tpsFlag == 0 {
 				util.Print("No \"txsFlag\" flag has been provided. Please input the tps flag with a value.")
 				cmd.Help()
-				return
+				return nil
 			}
 			if txSizeFlag >= 174 {
 				params = append(params, strconv.Itoa(txSizeFlag))
 			} else if txSizeFlag > 0 && txSizeFlag < 174 {
-				util.Print("Transaction size value is too small. The minimum size of a transaction is 174 bytes.")
-				return
+				return util.Print("Transaction size value is too small. The minimum size of a transaction is 174 bytes.")
 			}
 		default:
-			util.ClientNotSupported(previousBuild.Blockchain)
+			return util.ClientNotSupported(previousBuild.Blockchain)
 		}
-		util.JsonRpcCallAndPrint("run_burst_tx", params)
+		return util.JsonRpcCallAndPrint("run_burst_tx", params)
 	},
 }
 
@@ -194,13 +222,15 @@ var stopTxCmd = &cobra.Command{
 The user must specify the blockchain flag as well as any other flags that will be used for sending transactions.
 Stops the sending of transactions if transactions are currently being sent
 `,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		res, err := util.JsonRpcCall("state::kill", []string{})
-		if res != nil && res.(float64) == 0 && err == nil {
-			util.Print("Transactions stopped successfully")
-		} else {
-			util.PrintErrorFatal("There was an error stopping transactions")
+		if err != nil {
+			return err
+		}
+		if res == nil || res.(float64) != 0 {
+			return fmt.Errorf("There was an error stopping transactions")
 		}
+		return util.Print("Transactions stopped successfully")
 	},
 }
 
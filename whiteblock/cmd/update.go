@@ -32,13 +32,13 @@ func bashExec(_cmd string) (string, error) {
 	return resultsRaw.String(), nil
 }
 
-func handleUpdate(branch string) {
+func handleUpdate(branch string) error {
 	endpoint := fmt.Sprintf("https://storage.googleapis.com/genesis-public/cli/%s/bin/%s/%s/whiteblock",
 		branch, runtime.GOOS, runtime.GOARCH)
 	log.WithFields(log.Fields{"ep": endpoint}).Trace("fetching the binary data")
 	binary, err := util.HttpRequest("GET", endpoint, "")
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 	log.WithFields(log.Fields{"size": len(binary)}).Trace("fetched the binary data")
 	//let's find the where this binary is located.
@@ -51,56 +51,58 @@ func handleUpdate(branch string) {
 		binaryLocation, err = filepath.Abs(binaryLocation)
 	}
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 
 	binaryLocation, err = filepath.EvalSymlinks(binaryLocation)
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 
 	fi, err := os.Lstat(binaryLocation)
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 
 	err = ioutil.WriteFile(binaryLocation+".tmp", binary, fi.Mode())
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
 
 	//swap,will be atomic on sane systems
 	err = os.Rename(binaryLocation+".tmp", binaryLocation)
 	if err != nil {
-		util.PrintErrorFatal(err)
+		return err
 	}
-	util.Print("whiteblock cli has been updated.")
+	return util.Print("whiteblock cli has been updated.")
 }
 
-func getUpdateBranch(args []string) string {
+func getUpdateBranch(args []string) (string, error) {
 	if len(args) == 0 {
 		options := []string{
 			"master",
 			"dev",
 		}
-		return options[util.OptionListPrompt("Which version of the cli would you like to use?", []string{
+		index, err := util.OptionListPrompt("Which version of the cli would you like to use?", []string{
 			"stable",
 			"beta",
-		})]
+		})
+		if err != nil {
+			return "", err
+		}
+		return options[index], nil
 	}
 	switch args[0] {
 	case "beta":
 		fallthrough
 	case "dev":
-		return "dev"
+		return "dev", nil
 	case "master":
 		fallthrough
 	case "stable":
-		return "master"
-	default:
-		util.PrintErrorFatal("Invalid argument, specify either beta or stable")
+		return "master", nil
 	}
-	return ""
+	return "", util.NewValidationError("Invalid argument, specify either beta or stable")
 }
 
 var updateCmd = &cobra.Command{
@@ -108,15 +110,21 @@ var updateCmd = &cobra.Command{
 	Short: "Update the CLI",
 	Long:  `Updates the cli binary to either the stable or beta release`,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		util.CheckArguments(cmd, args, 0, 1)
-		branch := getUpdateBranch(args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := util.CheckArguments(args, 0, 1)
+		if err != nil {
+			return err
+		}
+		branch, err := getUpdateBranch(args)
+		if err != nil {
+			return err
+		}
 
 		switch runtime.GOOS {
 		case "linux":
-			handleUpdate(branch)
+			return handleUpdate(branch)
 		default:
-			util.Print("sorry, your OS does not support easy updates")
+			return util.Print("sorry, your OS does not support easy updates")
 		}
 	},
 }
@@ -13,8 +13,8 @@ const (
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Get whiteblock CLI client version",
-	Run: func(cmd *cobra.Command, args []string) {
-		util.Print(RootCmd.Use + " " + VERSION)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return util.Print(RootCmd.Use + " " + VERSION)
 	},
 }
 
@@ -1,15 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/graarh/golang-socketio"
+	log "github.com/sirupsen/logrus"
 	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/rpc"
 	"github.com/whiteblock/cli/whiteblock/util"
 	"os"
 	"os/signal"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 )
 
 type BuildStatus struct {
@@ -19,90 +25,241 @@ type BuildStatus struct {
 	Frozen   bool              `json:"frozen"`
 }
 
-func buildListener(testnetId string) {
-	sigChan := make(chan os.Signal, 1)
-	pauseChan := make(chan os.Signal, 1)
-	quitChan := make(chan os.Signal, 1)
+// socketAttempts is the number of times in a row the socket may fail to connect before the
+// status of the build is polled instead
+const socketAttempts = 3
 
-	signal.Notify(sigChan, syscall.SIGINT) //Stop the build on SIGINT
-	go func() {
-		<-sigChan
-		defer util.Delete("in_progress_build_id")
-		res, err := util.JsonRpcCall("stop_build", []string{testnetId})
-		if err != nil {
-			util.PrintErrorFatal(err)
-		}
-		util.Printf("\r\n%v\r\n", res)
-		os.Exit(0)
-	}()
+const statusPollInterval = 2 * time.Second
 
+// buildListener prints the progress of a build, running the hooks on its events, until it
+// finishes, fails or the timeout, if any, is reached. Ctrl-C stops the build, giving an
+// InterruptError.
+func buildListener(testnetId string, timeout time.Duration, hooks *build.Hooks) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+	defer cancel()
+	ctx, cancelInterrupt := rpc.InterruptContext(ctx)
+	defer cancelInterrupt()
+
+	errs := make(chan error, 1)
+	quitChan := make(chan os.Signal, 1)
 	signal.Notify(quitChan, syscall.SIGQUIT) //^\ means exit without side effects
+	defer signal.Stop(quitChan)
 	go func() {
-		<-quitChan
-		os.Exit(0)
+		select {
+		case <-quitChan:
+			os.Exit(0)
+		case <-ctx.Done():
+		}
 	}()
 
+	pauseChan := make(chan os.Signal, 1)
 	signal.Notify(pauseChan, syscall.SIGTSTP, syscall.SIGCONT)
-	paused := false
+	defer signal.Stop(pauseChan)
 	go func() {
+		paused := false
 		for {
-			sigId := <-pauseChan
+			var sigId os.Signal
+			select {
+			case sigId = <-pauseChan:
+			case <-ctx.Done():
+				return
+			}
 			if sigId == syscall.SIGTSTP && !paused {
-				paused = true
 				res, err := util.JsonRpcCall("freeze_build", []string{testnetId})
 				if err != nil {
-					util.PrintErrorFatal(err)
+					sendErr(errs, err)
+					continue
 				}
+				paused = true
 				util.Printf("\r\n%v\r\n", res)
 				signal.Reset(syscall.SIGTSTP)
 				syscall.Kill(syscall.Getpid(), syscall.SIGSTOP)
 				signal.Notify(pauseChan, syscall.SIGTSTP)
 			} else if sigId == syscall.SIGCONT && paused {
-				paused = false
 				res, err := util.JsonRpcCall("unfreeze_build", []string{testnetId})
 				if err != nil {
-					util.PrintErrorFatal(err)
+					sendErr(errs, err)
+					continue
 				}
+				paused = false
 				util.Printf("\r\n%v\r\n", res)
 			}
 		}
 	}()
 
-	mutex := &sync.Mutex{}
-	mutex.Lock()
-	c, err := func() (*gosocketio.Client, error) {
-		if strings.HasSuffix(conf.ServerAddr, "5000") { //5000 is http
-			return gosocketio.Dial(
-				"ws://"+conf.ServerAddr+"/socket.io/?EIO=3&transport=websocket",
-				GetDefaultWebsocketTransport())
-		} else { //5001 is https
-			return gosocketio.Dial(
-				"wss://"+conf.ServerAddr+"/socket.io/?EIO=3&transport=websocket",
-				GetDefaultWebsocketTransport())
+	statuses := make(chan build.Status)
+	go newBuildFollower(testnetId).follow(ctx, statuses, errs)
+
+	printer := build.NewPrinter()
+	for {
+		select {
+		case status := <-statuses:
+			done, err := printer.Update(status)
+			hooks.Update(status)
+			if err != nil {
+				return err
+			}
+			if done {
+				printer.PrintSummary()
+				return nil
+			}
+		case err := <-errs:
+			hooks.Fail(err)
+			return err
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return util.TimeoutError{What: "the build", After: timeout,
+					Hint: "it is still running, use `whiteblock build attach` to follow it or `whiteblock build stop` to stop it"}
+			}
+			return stopBuild(testnetId, hooks)
 		}
-	}()
+	}
+}
+
+// stopBuild stops the build after it was interrupted, recording it as stopped and running
+// the fail hooks, and gives an InterruptError
+func stopBuild(testnetId string, hooks *build.Hooks) error {
+	defer util.Delete("in_progress_build_id")
+	res, err := util.JsonRpcCall("stop_build", []string{testnetId})
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Warn("unable to stop the build")
+	} else {
+		util.Printf("\r\n%v\r\n", res)
+	}
+	err = build.SetBuildOutcome(testnetId, build.OutcomeStopped, nil)
 	if err != nil {
-		util.PrintErrorFatal(err)
+		log.WithFields(log.Fields{"error": err}).Warn("unable to record the outcome of the build")
 	}
-	defer c.Close()
+	hooks.Fail(fmt.Errorf("the build was stopped"))
+	return rpc.InterruptError{}
+}
 
-	c.On("error", func(h *gosocketio.Channel, args string) {
-		util.PrintErrorFatal(args)
-	})
+// buildFollower follows the status of a build over socket.io, or by polling build_status
+type buildFollower struct {
+	testnetID string
+	// dial connects to the socket of the server
+	dial func() (*gosocketio.Client, error)
+	// status gets the status of the build from the build_status rpc
+	status func(ctx context.Context) (build.Status, error)
+	// backoff gets the time to wait before the given reconnection attempt
+	backoff      func(attempt int) time.Duration
+	pollInterval time.Duration
+}
 
-	err = c.On("build_status", func(h *gosocketio.Channel, args string) {
-		var status build.Status
-		err := json.Unmarshal([]byte(args), &status)
+// newBuildFollower creates a buildFollower for the build of the given testnet on the configured server
+func newBuildFollower(testnetId string) buildFollower {
+	c := rpcClient()
+	return buildFollower{
+		testnetID: testnetId,
+		dial: func() (*gosocketio.Client, error) {
+			return gosocketio.Dial(util.SocketURL(), GetDefaultWebsocketTransport())
+		},
+		status: func(ctx context.Context) (build.Status, error) {
+			return c.BuildStatus(ctx, testnetId)
+		},
+		backoff:      rpc.Backoff,
+		pollInterval: statusPollInterval,
+	}
+}
+
+// follow sends each status of the build given over socket.io to statuses, until ctx is
+// done. When the connection drops, it reconnects with backoff and asks for the status again. If
+// the socket cannot be reached socketAttempts times in a row, such as when websockets are
+// blocked, the build_status rpc is polled instead.
+func (f buildFollower) follow(ctx context.Context, statuses chan<- build.Status, errs chan<- error) {
+	failures := 0
+	for attempt := 0; ctx.Err() == nil; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(f.backoff(attempt - 1)):
+			}
+		}
+		c, err := f.dial()
 		if err != nil {
-			util.PrintErrorFatal(args)
+			failures++
+			log.WithFields(log.Fields{"error": err, "attempt": failures}).Debug("unable to connect to the socket")
+			if failures >= socketAttempts {
+				log.WithFields(log.Fields{"error": err}).Warn("unable to follow the build over websockets, polling its status instead")
+				f.poll(ctx, statuses, errs)
+				return
+			}
+			continue
 		}
-		if status.Print() {
-			mutex.Unlock()
+		failures = 0
+
+		disconnected := make(chan struct{})
+		var once sync.Once
+		var received int32
+		c.On(gosocketio.OnDisconnection, func(h *gosocketio.Channel) {
+			once.Do(func() { close(disconnected) })
+		})
+		c.On("error", func(h *gosocketio.Channel, args string) {
+			sendErr(errs, fmt.Errorf("%s", args))
+		})
+		c.On("build_status", func(h *gosocketio.Channel, args string) {
+			var status build.Status
+			err := json.Unmarshal([]byte(args), &status)
+			if err != nil {
+				sendErr(errs, fmt.Errorf("unexpected build status %s: %v", args, err))
+				return
+			}
+			atomic.StoreInt32(&received, 1)
+			select {
+			case statuses <- status:
+			case <-ctx.Done():
+			}
+		})
+		if !c.IsAlive() {
+			once.Do(func() { close(disconnected) })
 		}
-	})
-	if err != nil {
-		util.PrintErrorFatal(err)
+		c.Emit("build_status", f.testnetID)
+
+		select {
+		case <-disconnected:
+			log.Warn("lost the connection to the build, reconnecting")
+		case <-ctx.Done():
+		}
+		c.Close()
+		if atomic.LoadInt32(&received) == 1 {
+			attempt = 0 //the connection was good, so reconnect without waiting long
+		}
+	}
+}
+
+// poll sends the status of the build from the build_status rpc to statuses every
+// pollInterval, until ctx is done
+func (f buildFollower) poll(ctx context.Context, statuses chan<- build.Status, errs chan<- error) {
+	for {
+		status, err := f.status(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			sendErr(errs, err)
+			return
+		}
+		select {
+		case statuses <- status:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case <-time.After(f.pollInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sendErr gives err to the listener, unless it already has an error to report
+func sendErr(errs chan<- error, err error) {
+	select {
+	case errs <- err:
+	default:
 	}
-	c.Emit("build_status", testnetId)
-	mutex.Lock()
 }
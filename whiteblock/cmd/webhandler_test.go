@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/graarh/golang-socketio"
+	"github.com/graarh/golang-socketio/transport"
+	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFollower is a buildFollower whose dials and polls are recorded, with the dials after
+// the first `connects` failing
+type fakeFollower struct {
+	mux      sync.Mutex
+	url      string
+	connects int
+	dials    int
+	backoffs []int
+	polled   []build.Status
+}
+
+func (ff *fakeFollower) follower() buildFollower {
+	return buildFollower{
+		testnetID: "testnet1",
+		dial: func() (*gosocketio.Client, error) {
+			ff.mux.Lock()
+			defer ff.mux.Unlock()
+			ff.dials++
+			if ff.dials > ff.connects {
+				return nil, fmt.Errorf("websockets are blocked")
+			}
+			return gosocketio.Dial(ff.url, transport.GetDefaultWebsocketTransport())
+		},
+		status: func(ctx context.Context) (build.Status, error) {
+			ff.mux.Lock()
+			defer ff.mux.Unlock()
+			if len(ff.polled) == 0 {
+				return build.Status{}, fmt.Errorf("no more statuses")
+			}
+			status := ff.polled[0]
+			ff.polled = ff.polled[1:]
+			return status, nil
+		},
+		backoff: func(attempt int) time.Duration {
+			ff.mux.Lock()
+			defer ff.mux.Unlock()
+			ff.backoffs = append(ff.backoffs, attempt)
+			return time.Millisecond
+		},
+		pollInterval: time.Millisecond,
+	}
+}
+
+// followAll follows the build until it gives an error, giving the statuses sent before it
+func followAll(t *testing.T, f buildFollower) ([]build.Status, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	statuses := make(chan build.Status)
+	errs := make(chan error, 1)
+	go f.follow(ctx, statuses, errs)
+
+	out := []build.Status{}
+	for {
+		select {
+		case status := <-statuses:
+			out = append(out, status)
+		case err := <-errs:
+			return out, err
+		case <-ctx.Done():
+			t.Fatalf("the build was not followed through, got %v", out)
+		}
+	}
+}
+
+func TestFollowFallsBackToPolling(t *testing.T) {
+	ff := &fakeFollower{polled: []build.Status{{Progress: 10, Stage: "Provisioning"}, {Progress: 100}}}
+	statuses, err := followAll(t, ff.follower())
+	if err == nil || err.Error() != "no more statuses" {
+		t.Errorf("expected the error from polling, got %v", err)
+	}
+	if ff.dials != socketAttempts {
+		t.Errorf("expected %d attempts at the socket, got %d", socketAttempts, ff.dials)
+	}
+	if fmt.Sprint(ff.backoffs) != "[0 1]" {
+		t.Errorf("expected to back off before each reconnection, got %v", ff.backoffs)
+	}
+	if len(statuses) != 2 || !statuses[1].Done() {
+		t.Errorf("expected the polled statuses, got %v", statuses)
+	}
+}
+
+func TestFollowReconnects(t *testing.T) {
+	server := gosocketio.NewServer(transport.GetDefaultWebsocketTransport())
+	server.On("build_status", func(c *gosocketio.Channel, testnetID string) {
+		data, _ := json.Marshal(build.Status{Progress: 30, Stage: "Starting " + testnetID})
+		c.Emit("build_status", string(data))
+		time.Sleep(50 * time.Millisecond)
+		c.Close()
+	})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	ff := &fakeFollower{
+		url:      strings.Replace(ts.URL, "http://", "ws://", 1) + "/socket.io/?EIO=3&transport=websocket",
+		connects: 1,
+		polled:   []build.Status{{Progress: 100}},
+	}
+	statuses, err := followAll(t, ff.follower())
+	if err == nil || err.Error() != "no more statuses" {
+		t.Errorf("expected the error from polling, got %v", err)
+	}
+	if ff.dials != socketAttempts+1 {
+		t.Errorf("expected to reconnect %d times after the connection dropped, got %d dials",
+			socketAttempts, ff.dials)
+	}
+	if fmt.Sprint(ff.backoffs) != "[0 1 2]" {
+		t.Errorf("expected the backoff to start over after a good connection, got %v", ff.backoffs)
+	}
+	if len(statuses) != 2 || statuses[0].Stage != "Starting testnet1" || !statuses[1].Done() {
+		t.Errorf("expected the status from the socket and then from polling, got %v", statuses)
+	}
+}
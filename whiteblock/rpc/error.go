@@ -0,0 +1,93 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/rpc/v2/json2"
+)
+
+// The exit codes of the errors given by the calls, which the command line exits with
+const (
+	ExitTransport   = 3
+	ExitAuth        = 4
+	ExitRPC         = 5
+	ExitInterrupted = 130
+)
+
+// RPCError is an error returned by the server in response to a json rpc call
+type RPCError struct {
+	Method string
+	Err    *json2.Error
+}
+
+func (e RPCError) Error() string {
+	if e.Err.Data != nil {
+		res, err := json.Marshal(e.Err.Data)
+		if err == nil {
+			return string(res)
+		}
+	}
+	return e.Err.Message
+}
+
+func (e RPCError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode is ExitRPC
+func (e RPCError) ExitCode() int {
+	return ExitRPC
+}
+
+// TransportError is an error in reaching the server, including a server error status
+type TransportError struct {
+	URL        string
+	StatusCode int
+	Err        error
+}
+
+func (e TransportError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s returned %d: %v", e.URL, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("request to %s failed: %v", e.URL, e.Err)
+}
+
+func (e TransportError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode is ExitTransport
+func (e TransportError) ExitCode() int {
+	return ExitTransport
+}
+
+// AuthError is an error caused by missing or rejected credentials
+type AuthError struct {
+	Err error
+}
+
+func (e AuthError) Error() string {
+	return fmt.Sprintf("not authorized: %v, try running `whiteblock login`", e.Err)
+}
+
+func (e AuthError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode is ExitAuth
+func (e AuthError) ExitCode() int {
+	return ExitAuth
+}
+
+// InterruptError is given when a call is cancelled with Ctrl-C
+type InterruptError struct{}
+
+func (e InterruptError) Error() string {
+	return "interrupted"
+}
+
+// ExitCode is ExitInterrupted
+func (e InterruptError) ExitCode() int {
+	return ExitInterrupted
+}
@@ -0,0 +1,55 @@
+// Package rpc makes json rpc calls to the whiteblock genesis server and gives the typed errors
+// they fail with. It keeps no configuration of its own, so it may be used outside of the
+// command line.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/gorilla/rpc/v2/json2"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// Call makes a single json rpc call to url, decoding the result into out. The
+// errors returned are categorized as RPCError, TransportError or AuthError where possible.
+func Call(ctx context.Context, httpClient *http.Client, url string, header http.Header,
+	method string, params interface{}, out interface{}) error {
+
+	jrpc, err := json2.EncodeClientRequest(method, params)
+	if err != nil {
+		log.Warn(err)
+		return err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(jrpc))
+	if err != nil {
+		log.Warn(err)
+		return err
+	}
+	req = req.WithContext(ctx)
+	for key, vals := range header {
+		for _, val := range vals {
+			req.Header.Add(key, val)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	req.Close = true
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		log.Println(err)
+		return TransportError{URL: url, Err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return AuthError{Err: fmt.Errorf("the server responded with %s", resp.Status)}
+	}
+	err = json2.DecodeClientResponse(resp.Body, out)
+	if jsonError, ok := err.(*json2.Error); ok {
+		return RPCError{Method: method, Err: jsonError}
+	} else if err != nil {
+		return TransportError{URL: url, StatusCode: resp.StatusCode, Err: err}
+	}
+	return nil
+}
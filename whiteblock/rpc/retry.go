@@ -0,0 +1,127 @@
+package rpc
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	retryBaseDelay = 250 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+)
+
+var (
+	jitter    = rand.New(rand.NewSource(time.Now().UnixNano()))
+	jitterMux sync.Mutex
+)
+
+// nonRetryableMethods are the rpc calls which are not safe to repeat, since the first attempt
+// may have taken effect even though it failed, such as starting a second build.
+var nonRetryableMethods = map[string]bool{
+	"build":            true,
+	"add_nodes":        true,
+	"send_transaction": true,
+	"send_to":          true,
+	"run_constant_tps": true,
+	"run_burst_tx":     true,
+	"setup_load":       true,
+	"run_tests":        true,
+	"add_commands":     true,
+	"lock":             true,
+}
+
+// IsRetryableMethod checks if the given rpc call may be repeated after a failure
+func IsRetryableMethod(method string) bool {
+	return !nonRetryableMethods[method]
+}
+
+// IsRetryableError checks if a failed call is worth trying again. Only the errors
+// in reaching the server and server errors are, the server rejecting the call will
+// not change on a retry, and a successful response which could not be read has
+// already been acted on by the server.
+func IsRetryableError(err error) bool {
+	switch e := err.(type) {
+	case TransportError:
+		return e.StatusCode == 0 || e.StatusCode >= 500
+	}
+	return false
+}
+
+// Backoff gets the time to wait before the given retry, which doubles with every
+// attempt, with jitter so that many clients do not retry in step.
+func Backoff(attempt int) time.Duration {
+	delay := retryMaxDelay
+	if attempt < 16 {
+		delay = retryBaseDelay << uint(attempt)
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	jitterMux.Lock()
+	defer jitterMux.Unlock()
+	return delay/2 + time.Duration(jitter.Int63n(int64(delay/2)+1))
+}
+
+// InterruptContext creates a context which is cancelled on SIGINT or SIGTERM, until
+// the returned cancel function is called.
+func InterruptContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigChan)
+	}()
+	return ctx, cancel
+}
+
+// Retry calls fn until it succeeds, gives an error which is not retryable or the attempts run
+// out, waiting longer between each attempt. Every attempt is given its own deadline of timeout,
+// if timeout is greater than 0. Calls to the given method are only made once if they are not
+// safe to retry.
+func Retry(ctx context.Context, method string, attempts int, timeout time.Duration,
+	fn func(ctx context.Context) error) error {
+
+	if !IsRetryableMethod(method) || attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			delay := Backoff(i - 1)
+			log.WithFields(log.Fields{"method": method, "attempt": i + 1, "delay": delay,
+				"error": err}).Debug("retrying the call")
+			select {
+			case <-ctx.Done():
+				return InterruptError{}
+			case <-time.After(delay):
+			}
+		}
+		err = func() error {
+			callCtx := ctx
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				callCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			return fn(callCtx)
+		}()
+		if ctx.Err() == context.Canceled {
+			return InterruptError{}
+		}
+		if err == nil || !IsRetryableError(err) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	var tests = []struct {
+		method   string
+		err      error
+		attempts int
+		expected int
+	}{
+		{method: "nodes", err: nil, attempts: 3, expected: 1},
+		{method: "nodes", err: TransportError{URL: "x", Err: fmt.Errorf("refused")}, attempts: 2, expected: 2},
+		{method: "nodes", err: TransportError{URL: "x", StatusCode: 502, Err: fmt.Errorf("bad gateway")}, attempts: 2, expected: 2},
+		{method: "nodes", err: TransportError{URL: "x", StatusCode: 404, Err: fmt.Errorf("not found")}, attempts: 3, expected: 1},
+		{method: "nodes", err: TransportError{URL: "x", StatusCode: 200, Err: fmt.Errorf("bad json")}, attempts: 3, expected: 1},
+		{method: "nodes", err: AuthError{Err: fmt.Errorf("expired")}, attempts: 3, expected: 1},
+		{method: "build", err: TransportError{URL: "x", Err: fmt.Errorf("refused")}, attempts: 3, expected: 1},
+		{method: "add_nodes", err: TransportError{URL: "x", Err: fmt.Errorf("refused")}, attempts: 3, expected: 1},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			calls := 0
+			err := Retry(context.Background(), tt.method, tt.attempts, time.Second, func(ctx context.Context) error {
+				calls++
+				return tt.err
+			})
+			if err != tt.err {
+				t.Errorf("expected the error %v, got %v", tt.err, err)
+			}
+			if calls != tt.expected {
+				t.Errorf("expected %d calls, got %d", tt.expected, calls)
+			}
+		})
+	}
+}
+
+func TestRetryCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Retry(ctx, "nodes", 3, time.Second, func(ctx context.Context) error {
+		return TransportError{URL: "x", Err: ctx.Err()}
+	})
+	if _, ok := err.(InterruptError); !ok {
+		t.Errorf("expected an InterruptError, got %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for i := 0; i < 40; i++ {
+		delay := Backoff(i)
+		max := retryMaxDelay
+		if i < 6 {
+			max = retryBaseDelay << uint(i)
+		}
+		if delay < max/2 || delay > max {
+			t.Errorf("backoff %d of %v is outside of [%v, %v]", i, delay, max/2, max)
+		}
+	}
+}
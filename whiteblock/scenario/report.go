@@ -0,0 +1,246 @@
+package scenario
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The formats the reports can be written in
+const (
+	ReportJUnit = "junit"
+	ReportTAP   = "tap"
+	ReportJSON  = "json"
+)
+
+// ReportFormats are all of the formats the reports can be written in
+var ReportFormats = []string{ReportJUnit, ReportTAP, ReportJSON}
+
+// Summary is the report as written in the json format, with the counts of each outcome
+type Summary struct {
+	Report
+	Passed  bool `json:"passed"`
+	Total   int  `json:"total"`
+	Failed  int  `json:"failed"`
+	Skipped int  `json:"skipped"`
+}
+
+// NewSummary creates the summary of a report
+func NewSummary(report Report) Summary {
+	return Summary{
+		Report:  report,
+		Passed:  report.Passed(),
+		Total:   len(report.Steps),
+		Failed:  report.Count(StatusFailed),
+		Skipped: report.Count(StatusSkipped),
+	}
+}
+
+// WriteReport writes the report to w in the given format
+func WriteReport(w io.Writer, format string, report Report) error {
+	switch format {
+	case ReportJUnit:
+		return WriteJUnit(w, report)
+	case ReportTAP:
+		return WriteTAP(w, report)
+	case ReportJSON:
+		return WriteJSON(w, report)
+	}
+	return fmt.Errorf(`unknown report format "%s", expected one of %s`, format, strings.Join(ReportFormats, ", "))
+}
+
+// WriteReportFile writes the report to the file at path, or to stdout if path is "-"
+func WriteReportFile(path string, format string, report Report) error {
+	if path == "-" {
+		return WriteReport(os.Stdout, format, report)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = WriteReport(f, format, report)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteJSON writes the summary of the report as json
+func WriteJSON(w io.Writer, report Report) error {
+	data, err := json.MarshalIndent(NewSummary(report), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+func seconds(d Duration) string {
+	return fmt.Sprintf("%.3f", time.Duration(d).Seconds())
+}
+
+// buildProperties flattens the build of a report into junit properties
+func buildProperties(report Report) []junitProperty {
+	var out []junitProperty
+	if len(report.TestnetID) > 0 {
+		out = append(out, junitProperty{Name: "testnetId", Value: report.TestnetID})
+	}
+	if report.Build == nil {
+		return out
+	}
+	out = append(out,
+		junitProperty{Name: "blockchain", Value: report.Build.Blockchain},
+		junitProperty{Name: "nodes", Value: fmt.Sprint(report.Build.Nodes)})
+	images := map[string]bool{}
+	for _, image := range report.Build.Images {
+		images[image] = true
+	}
+	imageList := []string{}
+	for image := range images {
+		imageList = append(imageList, image)
+	}
+	sort.Strings(imageList)
+	if len(imageList) > 0 {
+		out = append(out, junitProperty{Name: "images", Value: strings.Join(imageList, ",")})
+	}
+	data, err := json.Marshal(report.Build)
+	if err == nil {
+		out = append(out, junitProperty{Name: "build", Value: string(data)})
+	}
+	return out
+}
+
+// WriteJUnit writes the report as junit xml, with a test case for each step
+func WriteJUnit(w io.Writer, report Report) error {
+	suite := junitTestSuite{
+		Name:       report.Name,
+		Tests:      len(report.Steps),
+		Failures:   report.Count(StatusFailed),
+		Skipped:    report.Count(StatusSkipped),
+		Time:       seconds(report.Duration),
+		Timestamp:  report.Started.UTC().Format("2006-01-02T15:04:05"),
+		Properties: buildProperties(report),
+	}
+	for _, res := range report.Steps {
+		tc := junitTestCase{
+			Name:      fmt.Sprintf("%d. %s", res.Index, res.Name),
+			ClassName: report.Name + "." + res.Action,
+			Time:      seconds(res.Duration),
+			SystemOut: res.Message,
+		}
+		switch res.Status {
+		case StatusFailed:
+			tc.Failure = &junitFailure{Message: res.Error, Type: res.ErrorKind, Text: res.Error}
+		case StatusSkipped:
+			tc.Skipped = &struct{}{}
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suites := junitTestSuites{
+		Name:     report.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, data)
+	return err
+}
+
+// tapEscape keeps a description on a single line, and from being read as a directive
+func tapEscape(s string) string {
+	s = strings.Replace(s, "\n", " ", -1)
+	return strings.Replace(s, "#", "\\#", -1)
+}
+
+// yamlString quotes a string for the yaml block of a tap test
+func yamlString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// WriteTAP writes the report as TAP version 13, with a test for each step
+func WriteTAP(w io.Writer, report Report) error {
+	out := []string{"TAP version 13", fmt.Sprintf("1..%d", len(report.Steps))}
+	out = append(out, "# "+tapEscape(report.Name))
+	if len(report.TestnetID) > 0 {
+		out = append(out, "# testnet "+report.TestnetID)
+	}
+	if report.Build != nil {
+		out = append(out, fmt.Sprintf("# build %s with %d nodes", report.Build.Blockchain, report.Build.Nodes))
+	}
+	for _, res := range report.Steps {
+		line := fmt.Sprintf("%d - %s", res.Index, tapEscape(res.Name))
+		switch res.Status {
+		case StatusPassed:
+			out = append(out, "ok "+line)
+		case StatusFailed:
+			out = append(out, "not ok "+line)
+		case StatusSkipped:
+			out = append(out, "ok "+line+" # SKIP an earlier step failed")
+			continue
+		}
+		out = append(out, "  ---", fmt.Sprintf("  duration_ms: %d", time.Duration(res.Duration)/time.Millisecond))
+		if len(res.Message) > 0 {
+			out = append(out, "  message: "+yamlString(res.Message))
+		}
+		if len(res.Error) > 0 {
+			out = append(out, "  error: "+yamlString(res.Error), "  errorKind: "+res.ErrorKind)
+		}
+		out = append(out, "  ...")
+	}
+	_, err := fmt.Fprintln(w, strings.Join(out, "\n"))
+	return err
+}
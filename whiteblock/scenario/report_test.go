@@ -0,0 +1,119 @@
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReport() Report {
+	return Report{
+		Name:      "partition",
+		TestnetID: "testnet1",
+		Started:   time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC),
+		Duration:  Duration(90 * time.Second),
+		Steps: []Result{
+			{Index: 1, Name: "wait for block 20", Action: "waitForBlock", Status: StatusPassed,
+				Duration: Duration(1500 * time.Millisecond), Message: "node 0 is at block 20"},
+			{Index: 2, Name: "assert # of blocks", Action: "assert", Status: StatusFailed,
+				Duration: Duration(time.Second), Error: "get_block_number failed", ErrorKind: "rpc"},
+			{Index: 3, Name: "kill node 1", Action: "kill", Status: StatusSkipped},
+		},
+		Build: &build.Config{Blockchain: "geth", Nodes: 2, Images: []string{"geth:stable", "geth:stable"}},
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := WriteJUnit(buf, testReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestSuites
+	err = xml.Unmarshal(buf.Bytes(), &suites)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suites.Tests != 3 || suites.Failures != 1 || suites.Skipped != 1 || len(suites.Suites) != 1 {
+		t.Fatalf("unexpected counts in %s", buf.String())
+	}
+	cases := suites.Suites[0].TestCases
+	if cases[0].Time != "1.500" || cases[0].Failure != nil || cases[0].SystemOut != "node 0 is at block 20" {
+		t.Errorf("unexpected passed test case: %+v", cases[0])
+	}
+	if cases[1].Failure == nil || cases[1].Failure.Type != "rpc" || cases[1].Failure.Message != "get_block_number failed" {
+		t.Errorf("unexpected failed test case: %+v", cases[1])
+	}
+	if cases[2].Skipped == nil {
+		t.Errorf("expected the last test case to be skipped: %+v", cases[2])
+	}
+	props := map[string]string{}
+	for _, prop := range suites.Suites[0].Properties {
+		props[prop.Name] = prop.Value
+	}
+	if props["testnetId"] != "testnet1" || props["blockchain"] != "geth" || props["images"] != "geth:stable" {
+		t.Errorf("unexpected properties: %v", props)
+	}
+}
+
+func TestWriteTAP(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := WriteTAP(buf, testReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"TAP version 13",
+		"1..3",
+		"# testnet testnet1",
+		"ok 1 - wait for block 20",
+		"  duration_ms: 1500",
+		"not ok 2 - assert \\# of blocks",
+		`  error: "get_block_number failed"`,
+		"ok 3 - kill node 1 # SKIP an earlier step failed",
+	}
+	for i, line := range expected {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if !strings.Contains(buf.String(), line+"\n") {
+				t.Errorf("expected the line %q in:\n%s", line, buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := WriteJSON(buf, testReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary["passed"] != false || summary["failed"] != 1.0 || summary["skipped"] != 1.0 ||
+		summary["testnetId"] != "testnet1" || summary["duration"] != "1m30s" || summary["build"] == nil {
+		t.Errorf("unexpected summary: %s", buf.String())
+	}
+}
+
+func TestRedactBuild(t *testing.T) {
+	bconf := build.Config{Extras: map[string]interface{}{"prebuild": map[string]interface{}{
+		"auth": map[string]string{"username": "user", "password": "secret"},
+	}}}
+	redacted := redactBuild(bconf)
+	data, _ := json.Marshal(redacted)
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("the password was not removed: %s", data)
+	}
+	data, _ = json.Marshal(bconf)
+	if !strings.Contains(string(data), "secret") {
+		t.Error("the original build should not be changed")
+	}
+}
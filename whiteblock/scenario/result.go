@@ -0,0 +1,128 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"time"
+)
+
+// The outcomes of a step
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Result is the outcome of a single step
+type Result struct {
+	Index    int       `json:"index"`
+	Name     string    `json:"name"`
+	Action   string    `json:"action"`
+	Status   string    `json:"status"`
+	Started  time.Time `json:"started"`
+	Duration Duration  `json:"duration"`
+	// Message describes what the step found, such as the value checked by an assertion
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// ErrorKind is the category of the error, such as rpc, transport or timeout
+	ErrorKind string `json:"errorKind,omitempty"`
+}
+
+// Report is the outcome of a whole scenario
+type Report struct {
+	Name      string    `json:"name"`
+	TestnetID string    `json:"testnetId,omitempty"`
+	Started   time.Time `json:"started"`
+	Duration  Duration  `json:"duration"`
+	Steps     []Result  `json:"steps"`
+	// Build is the build of the testnet once the scenario finished, with any credentials removed
+	Build *build.Config `json:"build,omitempty"`
+}
+
+// Count gets the number of steps with the given status
+func (report Report) Count(status string) int {
+	out := 0
+	for _, res := range report.Steps {
+		if res.Status == status {
+			out++
+		}
+	}
+	return out
+}
+
+// Passed checks that none of the steps failed
+func (report Report) Passed() bool {
+	return report.Count(StatusFailed) == 0
+}
+
+// Err gets the error to exit with for the report, nil if the scenario passed
+func (report Report) Err() error {
+	if report.Passed() {
+		return nil
+	}
+	return FailedError{Name: report.Name, Failed: report.Count(StatusFailed), Total: len(report.Steps)}
+}
+
+// errorKind gets the category of an error, for the reports
+func errorKind(err error) string {
+	var rpcErr util.RPCError
+	if errors.As(err, &rpcErr) {
+		return "rpc"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	switch util.ExitCode(err) {
+	case util.ExitTransport:
+		return "transport"
+	case util.ExitAuth:
+		return "auth"
+	case util.ExitValidation:
+		return "validation"
+	case util.ExitInterrupted:
+		return "interrupted"
+	}
+	return "failure"
+}
+
+// redactBuild removes the docker credentials from a build
+func redactBuild(bconf build.Config) build.Config {
+	prebuild, ok := bconf.Extras["prebuild"].(map[string]interface{})
+	if !ok {
+		return bconf
+	}
+	if _, ok := prebuild["auth"]; !ok {
+		return bconf
+	}
+	extras := map[string]interface{}{}
+	for k, v := range bconf.Extras {
+		extras[k] = v
+	}
+	redacted := map[string]interface{}{}
+	for k, v := range prebuild {
+		redacted[k] = v
+	}
+	redacted["auth"] = "redacted"
+	extras["prebuild"] = redacted
+	bconf.Extras = extras
+	return bconf
+}
+
+// FailedError is given when a step of a scenario fails
+type FailedError struct {
+	Name   string
+	Failed int
+	Total  int
+}
+
+func (e FailedError) Error() string {
+	return fmt.Sprintf("scenario %s failed, %d of %d steps failed", e.Name, e.Failed, e.Total)
+}
+
+// ExitCode is util.ExitTestFailure
+func (e FailedError) ExitCode() int {
+	return util.ExitTestFailure
+}
@@ -0,0 +1,407 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/whiteblock/cli/whiteblock/client"
+	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	buildPollInterval   = 2 * time.Second
+	defaultAutoInterval = 50 * time.Millisecond
+	defaultSampleSize   = 200
+)
+
+// Runner runs the steps of scenarios
+type Runner struct {
+	Client *client.Client
+	// TestnetID is the testnet the steps run against, it is replaced by the testnet built by
+	// a build step
+	TestnetID string
+	// OnStep is called before each step is run, if it is not nil
+	OnStep func(index int, step Step)
+	// OnResult is called with the result of each step, including the skipped steps, if it is not nil
+	OnResult func(res Result)
+}
+
+// Run runs each step of the scenario in order. Once a step fails, the rest of the steps are skipped,
+// other than those marked always.
+func (r *Runner) Run(ctx context.Context, scenario *Scenario) Report {
+	scenarioCtx := ctx
+	if scenario.Timeout > 0 {
+		var cancel context.CancelFunc
+		scenarioCtx, cancel = context.WithTimeout(ctx, time.Duration(scenario.Timeout))
+		defer cancel()
+	}
+	report := Report{Name: scenario.Name, Started: time.Now(), Steps: []Result{}}
+	failed := false
+	for i, step := range scenario.Steps {
+		res := Result{Index: i + 1, Name: step.Title(), Action: step.Action(), Status: StatusSkipped}
+		if !failed || step.Always {
+			if r.OnStep != nil {
+				r.OnStep(i+1, step)
+			}
+			stepCtx := scenarioCtx
+			if step.Always {
+				stepCtx = ctx //clean up steps still run once the scenario has timed out
+			}
+			res.Started = time.Now()
+			msg, err := r.runStep(stepCtx, scenario, step)
+			res.Duration = Duration(time.Since(res.Started).Round(time.Millisecond))
+			res.Message = msg
+			res.Status = StatusPassed
+			if err != nil {
+				res.Status = StatusFailed
+				res.Error = err.Error()
+				res.ErrorKind = errorKind(err)
+				failed = failed || !step.ContinueOnFailure
+			}
+		}
+		report.Steps = append(report.Steps, res)
+		if r.OnResult != nil {
+			r.OnResult(res)
+		}
+	}
+	report.TestnetID = r.TestnetID
+	if len(r.TestnetID) > 0 {
+		bconf, err := r.Client.GetBuild(context.Background(), r.TestnetID)
+		if err == nil {
+			bconf = redactBuild(bconf)
+			report.Build = &bconf
+		} else {
+			log.WithFields(log.Fields{"error": err}).Debug("unable to get the build of the testnet")
+		}
+	}
+	report.Duration = Duration(time.Since(report.Started).Round(time.Millisecond))
+	return report
+}
+
+func (r *Runner) runStep(ctx context.Context, scenario *Scenario, step Step) (string, error) {
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if timeout := step.timeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if step.Build != nil {
+		return r.build(ctx, scenario.dir, step.Build)
+	}
+	if step.Sleep != nil {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Duration(*step.Sleep)):
+		}
+		return "", nil
+	}
+	if len(r.TestnetID) == 0 {
+		return "", fmt.Errorf("there is no testnet to run against, add a build step or give a testnet")
+	}
+
+	switch {
+	case step.WaitForBlock != nil:
+		return r.waitForBlock(ctx, step.WaitForBlock)
+	case step.Netconfig != nil:
+		return "", r.netconfig(ctx, step.Netconfig)
+	case step.Partition != nil:
+		if step.Partition.Heal {
+			return "", r.Client.RemoveAllOutages(ctx, r.TestnetID)
+		}
+		return "", r.Client.PartitionOutage(ctx, r.TestnetID, step.Partition.Nodes)
+	case step.TxStream != nil:
+		return r.txStream(ctx, step.TxStream)
+	case step.Auto != nil:
+		return r.auto(ctx, step.Auto)
+	case step.Signal != nil:
+		return "", r.Client.SignalNode(ctx, r.TestnetID, strconv.Itoa(step.Signal.Node), step.Signal.Signal)
+	case step.Kill != nil:
+		return "", r.Client.KillNode(ctx, r.TestnetID, strconv.Itoa(step.Kill.Node))
+	case step.Restart != nil:
+		return "", r.Client.RestartNode(ctx, r.TestnetID, strconv.Itoa(step.Restart.Node))
+	case step.Assert != nil:
+		return r.assert(ctx, step.Assert)
+	}
+	return "", fmt.Errorf("unknown action")
+}
+
+func (r *Runner) build(ctx context.Context, dir string, step *BuildStep) (string, error) {
+	path := step.Spec
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	spec, err := build.LoadSpec(path)
+	if err != nil {
+		return "", err
+	}
+	bconf := spec.Config
+	err = spec.Apply(&bconf)
+	if err != nil {
+		return "", err
+	}
+	for i := range bconf.Images {
+		bconf.Images[i], err = build.ResolveImage(bconf.Blockchain, bconf.Images[i])
+		if err != nil {
+			return "", err
+		}
+	}
+	if len(bconf.Servers) == 0 {
+		res, err := r.Client.GetServers(ctx)
+		if err != nil {
+			return "", err
+		}
+		bconf.Servers, err = build.DefaultServers(res)
+		if err != nil {
+			return "", err
+		}
+	}
+	build.SanitizeBuild(&bconf)
+	if errs := build.Validate(bconf, nil); len(errs) > 0 {
+		return "", errs
+	}
+
+	testnetID, err := r.Client.Build(ctx, bconf)
+	if err != nil {
+		return "", err
+	}
+	r.TestnetID = testnetID
+	err = util.SaveTestnet(util.Testnet{ID: testnetID, Name: step.Name, Blockchain: bconf.Blockchain, Nodes: bconf.Nodes})
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Warn("unable to add the testnet to the registry")
+	}
+	msg := "built testnet " + testnetID
+	return msg, r.waitForBuild(ctx, testnetID)
+}
+
+func (r *Runner) waitForBuild(ctx context.Context, testnetID string) error {
+	for {
+		status, err := r.Client.BuildStatus(ctx, testnetID)
+		if err == nil && status.Error != nil {
+			return fmt.Errorf("the build failed: %v", status.Error["what"])
+		}
+		if err == nil && status.Progress == 100.0 {
+			return nil
+		}
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Debug("unable to get the build status")
+		}
+		select {
+		case <-ctx.Done():
+			//the build will not be waited on, so it should not be left running
+			stopErr := r.Client.StopBuild(context.Background(), testnetID)
+			if stopErr != nil {
+				log.WithFields(log.Fields{"error": stopErr}).Warn("unable to stop the build")
+			}
+			return fmt.Errorf("the build did not finish: %v", ctx.Err())
+		case <-time.After(buildPollInterval):
+		}
+	}
+}
+
+func (r *Runner) waitForBlock(ctx context.Context, step *WaitForBlockStep) (string, error) {
+	interval := time.Duration(step.Interval)
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	var height int64 = -1
+	var lastErr error
+	for {
+		current, err := r.Client.GetBlockNumber(ctx, step.Node)
+		if err == nil {
+			height = current
+			if height >= step.Height {
+				return fmt.Sprintf("node %d is at block %d", step.Node, height), nil
+			}
+		}
+		lastErr = err
+		select {
+		case <-ctx.Done():
+			if height < 0 && lastErr != nil {
+				return "", fmt.Errorf("unable to get the block height: %v", lastErr)
+			}
+			return fmt.Sprintf("node %d is at block %d", step.Node, height),
+				fmt.Errorf("node %d did not reach block %d: %v", step.Node, step.Height, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (r *Runner) netconfig(ctx context.Context, step *NetconfigStep) error {
+	if step.Clear {
+		return r.Client.NetemDelete(ctx, r.TestnetID)
+	}
+	conditions := client.Netem{
+		Limit: step.Limit,
+		Loss:  step.Loss,
+		Delay: int(time.Duration(step.Delay) / time.Microsecond),
+		Rate:  step.Rate,
+	}
+	if step.Node == nil {
+		conditions.Delay /= 2 //as with netconfig all, the delay is split between both ends
+		return r.Client.NetemAll(ctx, r.TestnetID, conditions)
+	}
+	conditions.Node = *step.Node
+	return r.Client.Netem(ctx, r.TestnetID, conditions)
+}
+
+func (r *Runner) txStream(ctx context.Context, step *TxStreamStep) (string, error) {
+	if step.Stop {
+		_, err := r.Client.Kill(ctx)
+		return "", err
+	}
+	res, err := r.Client.RunConstantTPS(ctx, client.TPS{
+		TPS:    step.TPS,
+		Value:  strconv.Itoa(step.Value) + "000000000000000000",
+		TxSize: step.Size,
+	})
+	return fmt.Sprint(res), err
+}
+
+func (r *Runner) auto(ctx context.Context, step *AutoStep) (string, error) {
+	if step.Stop {
+		_, err := r.Client.KillSubRoutines(ctx)
+		return "", err
+	}
+	interval := time.Duration(step.Interval)
+	if interval <= 0 {
+		interval = defaultAutoInterval
+	}
+	sampleSize := step.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = defaultSampleSize
+	}
+	args := step.Arguments
+	if args == nil {
+		args = []interface{}{}
+	}
+	name := fmt.Sprintf("node%d:%s", step.Node, step.Call)
+	_, err := r.Client.SetupLoad(ctx, client.Load{
+		Node: step.Node,
+		Name: name,
+		Settings: client.LoadSettings{
+			TargetDelay:   int(interval / time.Microsecond),
+			SampleSize:    sampleSize,
+			MaxNumErrMsgs: 5,
+			RecordErrMsgs: true,
+		},
+		Call:      step.Call,
+		Arguments: args,
+	})
+	return "started " + name, err
+}
+
+func (r *Runner) statValue(ctx context.Context, step *AssertStep) (float64, error) {
+	if step.Stat == "blockNumber" {
+		height, err := r.Client.GetBlockNumber(ctx, step.Node)
+		return float64(height), err
+	}
+	var stats interface{}
+	var err error
+	if step.Blocks > 0 {
+		stats, err = r.Client.Stats(ctx, client.StatsRequest{StartBlock: -step.Blocks})
+	} else {
+		stats, err = r.Client.AllStats(ctx)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return lookupStat(stats, step.Stat)
+}
+
+func (r *Runner) assert(ctx context.Context, step *AssertStep) (string, error) {
+	interval := time.Duration(step.Interval)
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	for {
+		msg := ""
+		value, err := r.statValue(ctx, step)
+		if err == nil {
+			msg = fmt.Sprintf("%s is %v", step.Stat, value)
+			err = step.check(value)
+			if err == nil {
+				return msg, nil
+			}
+		}
+		if !step.Eventually {
+			return msg, err
+		}
+		select {
+		case <-ctx.Done():
+			return msg, err
+		case <-time.After(interval):
+		}
+	}
+}
+
+// check checks the value against each of the bounds of the assertion
+func (assert AssertStep) check(value float64) error {
+	failed := []string{}
+	if assert.Lt != nil && !(value < *assert.Lt) {
+		failed = append(failed, fmt.Sprintf("less than %v", *assert.Lt))
+	}
+	if assert.Lte != nil && !(value <= *assert.Lte) {
+		failed = append(failed, fmt.Sprintf("at most %v", *assert.Lte))
+	}
+	if assert.Gt != nil && !(value > *assert.Gt) {
+		failed = append(failed, fmt.Sprintf("greater than %v", *assert.Gt))
+	}
+	if assert.Gte != nil && !(value >= *assert.Gte) {
+		failed = append(failed, fmt.Sprintf("at least %v", *assert.Gte))
+	}
+	if assert.Eq != nil && value != *assert.Eq {
+		failed = append(failed, fmt.Sprintf("equal to %v", *assert.Eq))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%s is %v, expected it to be %s", assert.Stat, value, strings.Join(failed, " and "))
+	}
+	return nil
+}
+
+// lookupStat follows the dot separated path through the stats, to a number
+func lookupStat(stats interface{}, path string) (float64, error) {
+	current := stats
+	for _, key := range strings.Split(path, ".") {
+		switch val := current.(type) {
+		case map[string]interface{}:
+			next, ok := val[key]
+			if !ok {
+				return 0, fmt.Errorf("the stats do not have %s", path)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(val) {
+				return 0, fmt.Errorf("the stats do not have %s", path)
+			}
+			current = val[index]
+		default:
+			return 0, fmt.Errorf("the stats do not have %s", path)
+		}
+	}
+	switch val := current.(type) {
+	case float64:
+		return val, nil
+	case bool:
+		if val {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		out, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s is not a number: %s", path, val)
+		}
+		return out, nil
+	}
+	return 0, fmt.Errorf("%s is not a number", path)
+}
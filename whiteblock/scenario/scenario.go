@@ -0,0 +1,305 @@
+// Package scenario runs test scenarios against a testnet. A scenario is an ordered list of
+// steps, such as building a testnet, changing the network conditions or asserting on the
+// stats of the blockchain, each of which passes or fails on its own.
+package scenario
+
+import (
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Duration is given in a scenario as either a string such as "1m30s", or as a number of seconds
+type Duration = util.Duration
+
+// Scenario is a test, as given in a scenario file
+type Scenario struct {
+	Name string `json:"name"`
+	// Testnet is the testnet to run against, by name or id. If it is empty and no step builds a
+	// testnet, the current testnet is used.
+	Testnet string `json:"testnet"`
+	// Timeout is the limit on the time taken by the whole scenario, there is no limit if it is 0
+	Timeout Duration `json:"timeout"`
+	Steps   []Step   `json:"steps"`
+
+	dir string
+}
+
+// Step is a single action of a scenario. Exactly one of the actions must be given.
+type Step struct {
+	// Name describes the step in the results, it defaults to the action
+	Name string `json:"name"`
+	// Timeout is the limit on the time taken by the step, see DefaultTimeout
+	Timeout Duration `json:"timeout"`
+	// ContinueOnFailure runs the rest of the steps even if this step fails
+	ContinueOnFailure bool `json:"continueOnFailure"`
+	// Always runs the step even after an earlier step has failed, such as to restore the network
+	Always bool `json:"always"`
+
+	Build        *BuildStep        `json:"build"`
+	WaitForBlock *WaitForBlockStep `json:"waitForBlock"`
+	Netconfig    *NetconfigStep    `json:"netconfig"`
+	Partition    *PartitionStep    `json:"partition"`
+	TxStream     *TxStreamStep     `json:"txStream"`
+	Auto         *AutoStep         `json:"auto"`
+	Sleep        *Duration         `json:"sleep"`
+	Signal       *SignalStep       `json:"signal"`
+	Kill         *NodeStep         `json:"kill"`
+	Restart      *NodeStep         `json:"restart"`
+	Assert       *AssertStep       `json:"assert"`
+}
+
+// BuildStep builds a new testnet from a build spec, the steps after it run against the new testnet
+type BuildStep struct {
+	// Spec is the path to the build spec, relative to the scenario file
+	Spec string `json:"spec"`
+	// Name is the name to give the testnet in the registry
+	Name string `json:"name"`
+}
+
+// WaitForBlockStep waits for a node to reach a block height
+type WaitForBlockStep struct {
+	Height int64 `json:"height"`
+	Node   int   `json:"node"`
+	// Interval is the time between checks of the block height, 5s by default
+	Interval Duration `json:"interval"`
+}
+
+// NetconfigStep changes the network conditions of a node, or of every node if Node is not given
+type NetconfigStep struct {
+	Node *int `json:"node"`
+	// Delay is the latency to add, such as "100ms"
+	Delay Duration `json:"delay"`
+	// Loss is the percentage of packets to drop
+	Loss float64 `json:"loss"`
+	// Rate is the bandwidth limit, such as "100mbps"
+	Rate  string `json:"rate"`
+	Limit int    `json:"limit"`
+	// Clear removes the network conditions from every node instead
+	Clear bool `json:"clear"`
+}
+
+// PartitionStep cuts the given nodes off from the rest of the network
+type PartitionStep struct {
+	Nodes []int `json:"nodes"`
+	// Heal restores all of the connections instead
+	Heal bool `json:"heal"`
+}
+
+// TxStreamStep starts a stream of transactions
+type TxStreamStep struct {
+	TPS int `json:"tps"`
+	// Value is the value of each transaction, in eth
+	Value int `json:"value"`
+	Size  int `json:"size"`
+	// Stop stops the stream of transactions instead
+	Stop bool `json:"stop"`
+}
+
+// AutoStep starts an automated load of json rpc calls on a node, like whiteblock auto
+type AutoStep struct {
+	Node      int           `json:"node"`
+	Call      string        `json:"call"`
+	Arguments []interface{} `json:"arguments"`
+	// Interval is the time between calls, 50ms by default
+	Interval   Duration `json:"interval"`
+	SampleSize int      `json:"sampleSize"`
+	// Stop stops all of the automated loads instead
+	Stop bool `json:"stop"`
+}
+
+// SignalStep sends a signal, such as SIGINT, to the main process of a node
+type SignalStep struct {
+	Node   int    `json:"node"`
+	Signal string `json:"signal"`
+}
+
+// NodeStep is an action on a single node
+type NodeStep struct {
+	Node int `json:"node"`
+}
+
+// AssertStep checks a value from the blockchain against the given bounds. Stat is either
+// "blockNumber", for the block height of Node, or a dot separated path into the stats
+// of the blockchain, such as "blockTime".
+type AssertStep struct {
+	Stat string `json:"stat"`
+	Node int    `json:"node"`
+	// Blocks selects the stats of the most recent blocks, instead of all of the blocks
+	Blocks int64    `json:"blocks"`
+	Lt     *float64 `json:"lt"`
+	Lte    *float64 `json:"lte"`
+	Gt     *float64 `json:"gt"`
+	Gte    *float64 `json:"gte"`
+	Eq     *float64 `json:"eq"`
+	// Eventually retries the check until it passes or the step times out
+	Eventually bool `json:"eventually"`
+	// Interval is the time between checks when Eventually is set, 5s by default
+	Interval Duration `json:"interval"`
+}
+
+// Action gets the name of the action of the step
+func (step Step) Action() string {
+	actions := step.actions()
+	if len(actions) != 1 {
+		return ""
+	}
+	return actions[0]
+}
+
+func (step Step) actions() []string {
+	out := []string{}
+	for action, given := range map[string]bool{
+		"build":        step.Build != nil,
+		"waitForBlock": step.WaitForBlock != nil,
+		"netconfig":    step.Netconfig != nil,
+		"partition":    step.Partition != nil,
+		"txStream":     step.TxStream != nil,
+		"auto":         step.Auto != nil,
+		"sleep":        step.Sleep != nil,
+		"signal":       step.Signal != nil,
+		"kill":         step.Kill != nil,
+		"restart":      step.Restart != nil,
+		"assert":       step.Assert != nil,
+	} {
+		if given {
+			out = append(out, action)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Title gets the name of the step, or a description of it if it has no name
+func (step Step) Title() string {
+	if len(step.Name) > 0 {
+		return step.Name
+	}
+	switch step.Action() {
+	case "build":
+		return "build " + step.Build.Spec
+	case "waitForBlock":
+		return fmt.Sprintf("wait for node %d to reach block %d", step.WaitForBlock.Node, step.WaitForBlock.Height)
+	case "partition":
+		if step.Partition.Heal {
+			return "heal the partitions"
+		}
+		return fmt.Sprintf("partition off nodes %v", step.Partition.Nodes)
+	case "sleep":
+		return "sleep " + time.Duration(*step.Sleep).String()
+	case "signal":
+		return fmt.Sprintf("send %s to node %d", step.Signal.Signal, step.Signal.Node)
+	case "kill":
+		return fmt.Sprintf("kill node %d", step.Kill.Node)
+	case "restart":
+		return fmt.Sprintf("restart node %d", step.Restart.Node)
+	case "assert":
+		return "assert " + step.Assert.Stat
+	}
+	return step.Action()
+}
+
+// DefaultTimeout gets the timeout of the step when none is given
+func (step Step) DefaultTimeout() time.Duration {
+	switch step.Action() {
+	case "build":
+		return 30 * time.Minute
+	case "waitForBlock":
+		return 10 * time.Minute
+	case "sleep":
+		return 0
+	}
+	return 2 * time.Minute
+}
+
+func (step Step) timeout() time.Duration {
+	if step.Timeout > 0 {
+		return time.Duration(step.Timeout)
+	}
+	return step.DefaultTimeout()
+}
+
+// Load reads a scenario from a yaml or json file
+func Load(path string) (*Scenario, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	scenario, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	scenario.dir = filepath.Dir(path)
+	if len(scenario.Name) == 0 {
+		scenario.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return scenario, nil
+}
+
+// Parse parses a scenario and checks that each of its steps is valid
+func Parse(data []byte) (*Scenario, error) {
+	scenario := new(Scenario)
+	err := util.UnmarshalYAMLStrict(data, scenario)
+	if err != nil {
+		return nil, util.ValidationError{Err: err}
+	}
+	return scenario, scenario.Validate()
+}
+
+// Validate checks that each step has exactly one action, along with the values it needs
+func (scenario Scenario) Validate() error {
+	if len(scenario.Steps) == 0 {
+		return util.NewValidationError("the scenario has no steps")
+	}
+	for i, step := range scenario.Steps {
+		err := step.validate()
+		if err != nil {
+			return util.NewValidationError("step %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (step Step) validate() error {
+	actions := step.actions()
+	switch len(actions) {
+	case 0:
+		return fmt.Errorf("no action given")
+	case 1:
+	default:
+		return fmt.Errorf("only one action may be given per step, given %s", strings.Join(actions, ", "))
+	}
+	switch {
+	case step.Build != nil && len(step.Build.Spec) == 0:
+		return fmt.Errorf("build: spec is required")
+	case step.WaitForBlock != nil && step.WaitForBlock.Height <= 0:
+		return fmt.Errorf("waitForBlock: height must be greater than 0")
+	case step.Partition != nil && !step.Partition.Heal && len(step.Partition.Nodes) == 0:
+		return fmt.Errorf("partition: nodes are required")
+	case step.TxStream != nil && !step.TxStream.Stop && step.TxStream.TPS <= 0:
+		return fmt.Errorf("txStream: tps must be greater than 0")
+	case step.Auto != nil && !step.Auto.Stop && len(step.Auto.Call) == 0:
+		return fmt.Errorf("auto: call is required")
+	case step.Sleep != nil && *step.Sleep < 0:
+		return fmt.Errorf("sleep: cannot be negative")
+	case step.Signal != nil && len(step.Signal.Signal) == 0:
+		return fmt.Errorf("signal: signal is required")
+	case step.Assert != nil:
+		return step.Assert.validate()
+	}
+	return nil
+}
+
+func (assert AssertStep) validate() error {
+	if len(assert.Stat) == 0 {
+		return fmt.Errorf("assert: stat is required")
+	}
+	if assert.Lt == nil && assert.Lte == nil && assert.Gt == nil && assert.Gte == nil && assert.Eq == nil {
+		return fmt.Errorf("assert: at least one of lt, lte, gt, gte or eq is required")
+	}
+	return nil
+}
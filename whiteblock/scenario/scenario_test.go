@@ -0,0 +1,250 @@
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/whiteblock/cli/whiteblock/client"
+	"github.com/whiteblock/cli/whiteblock/cmd/build"
+	"github.com/whiteblock/cli/whiteblock/util"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		data    string
+		actions []string
+		err     bool
+	}{
+		{
+			data: `
+name: partition
+steps:
+  - waitForBlock: {height: 20}
+  - netconfig: {delay: 100ms, loss: 0.5}
+  - sleep: 30
+  - partition: {heal: true}
+    always: true
+  - assert: {stat: blockNumber, gte: 40}
+`,
+			actions: []string{"waitForBlock", "netconfig", "sleep", "partition", "assert"},
+		},
+		{data: `steps: []`, err: true},
+		{data: `steps: [{name: nothing}]`, err: true},
+		{data: `steps: [{sleep: 1s, kill: {node: 1}}]`, err: true},
+		{data: `steps: [{waitForBlock: {node: 1}}]`, err: true},
+		{data: `steps: [{assert: {stat: blockNumber}}]`, err: true},
+		{data: `steps: [{sleep: soon}]`, err: true},
+		{data: `steps: [{reboot: {node: 1}}]`, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			scenario, err := Parse([]byte(tt.data))
+			if tt.err {
+				if util.ExitCode(err) != util.ExitValidation {
+					t.Errorf("expected a validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(scenario.Steps) != len(tt.actions) {
+				t.Fatalf("expected %d steps, got %d", len(tt.actions), len(scenario.Steps))
+			}
+			for j, step := range scenario.Steps {
+				if step.Action() != tt.actions[j] {
+					t.Errorf("step %d: expected %s, got %s", j, tt.actions[j], step.Action())
+				}
+			}
+		})
+	}
+}
+
+func TestLookupStat(t *testing.T) {
+	stats := map[string]interface{}{
+		"blockTime": 12.5,
+		"blocks":    "30",
+		"nodes":     []interface{}{map[string]interface{}{"up": true}},
+		"name":      "geth",
+	}
+	var tests = []struct {
+		path     string
+		expected float64
+		err      bool
+	}{
+		{path: "blockTime", expected: 12.5},
+		{path: "blocks", expected: 30},
+		{path: "nodes.0.up", expected: 1},
+		{path: "nodes.1.up", err: true},
+		{path: "name", err: true},
+		{path: "missing", err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			value, err := lookupStat(stats, tt.path)
+			if (err != nil) != tt.err {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestAssertCheck(t *testing.T) {
+	ten := 10.0
+	twenty := 20.0
+	var tests = []struct {
+		assert AssertStep
+		value  float64
+		err    bool
+	}{
+		{assert: AssertStep{Lt: &twenty}, value: 10},
+		{assert: AssertStep{Lt: &twenty}, value: 20, err: true},
+		{assert: AssertStep{Lte: &twenty}, value: 20},
+		{assert: AssertStep{Gt: &ten, Lt: &twenty}, value: 15},
+		{assert: AssertStep{Gt: &ten, Lt: &twenty}, value: 10, err: true},
+		{assert: AssertStep{Gte: &ten}, value: 10},
+		{assert: AssertStep{Eq: &ten}, value: 11, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := tt.assert.check(tt.value)
+			if (err != nil) != tt.err {
+				t.Errorf("unexpected result from check: %v", err)
+			}
+		})
+	}
+}
+
+// fakeTransport replies to the calls with the given results, failing the calls it has no result for
+type fakeTransport struct {
+	results map[string]string
+	methods []string
+	// params are the params of the last call of each method
+	params map[string]interface{}
+}
+
+func (ft *fakeTransport) Call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	ft.methods = append(ft.methods, method)
+	if ft.params == nil {
+		ft.params = map[string]interface{}{}
+	}
+	ft.params[method] = params
+	res, ok := ft.results[method]
+	if !ok {
+		return fmt.Errorf("%s failed", method)
+	}
+	return json.Unmarshal([]byte(res), out)
+}
+
+func TestRunnerRun(t *testing.T) {
+	scenario, err := Parse([]byte(`
+steps:
+  - waitForBlock: {height: 5, interval: 0.01}
+  - partition: {nodes: [0]}
+  - kill: {node: 1}
+  - assert: {stat: blockNumber, gte: 100}
+  - restart: {node: 1}
+  - partition: {heal: true}
+    always: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ft := &fakeTransport{results: map[string]string{
+		"get_block_number":   "5",
+		"partition_outage":   "null",
+		"remove_all_outages": "null",
+	}}
+	runner := Runner{Client: client.New(ft), TestnetID: "testnet1"}
+	report := runner.Run(context.Background(), scenario)
+
+	expected := []string{StatusPassed, StatusPassed, StatusFailed, StatusSkipped, StatusSkipped, StatusPassed}
+	for i, res := range report.Steps {
+		if res.Status != expected[i] {
+			t.Errorf("step %d: expected %s, got %s", i+1, expected[i], res.Status)
+		}
+	}
+	if report.Passed() {
+		t.Error("expected the scenario to fail")
+	}
+	if util.ExitCode(report.Err()) != util.ExitTestFailure {
+		t.Errorf("unexpected exit code for %v", report.Err())
+	}
+	expectedMethods := []string{"get_block_number", "partition_outage", "kill_node", "remove_all_outages", "get_build"}
+	if fmt.Sprint(ft.methods) != fmt.Sprint(expectedMethods) {
+		t.Errorf("expected the calls %v, got %v", expectedMethods, ft.methods)
+	}
+}
+
+func TestRunnerBuild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenario")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var tests = []struct {
+		spec    string
+		servers []int
+		methods []string
+	}{
+		{
+			spec:    "blockchain: testchain\nnodes: 2\nimage: testchain:latest\n",
+			servers: []int{7},
+			methods: []string{"get_servers", "build", "build_status"},
+		},
+		{
+			spec:    "blockchain: testchain\nnodes: 2\nimage: testchain:latest\nservers: [3]\n",
+			servers: []int{3},
+			methods: []string{"build", "build_status"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := ioutil.WriteFile(filepath.Join(dir, "spec.yaml"), []byte(tt.spec), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scenario := &Scenario{dir: dir}
+			ft := &fakeTransport{results: map[string]string{
+				"get_servers":  `{"server7": {"id": 7, "addr": "10.0.0.7"}}`,
+				"build":        `"testnet-built"`,
+				"build_status": `{"progress": 100}`,
+			}}
+			runner := Runner{Client: client.New(ft)}
+			_, err = runner.build(context.Background(), scenario.dir, &BuildStep{Spec: "spec.yaml"})
+			defer util.RemoveTestnet("testnet-built")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(ft.methods) != fmt.Sprint(tt.methods) {
+				t.Errorf("expected the calls %v, got %v", tt.methods, ft.methods)
+			}
+			bconf, ok := ft.params["build"].(build.Config)
+			if !ok {
+				t.Fatalf("expected the build to be sent a build.Config, got %T", ft.params["build"])
+			}
+			if !reflect.DeepEqual(bconf.Servers, tt.servers) {
+				t.Errorf("expected the servers %v, got %v", tt.servers, bconf.Servers)
+			}
+			if bconf.Nodes != 2 || !reflect.DeepEqual(bconf.Images, []string{"testchain:latest", "testchain:latest"}) {
+				t.Errorf("unexpected build sent: %+v", bconf)
+			}
+			if runner.TestnetID != "testnet-built" {
+				t.Errorf("expected the runner to use the built testnet, got %s", runner.TestnetID)
+			}
+		})
+	}
+}
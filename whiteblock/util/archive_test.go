@@ -0,0 +1,79 @@
+package util
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type tarEntry struct {
+	name string
+	data string
+	dir  bool
+}
+
+func makeTarGz(t *testing.T, entries []tarEntry) []byte {
+	buf := bytes.Buffer{}
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.data)), Typeflag: tar.TypeReg}
+		if entry.dir {
+			header.Typeflag = tar.TypeDir
+			header.Mode = 0755
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		archive.Write([]byte(entry.data))
+	}
+	archive.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestExtractTarGz(t *testing.T) {
+	var tests = []struct {
+		entries  []tarEntry
+		expected map[string]string
+		err      bool
+	}{
+		{
+			entries: []tarEntry{{name: "geth/", dir: true}, {name: "geth/genesis.json", data: "{}"},
+				{name: "var/log/core.1", data: "core"}},
+			expected: map[string]string{"geth/genesis.json": "{}", "var/log/core.1": "core"},
+		},
+		{entries: []tarEntry{{name: "../outside", data: "x"}}, err: true},
+		{entries: []tarEntry{{name: "geth/../../outside", data: "x"}}, err: true},
+		{entries: []tarEntry{{name: "/etc/passwd", data: "x"}}, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "extract")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			files, err := ExtractTarGz(bytes.NewReader(makeTarGz(t, tt.entries)), dir)
+			if tt.err {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := readTree(t, dir)
+			if !reflect.DeepEqual(out, tt.expected) || files != len(tt.expected) {
+				t.Errorf("the files extracted do not match the expected files: %d %v", files, out)
+			}
+		})
+	}
+}
@@ -4,23 +4,35 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"os"
+	"time"
 )
 
 // Config groups all of the global configuration parameters into
 // a single struct
 type Config struct {
-	APIURL            string  `mapstructure:"apiURL"`
-	Verbosity         string  `mapstructure:"verbosity"`
-	HTTPTimeout       int64   `mapstructure:"httpTimeout"`
-	HTTPRetries       int     `mapstructure:"httpRetries"`
-	StoreDirectory    string  `mapstructure:"storeDirectory"`
-	ServerAddr        string  `mapstructure:"serverAddr"`
-	CheckLoad         bool    `mapstructure:"checkLoad"`
-	LoadWarnThreshold float64 `mapstructure:"loadWarnThreshold"`
-	MaxConns          int64   `mapstructure:"maxConns"`
-	RPCRetries        int     `mapstructure:"rpcRetries"`
-	SSHPrivateKey     string  `mapstructure:"sshPrivateKey"`
-	SSHBinary         string  `mapstructure:"sshBinary"`
+	APIURL             string  `mapstructure:"apiURL"`
+	Verbosity          string  `mapstructure:"verbosity"`
+	HTTPTimeout        int64   `mapstructure:"httpTimeout"`
+	HTTPRetries        int     `mapstructure:"httpRetries"`
+	StoreDirectory     string  `mapstructure:"storeDirectory"`
+	ServerAddr         string  `mapstructure:"serverAddr"`
+	CheckLoad          bool    `mapstructure:"checkLoad"`
+	LoadWarnThreshold  float64 `mapstructure:"loadWarnThreshold"`
+	MaxConns           int64   `mapstructure:"maxConns"`
+	RPCRetries         int     `mapstructure:"rpcRetries"`
+	SSHPrivateKey      string  `mapstructure:"sshPrivateKey"`
+	Output             string  `mapstructure:"output"`
+	Scheme             string  `mapstructure:"scheme"`
+	Context            string  `mapstructure:"context"`
+	ServerURL          string  `mapstructure:"serverURL"`
+	CACert             string  `mapstructure:"caCert"`
+	ClientCert         string  `mapstructure:"clientCert"`
+	ClientKey          string  `mapstructure:"clientKey"`
+	InsecureSkipVerify bool    `mapstructure:"insecureSkipVerify"`
+	NetemPresets       string  `mapstructure:"netemPresets"`
+	// BuildTimeout is how long to follow a build before giving up on it, 0 for no limit
+	BuildTimeout time.Duration `mapstructure:"buildTimeout"`
+	BuildHooks   string        `mapstructure:"buildHooks"`
 }
 
 var conf = new(Config)
@@ -37,7 +49,17 @@ func setViperEnvBindings() {
 	viper.BindEnv("maxConns", "MAX_CONNS")
 	viper.BindEnv("rpcRetries", "RPC_RETRIES")
 	viper.BindEnv("sshPrivateKey", "SSH_PRIVATE_KEY")
-	viper.BindEnv("sshBinary", "SSH_BINARY")
+	viper.BindEnv("output", "OUTPUT")
+	viper.BindEnv("scheme", "SCHEME")
+	viper.BindEnv("context", "WHITEBLOCK_CONTEXT")
+	viper.BindEnv("serverURL", "SERVER_URL")
+	viper.BindEnv("caCert", "CA_CERT")
+	viper.BindEnv("clientCert", "CLIENT_CERT")
+	viper.BindEnv("clientKey", "CLIENT_KEY")
+	viper.BindEnv("insecureSkipVerify", "INSECURE_SKIP_VERIFY")
+	viper.BindEnv("netemPresets", "NETEM_PRESETS")
+	viper.BindEnv("buildTimeout", "BUILD_TIMEOUT")
+	viper.BindEnv("buildHooks", "BUILD_HOOKS")
 }
 func setViperDefaults() {
 	viper.SetDefault("apiURL", "https://api.whiteblock.io")
@@ -51,7 +73,17 @@ func setViperDefaults() {
 	viper.SetDefault("maxConns", 200)
 	viper.SetDefault("rpcRetries", 20)
 	viper.SetDefault("sshPrivateKey", "/home/master-secrets/id.master")
-	viper.SetDefault("sshBinary", "/usr/bin/ssh")
+	viper.SetDefault("output", "")
+	viper.SetDefault("scheme", "")
+	viper.SetDefault("context", "")
+	viper.SetDefault("serverURL", "")
+	viper.SetDefault("caCert", "")
+	viper.SetDefault("clientCert", "")
+	viper.SetDefault("clientKey", "")
+	viper.SetDefault("insecureSkipVerify", false)
+	viper.SetDefault("netemPresets", "")
+	viper.SetDefault("buildTimeout", "0s")
+	viper.SetDefault("buildHooks", "")
 }
 
 func init() {
@@ -0,0 +1,201 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultContext is the name of the context which is used when no other context has been chosen.
+// It uses the server from the config file and the store as it was before contexts existed.
+const DefaultContext = "default"
+
+const (
+	contextsKey       = "contexts"
+	currentContextKey = "current_context"
+)
+
+// contextScopedKeys are the keys of the store which belong to a server, each context
+// has its own copy of them
+var contextScopedKeys = map[string]bool{
+	"jwt":                  true,
+	"profile":              true,
+	"biome":                true,
+	"previous_build_id":    true,
+	"in_progress_build_id": true,
+	"testnets":             true,
+}
+
+var activeContext = DefaultContext
+
+// ServerContext is a named server to run the commands against, along with
+// its own credentials and builds
+type ServerContext struct {
+	Name       string `json:"name"`
+	ServerAddr string `json:"serverAddr,omitempty"`
+	Scheme     string `json:"scheme,omitempty"`
+	APIURL     string `json:"apiURL,omitempty"`
+
+	CACert             string `json:"caCert,omitempty"`
+	ClientCert         string `json:"clientCert,omitempty"`
+	ClientKey          string `json:"clientKey,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// scopedKey gets the key in the store for the given key, under the context in use
+func scopedKey(key string) string {
+	return contextKey(activeContext, key)
+}
+
+func contextKey(name string, key string) string {
+	if name == DefaultContext || !contextScopedKeys[key] {
+		return key
+	}
+	return "context/" + name + "/" + key
+}
+
+// GetContexts gets all of the stored contexts, by name
+func GetContexts() (map[string]ServerContext, error) {
+	out := map[string]ServerContext{}
+	if !Exists(contextsKey) {
+		return out, nil
+	}
+	return out, GetP(contextsKey, &out)
+}
+
+// GetContext gets the context with the given name
+func GetContext(name string) (ServerContext, error) {
+	if name == DefaultContext {
+		return ServerContext{Name: DefaultContext}, nil
+	}
+	contexts, err := GetContexts()
+	if err != nil {
+		return ServerContext{}, err
+	}
+	sctx, ok := contexts[name]
+	if !ok {
+		return ServerContext{}, NewValidationError(`context "%s" does not exist, see "whiteblock context list"`, name)
+	}
+	return sctx, nil
+}
+
+// ContextNames gets the names of all of the contexts, including the default context
+func ContextNames() ([]string, error) {
+	contexts, err := GetContexts()
+	if err != nil {
+		return nil, err
+	}
+	out := []string{}
+	for name := range contexts {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return append([]string{DefaultContext}, out...), nil
+}
+
+// SaveContext adds or updates a context
+func SaveContext(sctx ServerContext) error {
+	if len(sctx.Name) == 0 || sctx.Name == DefaultContext || strings.Contains(sctx.Name, "/") {
+		return NewValidationError(`invalid context name "%s"`, sctx.Name)
+	}
+	contexts, err := GetContexts()
+	if err != nil {
+		return err
+	}
+	contexts[sctx.Name] = sctx
+	return Set(contextsKey, contexts)
+}
+
+// DeleteContext removes a context along with everything stored for it
+func DeleteContext(name string) error {
+	if name == DefaultContext {
+		return NewValidationError("the default context cannot be deleted")
+	}
+	contexts, err := GetContexts()
+	if err != nil {
+		return err
+	}
+	if _, ok := contexts[name]; !ok {
+		return NewValidationError(`context "%s" does not exist`, name)
+	}
+	delete(contexts, name)
+	for key := range contextScopedKeys {
+		deleteRaw(contextKey(name, key))
+	}
+	if CurrentContextName() == name {
+		Delete(currentContextKey)
+	}
+	return Set(contextsKey, contexts)
+}
+
+// CurrentContextName gets the name of the context chosen with "context use"
+func CurrentContextName() string {
+	var name string
+	if GetP(currentContextKey, &name) != nil || len(name) == 0 {
+		return DefaultContext
+	}
+	return name
+}
+
+// SetCurrentContext chooses the context to use from now on
+func SetCurrentContext(name string) error {
+	_, err := GetContext(name)
+	if err != nil {
+		return err
+	}
+	if name == DefaultContext {
+		return Delete(currentContextKey)
+	}
+	return Set(currentContextKey, name)
+}
+
+// ActiveContext gets the name of the context in use by this execution
+func ActiveContext() string {
+	return activeContext
+}
+
+// UseContext switches this execution over to the given context, applying its
+// server settings to the config
+func UseContext(name string) error {
+	sctx, err := GetContext(name)
+	if err != nil {
+		return err
+	}
+	activeContext = name
+	if len(sctx.ServerAddr) > 0 {
+		conf.ServerAddr = sctx.ServerAddr
+	}
+	if len(sctx.Scheme) > 0 {
+		conf.Scheme = sctx.Scheme
+	}
+	if len(sctx.APIURL) > 0 {
+		conf.APIURL = sctx.APIURL
+	}
+	if len(sctx.ServerAddr) > 0 || len(sctx.Scheme) > 0 {
+		conf.ServerURL = "" //the context is more specific than the config
+	}
+	if len(sctx.CACert) > 0 {
+		conf.CACert = sctx.CACert
+	}
+	if len(sctx.ClientCert) > 0 {
+		conf.ClientCert = sctx.ClientCert
+		conf.ClientKey = sctx.ClientKey
+	}
+	if name != DefaultContext {
+		conf.InsecureSkipVerify = sctx.InsecureSkipVerify
+	}
+	return nil
+}
+
+// GetContextValue fetches the value of a key stored for the given context
+func GetContextValue(name string, key string, v interface{}) error {
+	return getRaw(contextKey(name, key), v)
+}
+
+// SetContextValue stores the value of a key for the given context
+func SetContextValue(name string, key string, v interface{}) error {
+	if !contextScopedKeys[key] {
+		return fmt.Errorf("%s is not stored per context", key)
+	}
+	return setRaw(contextKey(name, key), v)
+}
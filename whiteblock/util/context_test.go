@@ -0,0 +1,70 @@
+package util
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestContextKey(t *testing.T) {
+	var tests = []struct {
+		name     string
+		key      string
+		expected string
+	}{
+		{name: DefaultContext, key: "jwt", expected: "jwt"},
+		{name: "lab", key: "jwt", expected: "context/lab/jwt"},
+		{name: "lab", key: "previous_build_id", expected: "context/lab/previous_build_id"},
+		{name: "lab", key: "contexts", expected: "contexts"},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if contextKey(tt.name, tt.key) != tt.expected {
+				t.Errorf("return value of contextKey does not match expected value: %s", contextKey(tt.name, tt.key))
+			}
+		})
+	}
+}
+
+func TestUseContextInsecure(t *testing.T) {
+	saved := *conf
+	active := activeContext
+	defer func() {
+		*conf = saved
+		activeContext = active
+	}()
+	contexts, err := GetContexts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Set(contextsKey, contexts)
+
+	err = SaveContext(ServerContext{Name: "test-insecure", InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = SaveContext(ServerContext{Name: "test-secure"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		name     string
+		expected bool
+	}{
+		{name: "test-insecure", expected: true},
+		{name: DefaultContext, expected: true},
+		{name: "test-secure", expected: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := UseContext(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if conf.InsecureSkipVerify != tt.expected {
+				t.Errorf("expected InsecureSkipVerify to be %v after using %s", tt.expected, tt.name)
+			}
+		})
+	}
+}
@@ -54,7 +54,11 @@ func CreateTable() error {
 
 //Set stores a key value pair in the sql-lite database as json
 func Set(key string, value interface{}) error {
-	Delete(key)
+	return setRaw(scopedKey(key), value)
+}
+
+func setRaw(key string, value interface{}) error {
+	deleteRaw(key)
 	tx, err := db.Begin()
 	if err != nil {
 		return err
@@ -81,6 +85,10 @@ func Set(key string, value interface{}) error {
 
 //GetP fetches the value of key and returns it to v, v should be a pointer
 func GetP(key string, v interface{}) error {
+	return getRaw(scopedKey(key), v)
+}
+
+func getRaw(key string, v interface{}) error {
 	row := db.QueryRow(fmt.Sprintf("SELECT value FROM meta WHERE key = \"%s\"", key))
 	var data []byte
 	err := row.Scan(&data)
@@ -97,6 +105,10 @@ func Exists(key string) bool {
 
 //Delete deletes the value stored at key
 func Delete(key string) error {
+	return deleteRaw(scopedKey(key))
+}
+
+func deleteRaw(key string) error {
 	_, err := db.Exec(fmt.Sprintf("DELETE FROM meta WHERE key = \"%s\"", key))
 	return err
 }
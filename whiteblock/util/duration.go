@@ -0,0 +1,36 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Duration is a time.Duration which is given in a file as either a string
+// such as "1m30s", or as a number of seconds
+type Duration time.Duration
+
+// UnmarshalJSON parses a Duration from a string or a number of seconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var secs float64
+	if json.Unmarshal(data, &secs) == nil {
+		*d = Duration(secs * float64(time.Second))
+		return nil
+	}
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return fmt.Errorf("expected a duration such as \"30s\", got %s", string(data))
+	}
+	dur, err := time.ParseDuration(str)
+	if err != nil {
+		return err
+	}
+	*d = Duration(dur)
+	return nil
+}
+
+// MarshalJSON gives the Duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDurationUnmarshal(t *testing.T) {
+	var tests = []struct {
+		data     string
+		expected time.Duration
+	}{
+		{data: `"1m30s"`, expected: 90 * time.Second},
+		{data: `2`, expected: 2 * time.Second},
+		{data: `0.5`, expected: 500 * time.Millisecond},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var d Duration
+			err := json.Unmarshal([]byte(tt.data), &d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if time.Duration(d) != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, time.Duration(d))
+			}
+		})
+	}
+}
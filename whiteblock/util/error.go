@@ -1,9 +1,11 @@
 package util
 
 import (
+	"errors"
 	"fmt"
-	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/rpc"
 	"os"
+	"time"
 )
 
 const (
@@ -15,72 +17,184 @@ const (
  * Unify error messages through function calls
  */
 
-func CheckArguments(cmd *cobra.Command, args []string, min int, max int) {
+// CheckArguments checks that there are between min and max args, giving a ValidationError if not
+func CheckArguments(args []string, min int, max int) error {
 	if min == max && len(args) != min {
-		fmt.Println(cmd.UsageString())
 		plural := "s"
 		if min == 1 {
 			plural = ""
 		}
-		PrintErrorFatal(fmt.Sprintf("Invalid number of arguments. Expected exactly %d argument%s. Given %d.", min, plural, len(args)))
+		return NewValidationError("Invalid number of arguments. Expected exactly %d argument%s. Given %d.", min, plural, len(args))
 	}
 	if len(args) < min {
-		fmt.Println(cmd.UsageString())
 		plural := "s"
 		if min == 1 {
 			plural = ""
 		}
-		PrintErrorFatal(fmt.Sprintf("Missing arguments. Expected atleast %d argument%s. Given %d.", min, plural, len(args)))
+		return NewValidationError("Missing arguments. Expected atleast %d argument%s. Given %d.", min, plural, len(args))
 	}
 	if max != NoMaxArgs && len(args) > max {
-		fmt.Println(cmd.UsageString())
 		plural := "s"
 		if max == 1 {
 			plural = ""
 		}
-		PrintErrorFatal(fmt.Sprintf("Too many arguments. Expected atmost %d argument%s. Given %d.", max, plural, len(args)))
+		return NewValidationError("Too many arguments. Expected atmost %d argument%s. Given %d.", max, plural, len(args))
 	}
+	return nil
 }
 
 func InvalidArgument(arg string) {
 	PrintStringError(fmt.Sprintf("Invalid argument given: %s.", arg))
 }
 
-func InvalidInteger(name string, value string, fatal bool) {
-	PrintStringError(fmt.Sprintf("Invalid integer, given \"%s\" for %s.", value, name))
-	if fatal {
-		os.Exit(1)
-	}
+func InvalidInteger(name string, value string) error {
+	return NewValidationError("Invalid integer, given \"%s\" for %s.", value, name)
 }
 
-func CheckIntegerBounds(cmd *cobra.Command, name string, val int, min int, max int) {
+func CheckIntegerBounds(name string, val int, min int, max int) error {
 	if val < min {
-		PrintErrorFatal(fmt.Sprintf("The value given for %s, %d cannot be less than %d.", name, val, min))
+		return NewValidationError("The value given for %s, %d cannot be less than %d.", name, val, min)
 	} else if val > max {
-		PrintErrorFatal(fmt.Sprintf("The value given for %s, %d cannot be greater than %d.", name, val, max))
+		return NewValidationError("The value given for %s, %d cannot be greater than %d.", name, val, max)
 	}
+	return nil
 }
 
-func ClientNotSupported(client string) {
-	PrintErrorFatal(fmt.Sprintf("This function is not supported for %s.", client))
-}
-
-func MalformedUsageError(cmd *cobra.Command, err interface{}) {
-	fmt.Println(cmd.UsageString())
-	PrintErrorFatal(err)
+func ClientNotSupported(client string) error {
+	return NewValidationError("This function is not supported for %s.", client)
 }
 
-func FlagNotProvidedError(cmd *cobra.Command, flagName string) {
-	fmt.Println(cmd.UsageString())
-	PrintErrorFatal(fmt.Sprintf(`missing required flag: "%s"`, flagName))
+func FlagNotProvidedError(flagName string) error {
+	return NewValidationError(`missing required flag: "%s"`, flagName)
 }
 
 func PrintErrorFatal(err interface{}) {
 	PrintStringError(fmt.Sprint(err))
-	Print("If you believe this is a bug, please file an issue: https://github.com/whiteblock/cli/issues/new?template=bug_report.md")
-	os.Exit(1)
+	code := ExitGeneric
+	if e, ok := err.(error); ok {
+		code = ExitCode(e)
+	}
+	if OutputFormat() == OutputDefault && code == ExitGeneric {
+		Print("If you believe this is a bug, please file an issue: https://github.com/whiteblock/cli/issues/new?template=bug_report.md")
+	}
+	os.Exit(code)
 }
 
+// PrintStringError prints an error message, which goes to stderr when the output is meant for
+// another program, so that it does not get mixed in with the output.
 func PrintStringError(err string) {
-	fmt.Printf("\033[31mError:\033[0m %s\n", err)
+	out := os.Stdout
+	if OutputFormat() != OutputDefault {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "%s %s\n", Colorize("Error:", "31"), err)
+}
+
+// The exit codes for each category of error, so that scripts can tell a server which is
+// down apart from bad input
+const (
+	ExitGeneric         = 1
+	ExitValidation      = 2
+	ExitTransport       = rpc.ExitTransport
+	ExitAuth            = rpc.ExitAuth
+	ExitRPC             = rpc.ExitRPC
+	ExitNoPreviousBuild = 6
+	ExitTestFailure     = 7
+	ExitTimeout         = 8
+	ExitInterrupted     = rpc.ExitInterrupted
+)
+
+// ExitCoder is implemented by the errors which have their own exit code
+type ExitCoder interface {
+	ExitCode() int
+}
+
+// ExitCode gets the exit code for err, following any wrapped errors
+func ExitCode(err error) int {
+	var coder ExitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	return ExitGeneric
+}
+
+// The errors of the json rpc calls, which are kept in the rpc package so that it has no
+// dependency on the configuration of the command line
+type (
+	RPCError       = rpc.RPCError
+	TransportError = rpc.TransportError
+	AuthError      = rpc.AuthError
+	InterruptError = rpc.InterruptError
+)
+
+// ValidationError is an error in the input given by the user
+type ValidationError struct {
+	Err error
+}
+
+// NewValidationError creates a ValidationError with the given message
+func NewValidationError(format string, a ...interface{}) error {
+	return ValidationError{Err: fmt.Errorf(format, a...)}
+}
+
+func (e ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode is ExitValidation
+func (e ValidationError) ExitCode() int {
+	return ExitValidation
+}
+
+// NoPreviousBuildError is given when a command needs a testnet, but there has not been a build
+type NoPreviousBuildError struct{}
+
+func (e NoPreviousBuildError) Error() string {
+	return ErrNoPreviousBuild + ". Use build command to deploy a blockchain, " +
+		"or run `whiteblock sync` if you already have a blockchain deployed."
+}
+
+// ExitCode is ExitNoPreviousBuild
+func (e NoPreviousBuildError) ExitCode() int {
+	return ExitNoPreviousBuild
+}
+
+// TimeoutError is given when something takes longer than it was allowed to
+type TimeoutError struct {
+	What  string
+	After time.Duration
+	// Hint is what may be done about it, if anything
+	Hint string
+}
+
+func (e TimeoutError) Error() string {
+	msg := fmt.Sprintf("%s did not finish within %v", e.What, e.After)
+	if len(e.Hint) > 0 {
+		msg += ", " + e.Hint
+	}
+	return msg
+}
+
+// ExitCode is ExitTimeout
+func (e TimeoutError) ExitCode() int {
+	return ExitTimeout
+}
+
+// RemoteExitError is given when a command run on a node exits with a status other than 0, so
+// that the cli may exit with the same status
+type RemoteExitError struct {
+	Code int
+}
+
+func (e RemoteExitError) Error() string {
+	return fmt.Sprintf("the command exited with status %d", e.Code)
+}
+
+// ExitCode is the exit status of the command
+func (e RemoteExitError) ExitCode() int {
+	return e.Code
 }
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"fmt"
+	"github.com/gorilla/rpc/v2/json2"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestExitCode(t *testing.T) {
+	var tests = []struct {
+		err      error
+		expected int
+	}{
+		{err: fmt.Errorf("something"), expected: ExitGeneric},
+		{err: NewValidationError("bad %s", "input"), expected: ExitValidation},
+		{err: TransportError{URL: "http://127.0.0.1", Err: fmt.Errorf("refused")}, expected: ExitTransport},
+		{err: AuthError{Err: fmt.Errorf("expired")}, expected: ExitAuth},
+		{err: RPCError{Method: "nodes", Err: &json2.Error{Message: "failed"}}, expected: ExitRPC},
+		{err: NoPreviousBuildError{}, expected: ExitNoPreviousBuild},
+		{err: TimeoutError{What: "the build", After: time.Minute}, expected: ExitTimeout},
+		{err: RemoteExitError{Code: 42}, expected: 42},
+		{err: fmt.Errorf("wrapped: %w", AuthError{Err: fmt.Errorf("expired")}), expected: ExitAuth},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if ExitCode(tt.err) != tt.expected {
+				t.Errorf("return value of ExitCode does not match expected value: %d", ExitCode(tt.err))
+			}
+		})
+	}
+}
+
+func TestRPCErrorData(t *testing.T) {
+	err := RPCError{Method: "build", Err: &json2.Error{Message: "failed", Data: map[string]string{"what": "no nodes"}}}
+	if err.Error() != `{"what":"no nodes"}` {
+		t.Errorf("expected the error data to be given, got %s", err.Error())
+	}
+	err = RPCError{Method: "build", Err: &json2.Error{Message: "failed"}}
+	if err.Error() != "failed" {
+		t.Errorf("expected the error message to be given, got %s", err.Error())
+	}
+}
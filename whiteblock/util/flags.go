@@ -4,43 +4,28 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func RequireFlags(cmd *cobra.Command, flags ...string) {
+// RequireFlags gives an error for the first of flags which has not been given
+func RequireFlags(cmd *cobra.Command, flags ...string) error {
 	for _, flag := range flags {
 		if !cmd.Flags().Changed(flag) {
-			FlagNotProvidedError(cmd, flag)
-			return
+			return FlagNotProvidedError(flag)
 		}
 	}
+	return nil
 }
 
-func GetStringFlagValue(cmd *cobra.Command, flag string) string {
-	out, err := cmd.Flags().GetString(flag)
-	if err != nil {
-		PrintErrorFatal(err)
-	}
-	return out
+func GetStringFlagValue(cmd *cobra.Command, flag string) (string, error) {
+	return cmd.Flags().GetString(flag)
 }
 
-func GetIntFlagValue(cmd *cobra.Command, flag string) int {
-	out, err := cmd.Flags().GetInt(flag)
-	if err != nil {
-		PrintErrorFatal(err)
-	}
-	return out
+func GetIntFlagValue(cmd *cobra.Command, flag string) (int, error) {
+	return cmd.Flags().GetInt(flag)
 }
 
-func GetFloat64FlagValue(cmd *cobra.Command, flag string) float64 {
-	out, err := cmd.Flags().GetFloat64(flag)
-	if err != nil {
-		PrintErrorFatal(err)
-	}
-	return out
+func GetFloat64FlagValue(cmd *cobra.Command, flag string) (float64, error) {
+	return cmd.Flags().GetFloat64(flag)
 }
 
-func GetBoolFlagValue(cmd *cobra.Command, flag string) bool {
-	out, err := cmd.Flags().GetBool(flag)
-	if err != nil {
-		PrintErrorFatal(err)
-	}
-	return out
+func GetBoolFlagValue(cmd *cobra.Command, flag string) (bool, error) {
+	return cmd.Flags().GetBool(flag)
 }
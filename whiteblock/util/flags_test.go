@@ -8,7 +8,18 @@ import (
 )
 
 func TestRequireFlags(t *testing.T) {
-	// TODO test
+	command := new(cobra.Command)
+	command.Flags().String("a", "", "blah")
+	command.Flags().String("b", "", "blah")
+	command.ParseFlags([]string{"--a", "x"})
+
+	if err := RequireFlags(command, "a"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err := RequireFlags(command, "a", "b")
+	if ExitCode(err) != ExitValidation {
+		t.Errorf("expected a validation error for the missing flag, got %v", err)
+	}
 }
 
 func TestGetStringFlagValue(t *testing.T) {
@@ -18,7 +29,11 @@ func TestGetStringFlagValue(t *testing.T) {
 	flag := "test"
 	expected := "string"
 
-	if !reflect.DeepEqual(GetStringFlagValue(command, flag), expected) {
+	out, err := GetStringFlagValue(command, flag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, expected) {
 		t.Error("return value of GetStringFlagValue")
 	}
 }
@@ -30,7 +45,11 @@ func TestGetIntFlagValue(t *testing.T) {
 	flag := "test"
 	expected := 14
 
-	if !reflect.DeepEqual(GetIntFlagValue(command, flag), expected) {
+	out, err := GetIntFlagValue(command, flag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, expected) {
 		t.Error("return value of GetStringFlagValue")
 	}
 }
@@ -42,7 +61,11 @@ func TestGetBoolFlagValue(t *testing.T) {
 	flag := "test"
 	expected := true
 
-	if !reflect.DeepEqual(GetBoolFlagValue(command, flag), expected) {
+	out, err := GetBoolFlagValue(command, flag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, expected) {
 		t.Error("return value of GetStringFlagValue")
 	}
 }
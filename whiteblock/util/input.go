@@ -16,7 +16,10 @@ func ParseIntToStringSlice(vals []string) (map[int][]string, error) {
 		if len(splitVal) != 2 {
 			return nil, fmt.Errorf("unexpected value %s", val)
 		}
-		index := CheckAndConvertInt(splitVal[0], "index")
+		index, err := CheckAndConvertInt(splitVal[0], "index")
+		if err != nil {
+			return nil, err
+		}
 		if _, ok := out[index]; !ok {
 			out[index] = []string{splitVal[1]}
 		} else {
@@ -46,16 +49,16 @@ func GetAsBool(input string) (bool, error) {
 	}
 }
 
-func YesNoPrompt(msg string) bool {
+func YesNoPrompt(msg string) (bool, error) {
 	if !IsTTY() {
-		PrintErrorFatal("not a tty. Did you forget to include -y in your script?")
+		return false, NewValidationError("not a tty. Did you forget to include -y in your script?")
 	}
 	scanner := bufio.NewScanner(os.Stdin)
 
 	for {
 		fmt.Printf("%s ([y]es/[n]o) ", msg)
 		if !scanner.Scan() {
-			PrintErrorFatal(scanner.Err())
+			return false, endOfInput(scanner)
 		}
 		ask := scanner.Text()
 		res, err := GetAsBool(ask)
@@ -63,14 +66,13 @@ func YesNoPrompt(msg string) bool {
 			fmt.Println(err)
 			continue
 		}
-		return res
+		return res, nil
 	}
-	panic("should never reach")
 }
 
-func OptionListPrompt(msg string, options []string) int {
+func OptionListPrompt(msg string, options []string) (int, error) {
 	if !IsTTY() {
-		PrintErrorFatal("not a TTY, failed to give option prompt")
+		return -1, NewValidationError("not a TTY, failed to give option prompt")
 	}
 	scanner := bufio.NewScanner(os.Stdin)
 
@@ -82,21 +84,28 @@ func OptionListPrompt(msg string, options []string) int {
 		fmt.Printf("\nenter your selection: ")
 
 		if !scanner.Scan() {
-			PrintErrorFatal(scanner.Err())
+			return -1, endOfInput(scanner)
 		}
 		userResponse := scanner.Text()
 		selection, err := strconv.Atoi(userResponse)
 		if err != nil {
-			InvalidInteger("selection", userResponse, false)
+			PrintStringError(InvalidInteger("selection", userResponse).Error())
 			continue
 		}
 		if selection >= len(options) || selection < 0 {
 			fmt.Println("option does not exist")
 			continue
 		}
-		return selection
+		return selection, nil
 	}
-	panic("should never reach")
+}
+
+// endOfInput gets the error for a prompt which ran out of input before it was answered
+func endOfInput(scanner *bufio.Scanner) error {
+	if scanner.Err() != nil {
+		return scanner.Err()
+	}
+	return NewValidationError("no answer was given to the prompt")
 }
 
 func ArgsToJSON(args []string) []interface{} {
@@ -113,3 +122,22 @@ func ArgsToJSON(args []string) []interface{} {
 	}
 	return out
 }
+
+// ShellJoin joins args into a command for a shell, quoting any which would otherwise be split
+// or expanded. A single argument is given as it is, so that it may hold a whole pipeline.
+func ShellJoin(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	out := []string{}
+	for _, arg := range args {
+		if len(arg) > 0 && strings.IndexFunc(arg, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:,+@%", r))
+		}) == -1 {
+			out = append(out, arg)
+			continue
+		}
+		out = append(out, "'"+strings.Replace(arg, "'", `'\''`, -1)+"'")
+	}
+	return strings.Join(out, " ")
+}
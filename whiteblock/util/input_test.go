@@ -150,3 +150,23 @@ func TestArgsToJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestShellJoin(t *testing.T) {
+	var tests = []struct {
+		args     []string
+		expected string
+	}{
+		{args: []string{"df -h | grep /dev"}, expected: "df -h | grep /dev"},
+		{args: []string{"ls", "-la", "/etc"}, expected: "ls -la /etc"},
+		{args: []string{"grep", "a b", "/etc/conf"}, expected: "grep 'a b' /etc/conf"},
+		{args: []string{"echo", "it's", "$HOME", ""}, expected: `echo 'it'\''s' '$HOME' ''`},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if ShellJoin(tt.args) != tt.expected {
+				t.Errorf("return value of ShellJoin does not match expected value: %s", ShellJoin(tt.args))
+			}
+		})
+	}
+}
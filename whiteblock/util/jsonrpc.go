@@ -1,30 +1,25 @@
 package util
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"github.com/gorilla/rpc/v2/json2"
 	log "github.com/sirupsen/logrus"
+	"github.com/whiteblock/cli/whiteblock/rpc"
 	"net/http"
-	"strings"
+	"os"
+	"time"
 )
 
-func JsonRpcCallAndPrint(method string, params interface{}) {
+// JsonRpcCallAndPrint makes a json rpc call and prints the result, using the given columns
+// for --output table
+func JsonRpcCallAndPrint(method string, params interface{}, columns ...string) error {
 	reply, err := JsonRpcCall(method, params)
 	if err != nil {
-		jsonError, ok := err.(*json2.Error)
-		if ok && jsonError.Data != nil {
-			res, err := json.Marshal(jsonError.Data)
-			if err != nil {
-				PrintErrorFatal(err)
-			}
-			PrintErrorFatal(string(res))
-		} else {
-			PrintErrorFatal(err)
-		}
+		return err
 	}
-	Print(reply)
+	return Render(os.Stdout, reply, columns...)
 }
+
 func JsonRpcCallP(method string, params interface{}, out interface{}) error {
 	res, err := JsonRpcCall(method, params)
 	if err != nil {
@@ -37,57 +32,41 @@ func JsonRpcCallP(method string, params interface{}, out interface{}) error {
 	return json.Unmarshal(tmp, out)
 }
 
+// JsonRpcCall makes a json rpc call to the configured server, retrying on failures reaching
+// the server. The call is cancelled on Ctrl-C.
 func JsonRpcCall(method string, params interface{}) (interface{}, error) {
-	var res interface{}
-	var err error
-	for i := 0; i < conf.RPCRetries; i++ {
-		res, err = jsonRpcCall(method, params)
-		if err == nil {
-			break
-		}
+	httpClient, err := HTTPClient()
+	if err != nil {
+		return nil, err
 	}
+	ctx, cancel := rpc.InterruptContext(context.Background())
+	defer cancel()
+	var res interface{}
+	err = rpc.Retry(ctx, method, conf.RPCRetries, RPCTimeout(), func(ctx context.Context) error {
+		res = nil
+		return rpc.Call(ctx, httpClient, RPCURL(), AuthHeader(), method, params, &res)
+	})
 	return res, err
 }
 
-func jsonRpcCall(method string, params interface{}) (interface{}, error) {
-	//log.Println("URL IS "+url)
-	jrpc, err := json2.EncodeClientRequest(method, params)
-	if err != nil {
-		log.Warn(err)
-		return nil, err
-	}
-	body := strings.NewReader(string(jrpc))
-	req, err := func() (*http.Request, error) {
-		if strings.HasSuffix(conf.ServerAddr, "5000") { //5000 is http
-			return http.NewRequest("POST", fmt.Sprintf("http://%s/rpc", conf.ServerAddr), body)
-		} else { //5001 is https
-			return http.NewRequest("POST", fmt.Sprintf("https://%s/rpc", conf.ServerAddr), body)
-		}
+// RPCTimeout gets the deadline for each attempt at a json rpc call
+func RPCTimeout() time.Duration {
+	return time.Duration(conf.HTTPTimeout) * time.Millisecond
+}
 
-	}()
-	if err != nil {
-		log.Warn(err)
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
+// RPCURL gets the url of the json rpc endpoint of the configured server
+func RPCURL() string {
+	return ServerBaseURL() + "/rpc"
+}
+
+// AuthHeader gets the headers needed to authenticate with the configured server
+func AuthHeader() http.Header {
+	header := http.Header{}
 	auth, err := CreateAuthNHeader()
 	if err != nil {
 		log.Println(err)
 	} else {
-		req.Header.Set("Authorization", auth) //If there is an error, dont send this header for now
-	}
-
-	req.Close = true
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	defer resp.Body.Close()
-	var out interface{}
-	err = json2.DecodeClientResponse(resp.Body, &out)
-	if err != nil {
-		return nil, err
+		header.Set("Authorization", auth) //If there is an error, dont send this header for now
 	}
-	return out, nil
+	return header
 }
@@ -0,0 +1,257 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// OutputDefault keeps the human friendly output
+	OutputDefault = ""
+	// OutputJSON renders everything as plain indented json
+	OutputJSON = "json"
+	// OutputYAML renders everything as yaml documents
+	OutputYAML = "yaml"
+	// OutputTable renders lists and maps of objects as aligned tables
+	OutputTable = "table"
+)
+
+var outputFormat = OutputDefault
+
+// SetOutputFormat sets the format used by Print for the rest of the execution
+func SetOutputFormat(format string) error {
+	switch strings.ToLower(format) {
+	case OutputDefault, OutputJSON, OutputYAML, OutputTable:
+		outputFormat = strings.ToLower(format)
+		return nil
+	}
+	return fmt.Errorf(`unknown output format "%s", expected one of json, yaml or table`, format)
+}
+
+// OutputFormat gets the output format given with --output
+func OutputFormat() string {
+	return outputFormat
+}
+
+// IsStructuredOutput checks if the output is meant to be consumed by a program
+func IsStructuredOutput() bool {
+	return outputFormat == OutputJSON || outputFormat == OutputYAML
+}
+
+// Colorize wraps s in the given ANSI SGR codes, only if the output is a terminal
+// which is expecting the pretty output.
+func Colorize(s string, codes ...string) string {
+	if !useColor() {
+		return s
+	}
+	return "\033[" + strings.Join(codes, ";") + "m" + s + "\033[0m"
+}
+
+func useColor() bool {
+	_, noPretty := os.LookupEnv("NO_PRETTY")
+	return !noPretty && outputFormat == OutputDefault && IsTTY()
+}
+
+// toGeneric converts i into the generic form encoding/json would give, so that the
+// json tags are respected by all of the renderers.
+func toGeneric(i interface{}) (interface{}, error) {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var out interface{}
+	return out, decoder.Decode(&out)
+}
+
+func renderJSON(w io.Writer, i interface{}) error {
+	out, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
+// MarshalYAML converts i to yaml by way of its json encoding, so that the json field names are used
+func MarshalYAML(i interface{}) ([]byte, error) {
+	generic, err := toGeneric(i)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(yamlFriendly(generic))
+}
+
+func renderYAML(w io.Writer, i interface{}) error {
+	out, err := MarshalYAML(i)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "---\n%s", string(out))
+	return err
+}
+
+// yaml.v2 would quote json.Number as a string
+func yamlFriendly(in interface{}) interface{} {
+	switch val := in.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	case map[string]interface{}:
+		out := yaml.MapSlice{}
+		for _, key := range sortedMapKeys(val) {
+			out = append(out, yaml.MapItem{Key: key, Value: yamlFriendly(val[key])})
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i := range val {
+			out[i] = yamlFriendly(val[i])
+		}
+		return out
+	}
+	return in
+}
+
+func sortedMapKeys(m map[string]interface{}) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func cellString(in interface{}) string {
+	switch val := in.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		return fmt.Sprint(val)
+	}
+	out, _ := json.Marshal(in)
+	return string(out)
+}
+
+// renderTable renders lists of objects and maps of objects as a table, using the given columns
+// when they are given and the union of all of the fields when they are not. Anything else
+// falls back to json.
+func renderTable(w io.Writer, i interface{}, columns []string) error {
+	if str, ok := i.(string); ok {
+		_, err := fmt.Fprintln(w, str)
+		return err
+	}
+	generic, err := toGeneric(i)
+	if err != nil {
+		return err
+	}
+	rows := []map[string]interface{}{}
+	keyColumn := ""
+	switch val := generic.(type) {
+	case []interface{}:
+		for _, elem := range val {
+			row, ok := elem.(map[string]interface{})
+			if !ok {
+				return renderJSON(w, i)
+			}
+			rows = append(rows, row)
+		}
+	case map[string]interface{}:
+		keyColumn = "name"
+		for _, key := range sortedMapKeys(val) {
+			row, ok := val[key].(map[string]interface{})
+			if !ok { //a flat object, show it as key value pairs
+				return renderKeyValueTable(w, val)
+			}
+			withKey := map[string]interface{}{keyColumn: key}
+			for k, v := range row {
+				if k == keyColumn {
+					k = "_" + k
+				}
+				withKey[k] = v
+			}
+			rows = append(rows, withKey)
+		}
+	default:
+		return renderJSON(w, i)
+	}
+
+	if len(columns) == 0 {
+		seen := map[string]bool{}
+		for _, row := range rows {
+			for _, key := range sortedMapKeys(row) {
+				if !seen[key] && key != keyColumn {
+					seen[key] = true
+					columns = append(columns, key)
+				}
+			}
+		}
+		sort.Strings(columns)
+		if len(keyColumn) > 0 {
+			columns = append([]string{keyColumn}, columns...)
+		}
+	} else if len(keyColumn) > 0 {
+		columns = append([]string{keyColumn}, columns...)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = strings.ToUpper(column)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = cellString(row[column])
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func renderKeyValueTable(w io.Writer, obj map[string]interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE")
+	for _, key := range sortedMapKeys(obj) {
+		fmt.Fprintf(tw, "%s\t%s\n", key, cellString(obj[key]))
+	}
+	return tw.Flush()
+}
+
+// Render writes i to w in the current output format. The columns are only used by the
+// table format, and may be left empty to use every field.
+func Render(w io.Writer, i interface{}, columns ...string) error {
+	switch outputFormat {
+	case OutputJSON:
+		return renderJSON(w, i)
+	case OutputYAML:
+		return renderYAML(w, i)
+	case OutputTable:
+		return renderTable(w, i, columns)
+	}
+	switch val := i.(type) {
+	case string:
+		_, err := fmt.Fprintln(w, Colorize(val, "97"))
+		return err
+	}
+	_, err := fmt.Fprintln(w, Prettypi(i))
+	return err
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+func TestRenderTable(t *testing.T) {
+	var tests = []struct {
+		i        interface{}
+		columns  []string
+		expected string
+	}{
+		{
+			i: []map[string]interface{}{
+				{"id": "a", "absNum": 0, "ports": map[string]string{"8545": "8545"}},
+				{"id": "bb", "absNum": 1},
+			},
+			columns:  []string{"absNum", "id", "ports"},
+			expected: "ABSNUM  ID  PORTS\n0       a   {\"8545\":\"8545\"}\n1       bb  \n",
+		},
+		{
+			i: map[string]interface{}{
+				"server1": map[string]interface{}{"addr": "10.0.0.1", "nodes": 2},
+			},
+			expected: "NAME     ADDR      NODES\nserver1  10.0.0.1  2\n",
+		},
+		{
+			i:        map[string]interface{}{"limit": 1000, "loss": 0.5},
+			expected: "KEY    VALUE\nlimit  1000\nloss   0.5\n",
+		},
+		{
+			i:        []string{"a"},
+			expected: "[\n  \"a\"\n]\n",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var buf bytes.Buffer
+			err := renderTable(&buf, tt.i, tt.columns)
+			if err != nil {
+				t.Error("error running renderTable", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("return value of renderTable does not match expected value:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func TestRenderYAML(t *testing.T) {
+	var buf bytes.Buffer
+	err := renderYAML(&buf, map[string]interface{}{"nodes": 3, "blockchain": "geth", "ratio": 0.5})
+	if err != nil {
+		t.Error("error running renderYAML", err)
+	}
+	expected := "---\nblockchain: geth\nnodes: 3\nratio: 0.5\n"
+	if buf.String() != expected {
+		t.Errorf("return value of renderYAML does not match expected value:\n%s", buf.String())
+	}
+}
+
+func TestSetOutputFormat(t *testing.T) {
+	defer SetOutputFormat(OutputDefault)
+	if err := SetOutputFormat("JSON"); err != nil || OutputFormat() != OutputJSON {
+		t.Error("expected JSON to be accepted as json")
+	}
+	if err := SetOutputFormat("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
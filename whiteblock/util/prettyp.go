@@ -9,8 +9,7 @@ import (
 )
 
 func Prettyp(s string) string {
-	_, noPretty := os.LookupEnv("NO_PRETTY")
-	if noPretty {
+	if !useColor() {
 		return s
 	}
 	s = strings.Trim(s, "\n\t\r\v ")
@@ -32,24 +31,24 @@ func Prettypi(i interface{}) string {
 		out, _ := json.Marshal(i)
 		return string(out)
 	}
+	if !useColor() {
+		out, _ := json.MarshalIndent(i, "", "  ")
+		return string(out)
+	}
 	out, _ := prettyjson.Marshal(i)
 	return string(out)
 }
 
-func Print(i interface{}) {
-	switch i.(type) {
-	case string:
-		_, noPretty := os.LookupEnv("NO_PRETTY")
-		if noPretty {
-			fmt.Println(i.(string))
-		} else {
-			fmt.Printf("\033[97m%s\033[0m\n", i.(string))
-		}
-	default:
-		fmt.Println(Prettypi(i))
-	}
+// Print prints i to stdout in the format given with --output
+func Print(i interface{}) error {
+	return PrintTable(i)
+}
+
+// PrintTable prints i like Print, using the given columns when the output is a table
+func PrintTable(i interface{}, columns ...string) error {
+	return Render(os.Stdout, i, columns...)
 }
 
-func Printf(format string, a ...interface{}) {
-	Print(fmt.Sprintf(format, a...))
+func Printf(format string, a ...interface{}) error {
+	return Print(fmt.Sprintf(format, a...))
 }
@@ -0,0 +1,314 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/pkg/sftp"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// SFTP opens an sftp session on the node, which must be closed when done with
+func (this SshClient) SFTP() (*sftp.Client, error) {
+	var err error
+	for _, client := range this.clients {
+		var out *sftp.Client
+		out, err = sftp.NewClient(client)
+		if err == nil {
+			return out, nil
+		}
+	}
+	if err == nil {
+		err = errors.New("Unable to get a session")
+	}
+	return nil, fmt.Errorf("could not start sftp: %v", err)
+}
+
+// Upload copies the file or directory at source on this machine to destination on the node.
+// As with scp -r, it is copied into destination if destination is a directory.
+func (this SshClient) Upload(source string, destination string) error {
+	client, err := this.SFTP()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return UploadPath(client, source, destination)
+}
+
+// Download copies the file or directory at source on the node to destination on this machine.
+// As with scp -r, it is copied into destination if destination is a directory.
+func (this SshClient) Download(source string, destination string) error {
+	client, err := this.SFTP()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return DownloadPath(client, source, destination)
+}
+
+// UploadPath copies source on this machine to destination through client, see Upload
+func UploadPath(client *sftp.Client, source string, destination string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if dest, err := client.Stat(destination); err == nil && dest.IsDir() {
+		destination = path.Join(destination, filepath.Base(source))
+	}
+	if !info.IsDir() {
+		return uploadFile(client, source, destination, info.Mode())
+	}
+	return filepath.Walk(source, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source, name)
+		if err != nil {
+			return err
+		}
+		target := path.Join(destination, filepath.ToSlash(rel))
+		if info.IsDir() {
+			err = client.MkdirAll(target)
+			if err != nil {
+				return fmt.Errorf("could not create %s on the node: %v", target, err)
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return uploadFile(client, name, target, info.Mode())
+	})
+}
+
+func uploadFile(client *sftp.Client, source string, destination string, mode os.FileMode) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := client.Create(destination)
+	if err != nil {
+		return fmt.Errorf("could not create %s on the node: %v", destination, err)
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return fmt.Errorf("could not copy %s to the node: %v", source, err)
+	}
+	return client.Chmod(destination, mode.Perm())
+}
+
+// DownloadPath copies source on the node to destination on this machine through client, see
+// Download
+func DownloadPath(client *sftp.Client, source string, destination string) error {
+	source = path.Clean(source)
+	info, err := client.Stat(source)
+	if err != nil {
+		return fmt.Errorf("%s on the node: %v", source, err)
+	}
+	if dest, err := os.Stat(destination); err == nil && dest.IsDir() {
+		destination = filepath.Join(destination, path.Base(source))
+	}
+	if !info.IsDir() {
+		return downloadFile(client, source, destination, info.Mode())
+	}
+	walker := client.Walk(source)
+	for walker.Step() {
+		if walker.Err() != nil {
+			return walker.Err()
+		}
+		rel := walker.Path()[len(source):]
+		target := filepath.Join(destination, filepath.FromSlash(rel))
+		if walker.Stat().IsDir() {
+			err = os.MkdirAll(target, walker.Stat().Mode().Perm()|0700)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !walker.Stat().Mode().IsRegular() {
+			continue
+		}
+		err = downloadFile(client, walker.Path(), target, walker.Stat().Mode())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downloadFile(client *sftp.Client, source string, destination string, mode os.FileMode) error {
+	in, err := client.Open(source)
+	if err != nil {
+		return fmt.Errorf("could not open %s on the node: %v", source, err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return fmt.Errorf("could not copy %s from the node: %v", source, err)
+	}
+	return nil
+}
+
+// PullStats counts what was copied by PullPath
+type PullStats struct {
+	Files int
+	// Unchanged is the number of files which had already been copied, and were not copied again
+	Unchanged int
+	Bytes     int64
+}
+
+// partMeta is the size and modification time of the file on the node which a partial copy is
+// of, kept next to it in destination.part.meta
+type partMeta struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// resumable checks if the partial copy with the metadata at path is of the file with the given info
+func resumable(path string, info os.FileInfo) bool {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var meta partMeta
+	err = json.Unmarshal(data, &meta)
+	return err == nil && meta.Size == info.Size() && meta.ModTime.Equal(info.ModTime())
+}
+
+// PullFile copies the file at source on the node to destination, resuming from where an earlier
+// copy left off. The copy is kept in destination.part until it is complete, and is only resumed
+// if the file on the node has the same size and modification time as when it was started. A file
+// which has already been copied, with the same size and modification time, is not copied again.
+// It gives the number of bytes copied, and whether the file had already been copied.
+func PullFile(client *sftp.Client, source string, destination string) (int64, bool, error) {
+	info, err := client.Stat(source)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s on the node: %v", source, err)
+	}
+	local, err := os.Stat(destination)
+	if err == nil && local.Size() == info.Size() && local.ModTime().Equal(info.ModTime()) {
+		return 0, true, nil
+	}
+	err = os.MkdirAll(filepath.Dir(destination), 0755)
+	if err != nil {
+		return 0, false, err
+	}
+
+	part := destination + ".part"
+	metaPath := part + ".meta"
+	flags := os.O_WRONLY | os.O_CREATE
+	if !resumable(metaPath, info) {
+		flags |= os.O_TRUNC
+		meta, err := json.Marshal(partMeta{Size: info.Size(), ModTime: info.ModTime()})
+		if err != nil {
+			return 0, false, err
+		}
+		err = ioutil.WriteFile(metaPath, meta, 0644)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+	out, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return 0, false, err
+	}
+	defer out.Close()
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false, err
+	}
+	if offset > info.Size() {
+		// the file on the node is not the one which was being copied
+		offset = 0
+		err = out.Truncate(0)
+		if err != nil {
+			return 0, false, err
+		}
+		_, err = out.Seek(0, io.SeekStart)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+	in, err := client.Open(source)
+	if err != nil {
+		return 0, false, fmt.Errorf("could not open %s on the node: %v", source, err)
+	}
+	defer in.Close()
+	_, err = in.Seek(offset, io.SeekStart)
+	if err != nil {
+		return 0, false, err
+	}
+	n, err := io.Copy(out, in)
+	if err != nil {
+		return n, false, fmt.Errorf("could not copy %s from the node: %v", source, err)
+	}
+	err = out.Close()
+	if err != nil {
+		return n, false, err
+	}
+	os.Chmod(part, info.Mode().Perm())
+	err = os.Chtimes(part, info.ModTime(), info.ModTime())
+	if err != nil {
+		return n, false, err
+	}
+	err = os.Rename(part, destination)
+	if err != nil {
+		return n, false, err
+	}
+	return n, false, os.Remove(metaPath)
+}
+
+// PullPath copies the file or directory at source on the node to destination with PullFile,
+// so that copying it again only copies what has not been copied yet. Unlike DownloadPath, it
+// is always copied to destination itself.
+func PullPath(client *sftp.Client, source string, destination string) (PullStats, error) {
+	out := PullStats{}
+	pull := func(source string, destination string) error {
+		n, unchanged, err := PullFile(client, source, destination)
+		out.Bytes += n
+		if err != nil {
+			return err
+		}
+		out.Files++
+		if unchanged {
+			out.Unchanged++
+		}
+		return nil
+	}
+	source = path.Clean(source)
+	info, err := client.Stat(source)
+	if err != nil {
+		return out, fmt.Errorf("%s on the node: %v", source, err)
+	}
+	if !info.IsDir() {
+		return out, pull(source, destination)
+	}
+	walker := client.Walk(source)
+	for walker.Step() {
+		if walker.Err() != nil {
+			return out, walker.Err()
+		}
+		target := filepath.Join(destination, filepath.FromSlash(walker.Path()[len(source):]))
+		if walker.Stat().IsDir() {
+			err = os.MkdirAll(target, walker.Stat().Mode().Perm()|0700)
+		} else if walker.Stat().Mode().IsRegular() {
+			err = pull(walker.Path(), target)
+		}
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
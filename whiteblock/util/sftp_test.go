@@ -0,0 +1,251 @@
+package util
+
+import (
+	"encoding/json"
+	"github.com/pkg/sftp"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type pipeConn struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// memSFTP gets a client of an sftp server which keeps its files in memory, and a function to
+// stop them both
+func memSFTP(t *testing.T) (*sftp.Client, func()) {
+	serverRead, clientWrite := io.Pipe()
+	clientRead, serverWrite := io.Pipe()
+	server := sftp.NewRequestServer(pipeConn{serverRead, serverWrite}, sftp.InMemHandler())
+	go server.Serve()
+	client, err := sftp.NewClientPipe(clientRead, clientWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, func() {
+		server.Close()
+		client.Close()
+	}
+}
+
+// readTree gets the contents of each file under dir, by its path from dir
+func readTree(t *testing.T, dir string) map[string]string {
+	out := map[string]string{}
+	err := filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, name)
+		out[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestCopyPath(t *testing.T) {
+	files := map[string]string{
+		"genesis.json":      `{"difficulty":"0x100"}`,
+		"keys/node0.key":    "abc",
+		"keys/deep/a/b.txt": "nested",
+	}
+	var tests = []struct {
+		source      string
+		destination string
+		mkdir       string
+		expected    map[string]string
+	}{
+		{source: "genesis.json", destination: "/genesis.json", expected: map[string]string{"genesis.json": files["genesis.json"]}},
+		{source: "keys", destination: "/copy", expected: map[string]string{
+			"copy/node0.key": "abc", "copy/deep/a/b.txt": "nested"}},
+		{source: "keys", destination: "/into", mkdir: "/into", expected: map[string]string{
+			"into/keys/node0.key": "abc", "into/keys/deep/a/b.txt": "nested"}},
+		{source: "keys/deep/a/b.txt", destination: "/out", mkdir: "/out", expected: map[string]string{"out/b.txt": "nested"}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			local, err := ioutil.TempDir("", "upload")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(local)
+			for name, data := range files {
+				name = filepath.Join(local, filepath.FromSlash(name))
+				os.MkdirAll(filepath.Dir(name), 0755)
+				if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			client, stop := memSFTP(t)
+			defer stop()
+			if len(tt.mkdir) > 0 {
+				if err := client.MkdirAll(tt.mkdir); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err = UploadPath(client, filepath.Join(local, filepath.FromSlash(tt.source)), tt.destination)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			back, err := ioutil.TempDir("", "download")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(back)
+			err = DownloadPath(client, "/", back)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := readTree(t, back)
+			if !reflect.DeepEqual(out, tt.expected) {
+				t.Errorf("the files copied there and back do not match the expected files: %v", out)
+			}
+		})
+	}
+}
+
+func TestDownloadPathMissing(t *testing.T) {
+	client, stop := memSFTP(t)
+	defer stop()
+	dir, err := ioutil.TempDir("", "download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if DownloadPath(client, "/nothing", dir) == nil {
+		t.Error("expected an error for a file which does not exist")
+	}
+}
+
+func TestPullFile(t *testing.T) {
+	const data = "0123456789abcdefghij"
+	var tests = []struct {
+		part     string
+		meta     bool
+		stale    bool
+		existing bool
+		copied   int64
+	}{
+		{copied: 20},
+		{part: "0123456789", meta: true, copied: 10},
+		{part: "0123456789", copied: 20},
+		{part: "ABCDEFGHIJ", meta: true, stale: true, copied: 20},
+		{part: "0123456789abcdefghijklmnop", meta: true, copied: 20},
+		{existing: true, copied: 0},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			client, stop := memSFTP(t)
+			defer stop()
+			file, err := client.Create("/data.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			file.Write([]byte(data))
+			file.Close()
+
+			dir, err := ioutil.TempDir("", "pull")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			destination := filepath.Join(dir, "data", "data.txt")
+			if tt.existing {
+				_, _, err = PullFile(client, "/data.txt", destination)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(tt.part) > 0 {
+				os.MkdirAll(filepath.Dir(destination), 0755)
+				ioutil.WriteFile(destination+".part", []byte(tt.part), 0644)
+			}
+			if tt.meta {
+				info, err := client.Stat("/data.txt")
+				if err != nil {
+					t.Fatal(err)
+				}
+				meta := partMeta{Size: info.Size(), ModTime: info.ModTime()}
+				if tt.stale {
+					meta.ModTime = meta.ModTime.Add(-time.Hour)
+				}
+				data, _ := json.Marshal(meta)
+				ioutil.WriteFile(destination+".part.meta", data, 0644)
+			}
+
+			n, unchanged, err := PullFile(client, "/data.txt", destination)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tt.copied || unchanged != tt.existing {
+				t.Errorf("expected %d bytes copied and unchanged to be %v, got %d and %v", tt.copied, tt.existing, n, unchanged)
+			}
+			out, err := ioutil.ReadFile(destination)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != data {
+				t.Errorf("the file pulled does not match the file on the node: %s", out)
+			}
+			for _, name := range []string{destination + ".part", destination + ".part.meta"} {
+				if _, err := os.Stat(name); !os.IsNotExist(err) {
+					t.Errorf("expected %s to be gone", name)
+				}
+			}
+		})
+	}
+}
+
+func TestPullPath(t *testing.T) {
+	client, stop := memSFTP(t)
+	defer stop()
+	client.MkdirAll("/geth/keys")
+	for name, data := range map[string]string{"/geth/genesis.json": "{}", "/geth/keys/a.key": "abc"} {
+		file, err := client.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Write([]byte(data))
+		file.Close()
+	}
+	dir, err := ioutil.TempDir("", "pull")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stats, err := PullPath(client, "/geth", filepath.Join(dir, "geth"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats != (PullStats{Files: 2, Bytes: 5}) {
+		t.Errorf("unexpected stats for the first pull: %+v", stats)
+	}
+	expected := map[string]string{"geth/genesis.json": "{}", "geth/keys/a.key": "abc"}
+	if out := readTree(t, dir); !reflect.DeepEqual(out, expected) {
+		t.Errorf("the files pulled do not match the files on the node: %v", out)
+	}
+	stats, err = PullPath(client, "/geth", filepath.Join(dir, "geth"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats != (PullStats{Files: 2, Unchanged: 2}) {
+		t.Errorf("expected nothing to be copied again, got %+v", stats)
+	}
+}
@@ -1,13 +1,24 @@
 package util
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+	"io"
 	"io/ioutil"
-	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// sshTimeout is how long to wait to connect to a node
+const sshTimeout = 10 * time.Second
+
 type SshClient struct {
 	clients []*ssh.Client
 }
@@ -65,6 +76,139 @@ func (this SshClient) Run(command string) (string, error) {
 	return string(out), err
 }
 
+// RunContext runs a command, giving its stdout, stderr and exit code. The command is killed if
+// ctx is done before it exits.
+func (this SshClient) RunContext(ctx context.Context, command string) (string, string, int, error) {
+	session, err := this.GetSession()
+	if err != nil {
+		return "", "", -1, err
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	err = session.Start(command)
+	if err != nil {
+		return "", "", -1, err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		session.Signal(ssh.SIGKILL)
+		session.Close()
+		<-done
+		return stdout.String(), stderr.String(), -1, ctx.Err()
+	}
+	if exitErr, ok := err.(*ssh.ExitError); ok {
+		return stdout.String(), stderr.String(), exitErr.ExitStatus(), nil
+	}
+	if err != nil {
+		return stdout.String(), stderr.String(), -1, err
+	}
+	return stdout.String(), stderr.String(), 0, nil
+}
+
+// Interactive runs a command attached to this terminal, or a login shell if command is empty.
+// A pty is given to the command when stdin is a terminal, and is kept the size of the terminal.
+// A RemoteExitError is given if the command exits with a status other than 0.
+func (this SshClient) Interactive(command string) error {
+	session, err := this.GetSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	// stdin is copied outside of the session, so that waiting on the session does not wait for
+	// the next read from the terminal once the command has exited
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	go func() {
+		io.Copy(stdin, os.Stdin)
+		stdin.Close()
+	}()
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+		termType := os.Getenv("TERM")
+		if len(termType) == 0 {
+			termType = "xterm"
+		}
+		err = session.RequestPty(termType, height, width, ssh.TerminalModes{
+			ssh.ECHO:          1,
+			ssh.TTY_OP_ISPEED: 14400,
+			ssh.TTY_OP_OSPEED: 14400,
+		})
+		if err != nil {
+			return err
+		}
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer term.Restore(fd, state)
+		stop := followWindowSize(fd, session)
+		defer stop()
+	}
+
+	if len(command) == 0 {
+		err = session.Shell()
+	} else {
+		err = session.Start(command)
+	}
+	if err != nil {
+		return err
+	}
+	return remoteExit(session.Wait())
+}
+
+// followWindowSize resizes the pty of the session whenever the terminal is resized, until stop
+// is called
+func followWindowSize(fd int, session *ssh.Session) (stop func()) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGWINCH)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-sigChan:
+				width, height, err := term.GetSize(fd)
+				if err == nil {
+					session.WindowChange(height, width)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
+
+// remoteExit gets the error for the result of a remote command
+func remoteExit(err error) error {
+	switch e := err.(type) {
+	case *ssh.ExitError:
+		return RemoteExitError{Code: e.ExitStatus()}
+	case *ssh.ExitMissingError:
+		return errors.New("the connection to the node closed before the command exited")
+	}
+	return err
+}
+
 func (this SshClient) Close() {
 	for _, client := range this.clients {
 		if client == nil {
@@ -77,11 +221,11 @@ func (this SshClient) Close() {
 func sshConnect(host string) (*ssh.Client, error) {
 	key, err := ioutil.ReadFile(conf.SSHPrivateKey)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("could not read the ssh key: %v", err)
 	}
 	signer, err := ssh.ParsePrivateKey(key)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("could not parse the ssh key %s: %v", conf.SSHPrivateKey, err)
 	}
 	sshConfig := &ssh.ClientConfig{
 		User: "root",
@@ -89,11 +233,12 @@ func sshConnect(host string) (*ssh.Client, error) {
 			// Use the PublicKeys method for remote authentication.
 			ssh.PublicKeys(signer),
 		},
+		Timeout: sshTimeout,
 	}
 	sshConfig.HostKeyCallback = ssh.InsecureIgnoreHostKey()
 	client, err := ssh.Dial("tcp", fmt.Sprintf("%s:22", host), sshConfig)
 	if err != nil {
-		fmt.Println("First ssh attempt failed: " + err.Error())
+		log.WithFields(log.Fields{"host": host, "error": err}).Warn("first ssh attempt failed")
 	}
 
 	return client, err
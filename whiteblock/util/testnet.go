@@ -0,0 +1,157 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const testnetsKey = "testnets"
+
+// selectedTestnet is the testnet given with --testnet, which takes the place of
+// the previous build for this execution
+var selectedTestnet string
+
+// Testnet is a testnet which has been built from this machine, kept in the
+// registry so that it can be referred to by name
+type Testnet struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Blockchain string            `json:"blockchain,omitempty"`
+	Nodes      int               `json:"nodes,omitempty"`
+	Created    time.Time         `json:"created"`
+}
+
+// SelectTestnet chooses the testnet to use for this execution, by id, name or
+// a unique prefix of the id
+func SelectTestnet(ref string) {
+	selectedTestnet = ref
+}
+
+// SelectedTestnet gets the testnet given with --testnet, if any
+func SelectedTestnet() string {
+	return selectedTestnet
+}
+
+// GetTestnets gets the registry of testnets, by id
+func GetTestnets() (map[string]Testnet, error) {
+	out := map[string]Testnet{}
+	if !Exists(testnetsKey) {
+		return out, nil
+	}
+	return out, GetP(testnetsKey, &out)
+}
+
+// ListTestnets gets the testnets in the registry, oldest first
+func ListTestnets() ([]Testnet, error) {
+	testnets, err := GetTestnets()
+	if err != nil {
+		return nil, err
+	}
+	out := []Testnet{}
+	for _, testnet := range testnets {
+		out = append(out, testnet)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Created.Equal(out[j].Created) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].Created.Before(out[j].Created)
+	})
+	return out, nil
+}
+
+// findTestnet finds the testnet referred to by ref, which is either its id, its name
+// or a prefix of its id which matches no other testnet
+func findTestnet(testnets map[string]Testnet, ref string) (Testnet, error) {
+	if testnet, ok := testnets[ref]; ok {
+		return testnet, nil
+	}
+	for _, testnet := range testnets {
+		if len(testnet.Name) > 0 && testnet.Name == ref {
+			return testnet, nil
+		}
+	}
+	matches := []Testnet{}
+	for id, testnet := range testnets {
+		if len(ref) > 0 && strings.HasPrefix(id, ref) {
+			matches = append(matches, testnet)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Testnet{}, NewValidationError(`testnet "%s" does not exist, see "whiteblock testnet list"`, ref)
+	case 1:
+		return matches[0], nil
+	}
+	return Testnet{}, NewValidationError(`"%s" matches %d testnets, give more of the id`, ref, len(matches))
+}
+
+// FindTestnet finds the testnet in the registry with the given id, name or id prefix
+func FindTestnet(ref string) (Testnet, error) {
+	testnets, err := GetTestnets()
+	if err != nil {
+		return Testnet{}, err
+	}
+	return findTestnet(testnets, ref)
+}
+
+// checkTestnetName checks that the name can be given to the testnet with the given id
+func checkTestnetName(testnets map[string]Testnet, name string, id string) error {
+	if len(name) == 0 {
+		return nil
+	}
+	if strings.ContainsAny(name, " \t\n/") {
+		return NewValidationError(`invalid testnet name "%s"`, name)
+	}
+	for _, testnet := range testnets {
+		if testnet.Name == name && testnet.ID != id {
+			return NewValidationError(`the name "%s" is already used by testnet %s`, name, testnet.ID)
+		}
+	}
+	return nil
+}
+
+// CheckTestnetName checks that the name is not in use by another testnet
+func CheckTestnetName(name string, id string) error {
+	testnets, err := GetTestnets()
+	if err != nil {
+		return err
+	}
+	return checkTestnetName(testnets, name, id)
+}
+
+// SaveTestnet adds or updates a testnet in the registry
+func SaveTestnet(testnet Testnet) error {
+	if len(testnet.ID) == 0 {
+		return fmt.Errorf("cannot save a testnet without an id")
+	}
+	testnets, err := GetTestnets()
+	if err != nil {
+		return err
+	}
+	err = checkTestnetName(testnets, testnet.Name, testnet.ID)
+	if err != nil {
+		return err
+	}
+	if testnet.Created.IsZero() {
+		testnet.Created = time.Now()
+	}
+	testnets[testnet.ID] = testnet
+	return Set(testnetsKey, testnets)
+}
+
+// RemoveTestnet removes a testnet from the registry
+func RemoveTestnet(id string) error {
+	testnets, err := GetTestnets()
+	if err != nil {
+		return err
+	}
+	if _, ok := testnets[id]; !ok {
+		return nil
+	}
+	delete(testnets, id)
+	return Set(testnetsKey, testnets)
+}
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestFindTestnet(t *testing.T) {
+	testnets := map[string]Testnet{
+		"4f2c1a": Testnet{ID: "4f2c1a", Name: "geth-small"},
+		"4f2d9b": Testnet{ID: "4f2d9b"},
+		"a01b3c": Testnet{ID: "a01b3c", Name: "4f2d"},
+	}
+	var tests = []struct {
+		ref      string
+		expected string
+		err      bool
+	}{
+		{ref: "4f2c1a", expected: "4f2c1a"},
+		{ref: "geth-small", expected: "4f2c1a"},
+		{ref: "4f2c", expected: "4f2c1a"},
+		{ref: "4f2d", expected: "a01b3c"},
+		{ref: "4f2", err: true},
+		{ref: "missing", err: true},
+		{ref: "", err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			testnet, err := findTestnet(testnets, tt.ref)
+			if tt.err {
+				if ExitCode(err) != ExitValidation {
+					t.Errorf("expected a validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Error(err)
+			}
+			if testnet.ID != tt.expected {
+				t.Errorf("return value of findTestnet does not match expected value: %s", testnet.ID)
+			}
+		})
+	}
+}
+
+func TestCheckTestnetName(t *testing.T) {
+	testnets := map[string]Testnet{
+		"4f2c1a": Testnet{ID: "4f2c1a", Name: "geth-small"},
+	}
+	var tests = []struct {
+		name string
+		id   string
+		err  bool
+	}{
+		{name: "", id: ""},
+		{name: "geth-large", id: ""},
+		{name: "geth-small", id: "4f2c1a"},
+		{name: "geth-small", id: "", err: true},
+		{name: "geth small", id: "", err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := checkTestnetName(testnets, tt.name, tt.id)
+			if (err != nil) != tt.err {
+				t.Errorf("unexpected result from checkTestnetName: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var (
+	httpClient    *http.Client
+	httpClientErr error
+	httpClientMux sync.Mutex
+)
+
+// ServerScheme gets the scheme of the server, http or https. It is taken from serverURL or
+// scheme in the config, and otherwise guessed from the port, as 5000 is http and 5001 is https.
+func ServerScheme() string {
+	if len(conf.ServerURL) > 0 {
+		u, err := url.Parse(conf.ServerURL)
+		if err == nil && len(u.Scheme) > 0 {
+			return strings.ToLower(u.Scheme)
+		}
+	}
+	if len(conf.Scheme) > 0 {
+		return strings.ToLower(conf.Scheme)
+	}
+	log.WithFields(log.Fields{"serverAddr": conf.ServerAddr}).Debug("no scheme given, guessing it from the port")
+	if strings.HasSuffix(conf.ServerAddr, "5000") {
+		return "http"
+	}
+	return "https"
+}
+
+// ServerBaseURL gets the url of the server, without a trailing slash
+func ServerBaseURL() string {
+	if len(conf.ServerURL) > 0 {
+		return strings.TrimSuffix(conf.ServerURL, "/")
+	}
+	return fmt.Sprintf("%s://%s", ServerScheme(), conf.ServerAddr)
+}
+
+// SocketURL gets the url of the socket.io endpoint of the server
+func SocketURL() string {
+	base := ServerBaseURL()
+	if strings.HasPrefix(base, "https://") {
+		base = "wss://" + strings.TrimPrefix(base, "https://")
+	} else {
+		base = "ws://" + strings.TrimPrefix(base, "http://")
+	}
+	return base + "/socket.io/?EIO=3&transport=websocket"
+}
+
+// TLSConfig creates the tls config for connections to the server and the api, from the ca
+// bundle, client certificate and insecure-skip-verify settings. The ca bundle is trusted along
+// with the system roots.
+func TLSConfig() (*tls.Config, error) {
+	out := &tls.Config{InsecureSkipVerify: conf.InsecureSkipVerify}
+	if conf.InsecureSkipVerify {
+		log.Warn("the certificate of the server is not being verified")
+	}
+	if len(conf.CACert) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := ioutil.ReadFile(conf.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the ca bundle: %v", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in the ca bundle %s", conf.CACert)
+		}
+		out.RootCAs = pool
+	}
+	if len(conf.ClientCert) > 0 || len(conf.ClientKey) > 0 {
+		if len(conf.ClientCert) == 0 || len(conf.ClientKey) == 0 {
+			return nil, NewValidationError("both clientCert and clientKey must be given for client certificate authentication")
+		}
+		cert, err := tls.LoadX509KeyPair(conf.ClientCert, conf.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %v", err)
+		}
+		out.Certificates = []tls.Certificate{cert}
+	}
+	return out, nil
+}
+
+// HTTPClient gets the http client to reach the server and the api with, which uses the
+// tls settings from the config. The client has no timeout, the requests should be given
+// a deadline instead.
+func HTTPClient() (*http.Client, error) {
+	httpClientMux.Lock()
+	defer httpClientMux.Unlock()
+	if httpClient != nil || httpClientErr != nil {
+		return httpClient, httpClientErr
+	}
+	tlsConf, err := TLSConfig()
+	if err != nil {
+		httpClientErr = err
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConf
+	httpClient = &http.Client{Transport: transport}
+	return httpClient, nil
+}
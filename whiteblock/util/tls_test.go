@@ -0,0 +1,52 @@
+package util
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestServerURLs(t *testing.T) {
+	var tests = []struct {
+		serverURL  string
+		scheme     string
+		serverAddr string
+		expected   string
+		socket     string
+	}{
+		{serverAddr: "127.0.0.1:5000", expected: "http://127.0.0.1:5000", socket: "ws://127.0.0.1:5000/socket.io/?EIO=3&transport=websocket"},
+		{serverAddr: "127.0.0.1:5001", expected: "https://127.0.0.1:5001", socket: "wss://127.0.0.1:5001/socket.io/?EIO=3&transport=websocket"},
+		{scheme: "http", serverAddr: "10.0.0.5:8080", expected: "http://10.0.0.5:8080", socket: "ws://10.0.0.5:8080/socket.io/?EIO=3&transport=websocket"},
+		{scheme: "HTTPS", serverAddr: "10.0.0.5:5000", expected: "https://10.0.0.5:5000", socket: "wss://10.0.0.5:5000/socket.io/?EIO=3&transport=websocket"},
+		{serverURL: "https://genesis.lab/", scheme: "http", serverAddr: "127.0.0.1:5000", expected: "https://genesis.lab", socket: "wss://genesis.lab/socket.io/?EIO=3&transport=websocket"},
+	}
+
+	saved := *conf
+	defer func() { *conf = saved }()
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			conf.ServerURL = tt.serverURL
+			conf.Scheme = tt.scheme
+			conf.ServerAddr = tt.serverAddr
+			if ServerBaseURL() != tt.expected {
+				t.Errorf("return value of ServerBaseURL does not match expected value: %s", ServerBaseURL())
+			}
+			if SocketURL() != tt.socket {
+				t.Errorf("return value of SocketURL does not match expected value: %s", SocketURL())
+			}
+			if RPCURL() != tt.expected+"/rpc" {
+				t.Errorf("return value of RPCURL does not match expected value: %s", RPCURL())
+			}
+		})
+	}
+}
+
+func TestTLSConfigClientCertPair(t *testing.T) {
+	saved := *conf
+	defer func() { *conf = saved }()
+	conf.ClientCert = "client.pem"
+	conf.ClientKey = ""
+	_, err := TLSConfig()
+	if ExitCode(err) != ExitValidation {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
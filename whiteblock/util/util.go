@@ -2,8 +2,10 @@ package util
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/whiteblock/cli/whiteblock/rpc"
 	"golang.org/x/crypto/ssh/terminal"
 	"io/ioutil"
 	"net/http"
@@ -26,20 +28,20 @@ func PartialCommand(cmd *cobra.Command, args []string) {
 	return
 }
 
-func CheckAndConvertInt(num string, name string) int {
+func CheckAndConvertInt(num string, name string) (int, error) {
 	out, err := strconv.ParseInt(num, 0, 32)
 	if err != nil {
-		InvalidInteger(name, num, true)
+		return 0, InvalidInteger(name, num)
 	}
-	return int(out)
+	return int(out), nil
 }
 
-func CheckAndConvertInt64(num string, name string) int64 {
+func CheckAndConvertInt64(num string, name string) (int64, error) {
 	out, err := strconv.ParseInt(num, 0, 64)
 	if err != nil {
-		InvalidInteger(name, num, true)
+		return 0, InvalidInteger(name, num)
 	}
-	return out
+	return out, nil
 }
 
 /*
@@ -64,24 +66,39 @@ func HttpRequest(method string, url string, bodyData string) ([]byte, error) {
 	//req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Host", conf.APIURL)
 	req.Close = true
-	resp, err := http.DefaultClient.Do(req)
+	client, err := HTTPClient()
 	if err != nil {
 		return nil, err
 	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, TransportError{URL: url, Err: err}
+	}
 
 	defer resp.Body.Close()
 	buf := new(bytes.Buffer)
 
 	_, err = buf.ReadFrom(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, TransportError{URL: url, StatusCode: resp.StatusCode, Err: err}
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf(buf.String())
+		return nil, httpStatusError(url, resp.StatusCode, buf.String())
 	}
 	return buf.Bytes(), nil
 }
 
+// httpStatusError categorizes an unsuccessful response
+func httpStatusError(url string, status int, body string) error {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return AuthError{Err: fmt.Errorf("%s", body)}
+	case status >= 500:
+		return TransportError{URL: url, StatusCode: status, Err: fmt.Errorf("%s", body)}
+	}
+	return fmt.Errorf("%s", body)
+}
+
 func CreateAuthNHeader() (string, error) {
 	if Exists("jwt") {
 		var jwt string
@@ -94,16 +111,18 @@ func CreateAuthNHeader() (string, error) {
 }
 
 // JwtHTTPRequest is similar to HttpRequest, but it have the content-type set as application/json and it will
-// put the given jwt in the auth header
+// put the given jwt in the auth header. Only GET and HEAD requests are retried.
 func JwtHTTPRequest(method string, url string, bodyData string) (string, error) {
+	attempts := 1
+	if method == http.MethodGet || method == http.MethodHead {
+		attempts = conf.HTTPRetries
+	}
 	var res string
-	var err error
-	for i := 0; i < conf.HTTPRetries; i++ {
+	err := rpc.Retry(context.Background(), method+" "+url, attempts, 0, func(ctx context.Context) error {
+		var err error
 		res, err = jwtHTTPRequest(method, url, bodyData)
-		if err == nil {
-			break
-		}
-	}
+		return err
+	})
 	return res, err
 }
 func jwtHTTPRequest(method string, url string, bodyData string) (string, error) {
@@ -117,27 +136,31 @@ func jwtHTTPRequest(method string, url string, bodyData string) (string, error)
 	}
 	auth, err := CreateAuthNHeader()
 	if err != nil {
-		return "", err
+		return "", AuthError{Err: err}
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", auth)
 	//req.Header.Set("Host", ApiBaseURL)
 	req.Close = true
-	client := http.Client{Timeout: time.Duration(conf.HTTPTimeout) * time.Millisecond}
-	resp, err := client.Do(req)
+	httpClient, err := HTTPClient()
 	if err != nil {
 		return "", err
 	}
+	client := http.Client{Transport: httpClient.Transport, Timeout: time.Duration(conf.HTTPTimeout) * time.Millisecond}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", TransportError{URL: url, Err: err}
+	}
 
 	defer resp.Body.Close()
 	buf := new(bytes.Buffer)
 
 	_, err = buf.ReadFrom(resp.Body)
 	if err != nil {
-		return "", err
+		return "", TransportError{URL: url, StatusCode: resp.StatusCode, Err: err}
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", fmt.Errorf(buf.String())
+		return "", httpStatusError(url, resp.StatusCode, buf.String())
 	}
 	return buf.String(), nil
 }
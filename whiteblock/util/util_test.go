@@ -3,6 +3,7 @@ package util
 import (
 	"bytes"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strconv"
 	"strings"
@@ -45,7 +46,11 @@ func TestCheckAndConvertInt(t *testing.T) {
 
 	for i, tt := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			if CheckAndConvertInt(tt.num, tt.name) != tt.expected {
+			out, err := CheckAndConvertInt(tt.num, tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tt.expected {
 				t.Error("return value of CheckAndConvertInt does not match expected value")
 			}
 		})
@@ -67,13 +72,26 @@ func TestCheckAndConvertInt64(t *testing.T) {
 
 	for i, tt := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			if CheckAndConvertInt64(tt.num, tt.name) != tt.expected {
+			out, err := CheckAndConvertInt64(tt.num, tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tt.expected {
 				t.Error("return value of CheckAndConvertInt does not match expected value")
 			}
 		})
 	}
 }
 
+func TestCheckAndConvertIntInvalid(t *testing.T) {
+	for _, num := range []string{"", "five", "1.5", "99999999999"} {
+		_, err := CheckAndConvertInt(num, "test")
+		if ExitCode(err) != ExitValidation {
+			t.Errorf("expected a validation error for %q, got %v", num, err)
+		}
+	}
+}
+
 func TestWrite(t *testing.T) {
 	path := "/tmp/testWrite"
 	data := []byte("blah")
@@ -115,7 +133,42 @@ func TestCreateAuthNHeader(t *testing.T) {
 }
 
 func TestJwtHTTPRequest(t *testing.T) {
-	// TODO test this
+	if !Exists("jwt") {
+		Set("jwt", "test")
+		defer Delete("jwt")
+	}
+	saved := *conf
+	defer func() { *conf = saved }()
+	conf.HTTPRetries = 3
+	conf.HTTPTimeout = 1000
+
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.Method]++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		method string
+		calls  int
+	}{
+		{method: http.MethodGet, calls: 3},
+		{method: http.MethodPost, calls: 1},
+		{method: http.MethodDelete, calls: 1},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := JwtHTTPRequest(tt.method, server.URL, "{}")
+			if ExitCode(err) != ExitTransport {
+				t.Errorf("expected a transport error, got %v", err)
+			}
+			if calls[tt.method] != tt.calls {
+				t.Errorf("expected %d %s requests, got %d", tt.calls, tt.method, calls[tt.method])
+			}
+		})
+	}
 }
 
 func TestUnrollStringSlicetoMapStringString(t *testing.T) {
@@ -0,0 +1,70 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+)
+
+// YAMLToJSON converts a YAML document into its JSON equivalent. Since YAML is a superset
+// of JSON, JSON documents are passed through unchanged.
+func YAMLToJSON(data []byte) ([]byte, error) {
+	var raw interface{}
+	err := yaml.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+	conv, err := convertYAMLValue(raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(conv)
+}
+
+// UnmarshalYAMLStrict decodes a YAML or JSON document into out using the json tags of out.
+// Unknown fields are treated as errors and numbers in untyped fields are kept as json.Number.
+func UnmarshalYAMLStrict(data []byte, out interface{}) error {
+	jsonData, err := YAMLToJSON(data)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(out)
+}
+
+// yaml.v2 decodes maps as map[interface{}]interface{}, which encoding/json cannot handle
+func convertYAMLValue(in interface{}) (interface{}, error) {
+	switch val := in.(type) {
+	case map[interface{}]interface{}:
+		out := map[string]interface{}{}
+		for k, v := range val {
+			conv, err := convertYAMLValue(v)
+			if err != nil {
+				return nil, err
+			}
+			switch key := k.(type) {
+			case string:
+				out[key] = conv
+			case int, int64, uint64, bool, float64:
+				out[fmt.Sprint(key)] = conv
+			default:
+				return nil, fmt.Errorf("unsupported map key %v", k)
+			}
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, v := range val {
+			conv, err := convertYAMLValue(v)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = conv
+		}
+		return out, nil
+	}
+	return in, nil
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	var tests = []struct {
+		in       string
+		expected string
+	}{
+		{
+			in:       "nodes: 3\nblockchain: geth\n",
+			expected: `{"blockchain":"geth","nodes":3}`,
+		},
+		{
+			in:       "env:\n  0:\n    FOO: bar\n",
+			expected: `{"env":{"0":{"FOO":"bar"}}}`,
+		},
+		{
+			in:       `{"images": ["a", "b"]}`,
+			expected: `{"images":["a","b"]}`,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			out, err := YAMLToJSON([]byte(tt.in))
+			if err != nil {
+				t.Error("error running YAMLToJSON", err)
+			}
+			if string(out) != tt.expected {
+				t.Errorf("return value of YAMLToJSON does not match expected value: %s", string(out))
+			}
+		})
+	}
+}
+
+func TestUnmarshalYAMLStrict(t *testing.T) {
+	type sample struct {
+		Name  string   `json:"name"`
+		Count int      `json:"count"`
+		Tags  []string `json:"tags"`
+	}
+
+	var out sample
+	err := UnmarshalYAMLStrict([]byte("name: test\ncount: 4\ntags: [a, b]\n"), &out)
+	if err != nil {
+		t.Error("error running UnmarshalYAMLStrict", err)
+	}
+	if !reflect.DeepEqual(out, sample{Name: "test", Count: 4, Tags: []string{"a", "b"}}) {
+		t.Error("return value of UnmarshalYAMLStrict does not match expected value")
+	}
+
+	err = UnmarshalYAMLStrict([]byte("name: test\nunknown: 1\n"), &out)
+	if err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}